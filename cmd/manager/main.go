@@ -15,6 +15,8 @@ import (
 
 	"github.com/theodor2311/quay-operator/pkg/apis"
 	"github.com/theodor2311/quay-operator/pkg/controller"
+	"github.com/theodor2311/quay-operator/pkg/k8sutils"
+	"github.com/theodor2311/quay-operator/pkg/webhook"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -34,6 +36,10 @@ var (
 	metricsHost       = "0.0.0.0"
 	metricsPort int32 = 8383
 )
+
+// enableWebhooksEnvVar is the environment variable that enables the admission webhooks
+const enableWebhooksEnvVar = "ENABLE_WEBHOOKS"
+
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -106,23 +112,59 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Setup OpenShift Schemes
-	if err := ossecurityv1.AddToScheme(mgr.GetScheme()); err != nil {
+	k8sclient, err := k8sutils.GetK8sClient(cfg)
+	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
-	if err := routev1.AddToScheme(mgr.GetScheme()); err != nil {
+	isOpenShift, err := k8sutils.IsOpenShift(k8sclient)
+	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
+	// Setup OpenShift Schemes. Routes and SCCs are not available on other Kubernetes distributions
+	if isOpenShift {
+		if err := ossecurityv1.AddToScheme(mgr.GetScheme()); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+
+		if err := routev1.AddToScheme(mgr.GetScheme()); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	} else {
+		log.Info("OpenShift APIs not found. Routes and SCCs will not be managed")
+	}
+
 	// Setup all Controllers
-	if err := controller.AddToManager(mgr); err != nil {
+	if err := controller.AddToManager(mgr, isOpenShift); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
+	// Setup all Webhooks
+	if os.Getenv(enableWebhooksEnvVar) == "true" {
+		if err := webhook.AddToManager(mgr); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	} else {
+		// The API server cannot serve v1beta1 resources without the conversion webhook
+		conversionRequired, err := webhook.ConversionWebhookRequired(cfg)
+		if err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+
+		if conversionRequired {
+			log.Error(fmt.Errorf("%s must be set to true", enableWebhooksEnvVar), "The QuayEcosystem CustomResourceDefinition converts API versions through the operator webhook")
+			os.Exit(1)
+		}
+	}
+
 	// Create Service object to expose the metrics port.
 	_, err = metrics.ExposeMetricsPort(ctx, metricsPort)
 	if err != nil {
@@ -6,14 +6,16 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // QuayEcosystemSpec defines the desired state of QuayEcosystem
 // +k8s:openapi-gen=true
 type QuayEcosystemSpec struct {
-	Quay  Quay  `json:"quay,omitempty"`
-	Redis Redis `json:"redis,omitempty"`
-	Clair Clair `json:"clair,omitempty"`
+	Quay             Quay             `json:"quay,omitempty"`
+	Redis            Redis            `json:"redis,omitempty"`
+	Clair            Clair            `json:"clair,omitempty"`
+	HighAvailability HighAvailability `json:"highAvailability,omitempty"`
 }
 
 // QuayEcosystemPhase defines the phase of lifecycle the operator is running in
@@ -43,6 +45,110 @@ const (
 	QuayEcosystemQuaySetupSuccess QuayEcosystemConditionType = "QuaySetupSuccess"
 	// QuayEcosystemQuaySetupFailure indicates that the Quay setup process failed
 	QuayEcosystemQuaySetupFailure QuayEcosystemConditionType = "QuaySetupFailure"
+
+	// QuayEcosystemReady indicates whether all of the components of the QuayEcosystem are ready
+	QuayEcosystemReady QuayEcosystemConditionType = "Ready"
+)
+
+// PersistentVolumeClaimRetentionPolicy defines what happens to a PersistentVolumeClaim when the QuayEcosystem is deleted
+type PersistentVolumeClaimRetentionPolicy string
+
+const (
+	// RetainPersistentVolumeClaimRetentionPolicy keeps the PersistentVolumeClaim after the QuayEcosystem is deleted
+	RetainPersistentVolumeClaimRetentionPolicy PersistentVolumeClaimRetentionPolicy = "Retain"
+
+	// DeletePersistentVolumeClaimRetentionPolicy deletes the PersistentVolumeClaim along with the QuayEcosystem
+	DeletePersistentVolumeClaimRetentionPolicy PersistentVolumeClaimRetentionPolicy = "Delete"
+
+	// SnapshotPersistentVolumeClaimRetentionPolicy takes a VolumeSnapshot of the PersistentVolumeClaim before it is deleted
+	SnapshotPersistentVolumeClaimRetentionPolicy PersistentVolumeClaimRetentionPolicy = "Snapshot"
+)
+
+// HighAvailabilityMode defines how the replicas of a component are spread across the cluster
+type HighAvailabilityMode string
+
+const (
+	// RequiredAntiAffinityHighAvailabilityMode prevents two replicas of a component from being scheduled in the same topology domain
+	RequiredAntiAffinityHighAvailabilityMode HighAvailabilityMode = "RequiredAntiAffinity"
+
+	// PreferredAntiAffinityHighAvailabilityMode spreads the replicas of a component across topology domains when possible
+	PreferredAntiAffinityHighAvailabilityMode HighAvailabilityMode = "PreferredAntiAffinity"
+)
+
+// ClairVersion defines the major version of Clair that is deployed
+type ClairVersion string
+
+const (
+	// V2ClairVersion deploys Clair v2 fronted by a JWT proxy
+	V2ClairVersion ClairVersion = "v2"
+
+	// V4ClairVersion deploys Clair v4 authenticated with a pre-shared key
+	V4ClairVersion ClairVersion = "v4"
+)
+
+// ClairDeploymentMode defines how the Clair v4 services are deployed
+type ClairDeploymentMode string
+
+const (
+	// CombinedClairDeploymentMode runs the indexer, matcher and notifier in a single deployment
+	CombinedClairDeploymentMode ClairDeploymentMode = "Combined"
+
+	// SplitClairDeploymentMode runs the indexer, matcher and notifier in separate deployments
+	SplitClairDeploymentMode ClairDeploymentMode = "Split"
+)
+
+// CertificateIssuerKind defines the kind of cert-manager issuer from which certificates are requested
+type CertificateIssuerKind string
+
+const (
+	// IssuerCertificateIssuerKind requests certificates from an Issuer in the namespace of the QuayEcosystem
+	IssuerCertificateIssuerKind CertificateIssuerKind = "Issuer"
+
+	// ClusterIssuerCertificateIssuerKind requests certificates from a cluster scoped ClusterIssuer
+	ClusterIssuerCertificateIssuerKind CertificateIssuerKind = "ClusterIssuer"
+)
+
+// ExternalAccessType defines the kind of resource through which Quay is exposed outside of the cluster
+type ExternalAccessType string
+
+const (
+	// RouteExternalAccessType exposes Quay through OpenShift Routes
+	RouteExternalAccessType ExternalAccessType = "Route"
+
+	// IngressExternalAccessType exposes Quay through Kubernetes Ingresses
+	IngressExternalAccessType ExternalAccessType = "Ingress"
+)
+
+// QuayEcosystemComponent identifies a component of the QuayEcosystem
+type QuayEcosystemComponent string
+
+const (
+	// QuayEcosystemComponentQuay represents the Quay application
+	QuayEcosystemComponentQuay QuayEcosystemComponent = "quay"
+
+	// QuayEcosystemComponentQuayConfig represents the Quay config application
+	QuayEcosystemComponentQuayConfig QuayEcosystemComponent = "quayConfig"
+
+	// QuayEcosystemComponentClair represents Clair
+	QuayEcosystemComponentClair QuayEcosystemComponent = "clair"
+
+	// QuayEcosystemComponentClairIndexer represents the Clair v4 indexer in the split deployment mode
+	QuayEcosystemComponentClairIndexer QuayEcosystemComponent = "clairIndexer"
+
+	// QuayEcosystemComponentClairMatcher represents the Clair v4 matcher in the split deployment mode
+	QuayEcosystemComponentClairMatcher QuayEcosystemComponent = "clairMatcher"
+
+	// QuayEcosystemComponentClairNotifier represents the Clair v4 notifier in the split deployment mode
+	QuayEcosystemComponentClairNotifier QuayEcosystemComponent = "clairNotifier"
+
+	// QuayEcosystemComponentRedis represents Redis
+	QuayEcosystemComponentRedis QuayEcosystemComponent = "redis"
+
+	// QuayEcosystemComponentDatabase represents the Quay database
+	QuayEcosystemComponentDatabase QuayEcosystemComponent = "database"
+
+	// QuayEcosystemComponentClairDatabase represents the dedicated Clair database
+	QuayEcosystemComponentClairDatabase QuayEcosystemComponent = "clairDatabase"
 )
 
 // QuayEcosystemStatus defines the observed state of QuayEcosystem
@@ -56,6 +162,34 @@ type QuayEcosystemStatus struct {
 	// +patchStrategy=merge
 	Conditions    []QuayEcosystemCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
 	SetupComplete bool                     `json:"setupComplete,omitempty"`
+	// +optional
+	Components map[QuayEcosystemComponent]ComponentStatus `json:"components,omitempty"`
+	// +optional
+	Certificate *CertificateStatus `json:"certificate,omitempty"`
+	// +optional
+	SecurityScannerKey *SecurityScannerKeyStatus `json:"securityScannerKey,omitempty"`
+}
+
+// ComponentStatus defines the observed state of a component deployed by the operator
+type ComponentStatus struct {
+	DesiredReplicas int32  `json:"desiredReplicas"`
+	ReadyReplicas   int32  `json:"readyReplicas"`
+	Image           string `json:"image,omitempty"`
+	LastError       string `json:"lastError,omitempty"`
+}
+
+// CertificateStatus defines the observed state of the certificate served by Quay
+type CertificateStatus struct {
+	NotAfter   metav1.Time `json:"notAfter,omitempty"`
+	SelfSigned bool        `json:"selfSigned,omitempty"`
+}
+
+// SecurityScannerKeyStatus defines the observed state of the service key Clair uses to authenticate against Quay
+type SecurityScannerKeyStatus struct {
+	CreatedAt   metav1.Time  `json:"createdAt,omitempty"`
+	ExpiresAt   *metav1.Time `json:"expiresAt,omitempty"`
+	ID          string       `json:"id,omitempty"`
+	PreviousIDs []string     `json:"previousIDs,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -82,21 +216,31 @@ type QuayEcosystemList struct {
 
 // Quay defines the properies of a deployment of Quay
 type Quay struct {
-	ConfigRouteHost                string            `json:"configRouteHost,omitempty"`
-	ConfigSecretName               string            `json:"configSecretName,omitempty"`
-	Database                       Database          `json:"database,omitempty"`
-	EnableNodePortService          bool              `json:"enableNodePortService,omitempty"`
-	Image                          string            `json:"image,omitempty"`
-	ImagePullSecretName            string            `json:"imagePullSecretName,omitempty"`
-	IsOpenShift                    bool              `json:"isOpenShift,omitempty"`
-	KeepConfigDeployment           bool              `json:"keepConfigDeployment,omitempty"`
-	RegistryBackends               []RegistryBackend `json:"registryBackends,omitempty"`
-	RegistryStorage                RegistryStorage   `json:"registryStorage,omitempty"`
-	Replicas                       *int32            `json:"replicas,omitempty"`
-	RouteHost                      string            `json:"routeHost,omitempty"`
-	SkipSetup                      bool              `json:"skipSetup,omitempty"`
-	SslCertificatesSecretName      string            `json:"sslCertificatesSecretName,omitempty"`
-	SuperuserCredentialsSecretName string            `json:"superuserCredentialsSecretName,omitempty"`
+	Autoscaling            *Autoscaling         `json:"autoscaling,omitempty"`
+	CertificateIssuer      *CertificateIssuer   `json:"certificateIssuer,omitempty"`
+	ConfigPodSettings      ComponentPodSettings `json:"configPodSettings,omitempty"`
+	ConfigRouteHost        string               `json:"configRouteHost,omitempty"`
+	ConfigSecretName       string               `json:"configSecretName,omitempty"`
+	ConfigServiceExposure  *ServiceExposure     `json:"configServiceExposure,omitempty"`
+	Database               Database             `json:"database,omitempty"`
+	EnableNodePortService  bool                 `json:"enableNodePortService,omitempty"`
+	ExternalAccess         *ExternalAccess      `json:"externalAccess,omitempty"`
+	ExtraCACertsSecretName string               `json:"extraCACertsSecretName,omitempty"`
+	Image                  string               `json:"image,omitempty"`
+	ImagePullSecretName    string               `json:"imagePullSecretName,omitempty"`
+	InjectTrustedCABundle  bool                 `json:"injectTrustedCABundle,omitempty"`
+	// Deprecated: The operator detects whether the cluster serves the OpenShift APIs. Use ExternalAccess.Type to select how Quay is exposed
+	IsOpenShift                    bool                 `json:"isOpenShift,omitempty"`
+	KeepConfigDeployment           bool                 `json:"keepConfigDeployment,omitempty"`
+	PodSettings                    ComponentPodSettings `json:"podSettings,omitempty"`
+	RegistryBackends               []RegistryBackend    `json:"registryBackends,omitempty"`
+	RegistryStorage                RegistryStorage      `json:"registryStorage,omitempty"`
+	Replicas                       *int32               `json:"replicas,omitempty"`
+	RouteHost                      string               `json:"routeHost,omitempty"`
+	ServiceExposure                *ServiceExposure     `json:"serviceExposure,omitempty"`
+	SkipSetup                      bool                 `json:"skipSetup,omitempty"`
+	SslCertificatesSecretName      string               `json:"sslCertificatesSecretName,omitempty"`
+	SuperuserCredentialsSecretName string               `json:"superuserCredentialsSecretName,omitempty"`
 }
 
 // QuayEcosystemCondition defines a list of conditions that the object will transiton through
@@ -111,50 +255,125 @@ type QuayEcosystemCondition struct {
 
 // Redis defines the properies of a deployment of Redis
 type Redis struct {
-	Hostname            string `json:"hostname,omitempty"`
-	Image               string `json:"image,omitempty"`
-	ImagePullSecretName string `json:"imagePullSecretName,omitempty"`
-	Port                *int32 `json:"port,omitempty"`
-	Replicas            *int32 `json:"replicas,omitempty"`
+	Hostname            string               `json:"hostname,omitempty"`
+	Image               string               `json:"image,omitempty"`
+	ImagePullSecretName string               `json:"imagePullSecretName,omitempty"`
+	PodSettings         ComponentPodSettings `json:"podSettings,omitempty"`
+	Port                *int32               `json:"port,omitempty"`
+	Replicas            *int32               `json:"replicas,omitempty"`
 }
 
 // Database defines a database that will be deployed to support a particular component
 type Database struct {
-	CPU                   string `json:"cpu,omitempty"`
-	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
-	Image                 string `json:"image,omitempty"`
-	ImagePullSecretName   string `json:"imagePullSecretName,omitempty"`
-	Memory                string `json:"memory,omitempty"`
-	Replicas              *int32 `json:"replicas,omitempty"`
-	Server                string `json:"server,omitempty"`
-	VolumeSize            string `json:"volumeSize,omitempty"`
+	CPU                   string                               `json:"cpu,omitempty"`
+	CredentialsSecretName string                               `json:"credentialsSecretName,omitempty"`
+	Image                 string                               `json:"image,omitempty"`
+	ImagePullSecretName   string                               `json:"imagePullSecretName,omitempty"`
+	Memory                string                               `json:"memory,omitempty"`
+	PodSettings           ComponentPodSettings                 `json:"podSettings,omitempty"`
+	Replicas              *int32                               `json:"replicas,omitempty"`
+	RetentionPolicy       PersistentVolumeClaimRetentionPolicy `json:"retentionPolicy,omitempty"`
+	Server                string                               `json:"server,omitempty"`
+	VolumeSize            string                               `json:"volumeSize,omitempty"`
+}
+
+// ComponentPodSettings defines the resources and scheduling constraints applied to the pods of a component
+type ComponentPodSettings struct {
+	Affinity          *corev1.Affinity            `json:"affinity,omitempty"`
+	NodeSelector      map[string]string           `json:"nodeSelector,omitempty"`
+	PriorityClassName string                      `json:"priorityClassName,omitempty"`
+	Resources         corev1.ResourceRequirements `json:"resources,omitempty"`
+	Tolerations       []corev1.Toleration         `json:"tolerations,omitempty"`
+}
+
+// CertificateIssuer defines the cert-manager issuer from which the certificate served by Quay is requested
+type CertificateIssuer struct {
+	IncludeClairHostname  bool                  `json:"includeClairHostname,omitempty"`
+	IncludeConfigHostname bool                  `json:"includeConfigHostname,omitempty"`
+	Kind                  CertificateIssuerKind `json:"kind,omitempty"`
+	Name                  string                `json:"name"`
+}
+
+// ExternalAccess defines how Quay is exposed outside of the cluster. Routes are used when the cluster serves the
+// OpenShift Route API and Ingresses otherwise, unless a Type is specified
+type ExternalAccess struct {
+	Annotations  map[string]string  `json:"annotations,omitempty"`
+	IngressClass string             `json:"ingressClass,omitempty"`
+	Type         ExternalAccessType `json:"type,omitempty"`
+}
+
+// ServiceExposure defines how the Service of a component is exposed. The Type takes precedence over EnableNodePortService
+type ServiceExposure struct {
+	Annotations              map[string]string                       `json:"annotations,omitempty"`
+	ExternalTrafficPolicy    corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+	LoadBalancerSourceRanges []string                                `json:"loadBalancerSourceRanges,omitempty"`
+	NodePort                 int32                                   `json:"nodePort,omitempty"`
+	Type                     corev1.ServiceType                      `json:"type,omitempty"`
+}
+
+// Autoscaling defines the HorizontalPodAutoscaler that manages the replicas of a component
+type Autoscaling struct {
+	MaxReplicas                       int32  `json:"maxReplicas"`
+	MinReplicas                       *int32 `json:"minReplicas,omitempty"`
+	TargetCPUUtilizationPercentage    *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// HighAvailability defines how the Quay and Clair replicas are protected against node failures and voluntary disruptions
+type HighAvailability struct {
+	Enabled      bool                 `json:"enabled,omitempty"`
+	MinAvailable *intstr.IntOrString  `json:"minAvailable,omitempty"`
+	Mode         HighAvailabilityMode `json:"mode,omitempty"`
+	TopologyKey  string               `json:"topologyKey,omitempty"`
 }
 
 // Clair defines the properties of a deployment of Clair
 type Clair struct {
-	Image                     string   `json:"image,omitempty"`
-	ImagePullSecretName       string   `json:"imagePullSecretName,omitempty"`
-	Database                  Database `json:"database,omitempty"`
-	Replicas                  *int32   `json:"replicas,omitempty"`
-	SslCertificatesSecretName string   `json:"sslCertificatesSecretName,omitempty"`
+	APITimeout                   string               `json:"apiTimeout,omitempty"`
+	Autoscaling                  *Autoscaling         `json:"autoscaling,omitempty"`
+	Enabled                      *bool                `json:"enabled,omitempty"`
+	EnabledUpdaters              []string             `json:"enabledUpdaters,omitempty"`
+	ExtraCACertsSecretName       string               `json:"extraCACertsSecretName,omitempty"`
+	Image                        string               `json:"image,omitempty"`
+	ImagePullSecretName          string               `json:"imagePullSecretName,omitempty"`
+	InjectTrustedCABundle        bool                 `json:"injectTrustedCABundle,omitempty"`
+	Database                     Database             `json:"database,omitempty"`
+	DatabaseCacheSize            *int32               `json:"databaseCacheSize,omitempty"`
+	DeploymentMode               ClairDeploymentMode  `json:"deploymentMode,omitempty"`
+	NotifierAttempts             *int32               `json:"notifierAttempts,omitempty"`
+	NotifierRenotifyInterval     string               `json:"notifierRenotifyInterval,omitempty"`
+	PodSettings                  ComponentPodSettings `json:"podSettings,omitempty"`
+	Replicas                     *int32               `json:"replicas,omitempty"`
+	SecurityScannerKeyExpiration string               `json:"securityScannerKeyExpiration,omitempty"`
+	SslCertificatesSecretName    string               `json:"sslCertificatesSecretName,omitempty"`
+	UpdateInterval               string               `json:"updateInterval,omitempty"`
+	Version                      ClairVersion         `json:"version,omitempty"`
 }
 
 // RegistryBackend defines a particular backend supporting the Quay registry
 type RegistryBackend struct {
 	Name                  string `json:"name"`
+	Preferred             bool   `json:"preferred,omitempty"`
+	ReplicateByDefault    bool   `json:"replicateByDefault,omitempty"`
 	RegistryBackendSource `json:",inline" protobuf:"bytes,2,opt,name=registryBackendSource"`
 }
 
 // RegistryBackendSource defines the specific configurations to support the Quay registry
 type RegistryBackendSource struct {
-	Local *LocalRegistryBackendSource `json:"local,omitempty,name=local"`
+	Local             *LocalRegistryBackendSource             `json:"local,omitempty,name=local"`
+	S3                *S3RegistryBackendSource                `json:"s3,omitempty,name=s3"`
+	Azure             *AzureRegistryBackendSource             `json:"azure,omitempty,name=azure"`
+	GoogleCloud       *GoogleCloudRegistryBackendSource       `json:"googleCloud,omitempty,name=googleCloud"`
+	Swift             *SwiftRegistryBackendSource             `json:"swift,omitempty,name=swift"`
+	ObjectBucketClaim *ObjectBucketClaimRegistryBackendSource `json:"objectBucketClaim,omitempty,name=objectBucketClaim"`
 }
 
 // RegistryStorage defines the configurations to support persistent storage
 type RegistryStorage struct {
-	PersistentVolumeAccessModes      []corev1.PersistentVolumeAccessMode `json:"persistentVolumeAccessMode,omitempty,name=persistentVolumeAccessMode"`
-	PersistentVolumeSize             string                              `json:"persistentVolumeSize,omitempty,name=volumeSize"`
-	PersistentVolumeStorageClassName string                              `json:"persistentVolumeStorageClassName,omitempty,name=storageClassName"`
+	PersistentVolumeAccessModes      []corev1.PersistentVolumeAccessMode  `json:"persistentVolumeAccessMode,omitempty,name=persistentVolumeAccessMode"`
+	PersistentVolumeSize             string                               `json:"persistentVolumeSize,omitempty,name=volumeSize"`
+	PersistentVolumeStorageClassName string                               `json:"persistentVolumeStorageClassName,omitempty,name=storageClassName"`
+	PersistentVolumeRetentionPolicy  PersistentVolumeClaimRetentionPolicy `json:"persistentVolumeRetentionPolicy,omitempty,name=retentionPolicy"`
 }
 
 // LocalRegistryBackendSource defines local registry storage
@@ -162,6 +381,49 @@ type LocalRegistryBackendSource struct {
 	StoragePath string `json:"storage_path,omitempty,name=storage_path"`
 }
 
+// S3RegistryBackendSource defines S3 or S3 compatible (RADOS Gateway, MinIO) registry storage
+type S3RegistryBackendSource struct {
+	Bucket                string `json:"bucket,omitempty,name=bucket"`
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	Endpoint              string `json:"endpoint,omitempty,name=endpoint"`
+	Region                string `json:"region,omitempty,name=region"`
+	StoragePath           string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// AzureRegistryBackendSource defines Azure Blob Storage registry storage
+type AzureRegistryBackendSource struct {
+	AccountName           string `json:"accountName,omitempty,name=accountName"`
+	Container             string `json:"container,omitempty,name=container"`
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	StoragePath           string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// GoogleCloudRegistryBackendSource defines Google Cloud Storage registry storage
+type GoogleCloudRegistryBackendSource struct {
+	Bucket                string `json:"bucket,omitempty,name=bucket"`
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	StoragePath           string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// SwiftRegistryBackendSource defines OpenStack Swift registry storage
+type SwiftRegistryBackendSource struct {
+	AuthURL               string            `json:"authURL,omitempty,name=authURL"`
+	AuthVersion           int               `json:"authVersion,omitempty,name=authVersion"`
+	CACertSecretName      string            `json:"caCertSecretName,omitempty,name=caCertSecretName"`
+	Container             string            `json:"container,omitempty,name=container"`
+	CredentialsSecretName string            `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	OSOptions             map[string]string `json:"osOptions,omitempty,name=osOptions"`
+	StoragePath           string            `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// ObjectBucketClaimRegistryBackendSource defines registry storage provisioned through an ObjectBucketClaim (Ceph RADOS Gateway)
+type ObjectBucketClaimRegistryBackendSource struct {
+	Name             string `json:"name,omitempty,name=name"`
+	Secure           bool   `json:"secure,omitempty,name=secure"`
+	StorageClassName string `json:"storageClassName,omitempty,name=storageClassName"`
+	StoragePath      string `json:"storagePath,omitempty,name=storagePath"`
+}
+
 func init() {
 	SchemeBuilder.Register(&QuayEcosystem{}, &QuayEcosystemList{})
 }
@@ -186,7 +448,7 @@ func (q *QuayEcosystem) SetCondition(newCondition QuayEcosystemCondition) *QuayE
 		return &newCondition
 	}
 
-	if newCondition.Status != newCondition.Status {
+	if existingCondition.Status != newCondition.Status {
 		existingCondition.Status = newCondition.Status
 		existingCondition.LastTransitionTime = now
 	}
@@ -7,12 +7,119 @@ package v1alpha1
 import (
 	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Autoscaling) DeepCopyInto(out *Autoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Autoscaling.
+func (in *Autoscaling) DeepCopy() *Autoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(Autoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureRegistryBackendSource) DeepCopyInto(out *AzureRegistryBackendSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureRegistryBackendSource.
+func (in *AzureRegistryBackendSource) DeepCopy() *AzureRegistryBackendSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureRegistryBackendSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateIssuer) DeepCopyInto(out *CertificateIssuer) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateIssuer.
+func (in *CertificateIssuer) DeepCopy() *CertificateIssuer {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateIssuer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateStatus.
+func (in *CertificateStatus) DeepCopy() *CertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Clair) DeepCopyInto(out *Clair) {
 	*out = *in
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(Autoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnabledUpdaters != nil {
+		in, out := &in.EnabledUpdaters, &out.EnabledUpdaters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Database.DeepCopyInto(&out.Database)
+	if in.DatabaseCacheSize != nil {
+		in, out := &in.DatabaseCacheSize, &out.DatabaseCacheSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NotifierAttempts != nil {
+		in, out := &in.NotifierAttempts, &out.NotifierAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	in.PodSettings.DeepCopyInto(&out.PodSettings)
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
@@ -31,9 +138,62 @@ func (in *Clair) DeepCopy() *Clair {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentPodSettings) DeepCopyInto(out *ComponentPodSettings) {
+	*out = *in
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentPodSettings.
+func (in *ComponentPodSettings) DeepCopy() *ComponentPodSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentPodSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Database) DeepCopyInto(out *Database) {
 	*out = *in
+	in.PodSettings.DeepCopyInto(&out.PodSettings)
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
@@ -52,6 +212,66 @@ func (in *Database) DeepCopy() *Database {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalAccess) DeepCopyInto(out *ExternalAccess) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAccess.
+func (in *ExternalAccess) DeepCopy() *ExternalAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleCloudRegistryBackendSource) DeepCopyInto(out *GoogleCloudRegistryBackendSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleCloudRegistryBackendSource.
+func (in *GoogleCloudRegistryBackendSource) DeepCopy() *GoogleCloudRegistryBackendSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleCloudRegistryBackendSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HighAvailability) DeepCopyInto(out *HighAvailability) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HighAvailability.
+func (in *HighAvailability) DeepCopy() *HighAvailability {
+	if in == nil {
+		return nil
+	}
+	out := new(HighAvailability)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalRegistryBackendSource) DeepCopyInto(out *LocalRegistryBackendSource) {
 	*out = *in
@@ -68,10 +288,48 @@ func (in *LocalRegistryBackendSource) DeepCopy() *LocalRegistryBackendSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectBucketClaimRegistryBackendSource) DeepCopyInto(out *ObjectBucketClaimRegistryBackendSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectBucketClaimRegistryBackendSource.
+func (in *ObjectBucketClaimRegistryBackendSource) DeepCopy() *ObjectBucketClaimRegistryBackendSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectBucketClaimRegistryBackendSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Quay) DeepCopyInto(out *Quay) {
 	*out = *in
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(Autoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateIssuer != nil {
+		in, out := &in.CertificateIssuer, &out.CertificateIssuer
+		*out = new(CertificateIssuer)
+		**out = **in
+	}
+	in.ConfigPodSettings.DeepCopyInto(&out.ConfigPodSettings)
+	if in.ConfigServiceExposure != nil {
+		in, out := &in.ConfigServiceExposure, &out.ConfigServiceExposure
+		*out = new(ServiceExposure)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Database.DeepCopyInto(&out.Database)
+	if in.ExternalAccess != nil {
+		in, out := &in.ExternalAccess, &out.ExternalAccess
+		*out = new(ExternalAccess)
+		(*in).DeepCopyInto(*out)
+	}
+	in.PodSettings.DeepCopyInto(&out.PodSettings)
 	if in.RegistryBackends != nil {
 		in, out := &in.RegistryBackends, &out.RegistryBackends
 		*out = make([]RegistryBackend, len(*in))
@@ -85,6 +343,11 @@ func (in *Quay) DeepCopyInto(out *Quay) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ServiceExposure != nil {
+		in, out := &in.ServiceExposure, &out.ServiceExposure
+		*out = new(ServiceExposure)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -182,6 +445,8 @@ func (in *QuayEcosystemSpec) DeepCopyInto(out *QuayEcosystemSpec) {
 	*out = *in
 	in.Quay.DeepCopyInto(&out.Quay)
 	in.Redis.DeepCopyInto(&out.Redis)
+	in.Clair.DeepCopyInto(&out.Clair)
+	in.HighAvailability.DeepCopyInto(&out.HighAvailability)
 	return
 }
 
@@ -205,6 +470,23 @@ func (in *QuayEcosystemStatus) DeepCopyInto(out *QuayEcosystemStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[QuayEcosystemComponent]ComponentStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(CertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityScannerKey != nil {
+		in, out := &in.SecurityScannerKey, &out.SecurityScannerKey
+		*out = new(SecurityScannerKeyStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -221,6 +503,7 @@ func (in *QuayEcosystemStatus) DeepCopy() *QuayEcosystemStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Redis) DeepCopyInto(out *Redis) {
 	*out = *in
+	in.PodSettings.DeepCopyInto(&out.PodSettings)
 	if in.Port != nil {
 		in, out := &in.Port, &out.Port
 		*out = new(int32)
@@ -269,6 +552,31 @@ func (in *RegistryBackendSource) DeepCopyInto(out *RegistryBackendSource) {
 		*out = new(LocalRegistryBackendSource)
 		**out = **in
 	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3RegistryBackendSource)
+		**out = **in
+	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(AzureRegistryBackendSource)
+		**out = **in
+	}
+	if in.GoogleCloud != nil {
+		in, out := &in.GoogleCloud, &out.GoogleCloud
+		*out = new(GoogleCloudRegistryBackendSource)
+		**out = **in
+	}
+	if in.Swift != nil {
+		in, out := &in.Swift, &out.Swift
+		*out = new(SwiftRegistryBackendSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectBucketClaim != nil {
+		in, out := &in.ObjectBucketClaim, &out.ObjectBucketClaim
+		*out = new(ObjectBucketClaimRegistryBackendSource)
+		**out = **in
+	}
 	return
 }
 
@@ -302,3 +610,96 @@ func (in *RegistryStorage) DeepCopy() *RegistryStorage {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3RegistryBackendSource) DeepCopyInto(out *S3RegistryBackendSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3RegistryBackendSource.
+func (in *S3RegistryBackendSource) DeepCopy() *S3RegistryBackendSource {
+	if in == nil {
+		return nil
+	}
+	out := new(S3RegistryBackendSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityScannerKeyStatus) DeepCopyInto(out *SecurityScannerKeyStatus) {
+	*out = *in
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousIDs != nil {
+		in, out := &in.PreviousIDs, &out.PreviousIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityScannerKeyStatus.
+func (in *SecurityScannerKeyStatus) DeepCopy() *SecurityScannerKeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityScannerKeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceExposure) DeepCopyInto(out *ServiceExposure) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExposure.
+func (in *ServiceExposure) DeepCopy() *ServiceExposure {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceExposure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwiftRegistryBackendSource) DeepCopyInto(out *SwiftRegistryBackendSource) {
+	*out = *in
+	if in.OSOptions != nil {
+		in, out := &in.OSOptions, &out.OSOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwiftRegistryBackendSource.
+func (in *SwiftRegistryBackendSource) DeepCopy() *SwiftRegistryBackendSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SwiftRegistryBackendSource)
+	in.DeepCopyInto(out)
+	return out
+}
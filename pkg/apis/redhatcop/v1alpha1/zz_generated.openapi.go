@@ -78,11 +78,21 @@ func schema_pkg_apis_redhatcop_v1alpha1_QuayEcosystemSpec(ref common.ReferenceCa
 							Ref: ref("github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.Redis"),
 						},
 					},
+					"clair": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.Clair"),
+						},
+					},
+					"highAvailability": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.HighAvailability"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.Quay", "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.Redis"},
+			"github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.Clair", "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.HighAvailability", "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.Quay", "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.Redis"},
 	}
 }
 
@@ -134,10 +144,32 @@ func schema_pkg_apis_redhatcop_v1alpha1_QuayEcosystemStatus(ref common.Reference
 							Format: "",
 						},
 					},
+					"components": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.ComponentStatus"),
+									},
+								},
+							},
+						},
+					},
+					"certificate": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.CertificateStatus"),
+						},
+					},
+					"securityScannerKey": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.SecurityScannerKeyStatus"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.QuayEcosystemCondition"},
+			"github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.CertificateStatus", "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.ComponentStatus", "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.QuayEcosystemCondition", "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1.SecurityScannerKeyStatus"},
 	}
 }
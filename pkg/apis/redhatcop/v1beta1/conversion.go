@@ -0,0 +1,378 @@
+package v1beta1
+
+import (
+	"github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+)
+
+// ConvertTo converts this QuayEcosystem to the v1alpha1 storage version
+func (src *QuayEcosystem) ConvertTo(dst *v1alpha1.QuayEcosystem) error {
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.TypeMeta.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	dst.TypeMeta.Kind = src.TypeMeta.Kind
+
+	// Quay
+	dst.Spec.Quay = v1alpha1.Quay{
+		Autoscaling:                    (*v1alpha1.Autoscaling)(src.Spec.Quay.Autoscaling),
+		CertificateIssuer:              convertCertificateIssuerTo(src.Spec.Quay.Security.CertificateIssuer),
+		ConfigRouteHost:                src.Spec.Quay.Networking.ConfigRouteHost,
+		ConfigSecretName:               src.Spec.Quay.Security.ConfigSecretName,
+		ConfigServiceExposure:          (*v1alpha1.ServiceExposure)(src.Spec.Quay.Networking.ConfigServiceExposure),
+		Database:                       convertDatabaseTo(src.Spec.Quay.Database),
+		EnableNodePortService:          src.Spec.Quay.Networking.EnableNodePortService,
+		ExternalAccess:                 convertExternalAccessTo(src.Spec.Quay.Networking.ExternalAccess),
+		ExtraCACertsSecretName:         src.Spec.Quay.Security.ExtraCACertsSecretName,
+		InjectTrustedCABundle:          src.Spec.Quay.Security.InjectTrustedCABundle,
+		Image:                          src.Spec.Quay.Image,
+		ImagePullSecretName:            src.Spec.Quay.ImagePullSecretName,
+		ConfigPodSettings:              v1alpha1.ComponentPodSettings(src.Spec.Quay.ConfigPodSettings),
+		PodSettings:                    v1alpha1.ComponentPodSettings(src.Spec.Quay.PodSettings),
+		IsOpenShift:                    src.Spec.Quay.Networking.IsOpenShift,
+		KeepConfigDeployment:           src.Spec.Quay.KeepConfigDeployment,
+		Replicas:                       src.Spec.Quay.Replicas,
+		RouteHost:                      src.Spec.Quay.Networking.RouteHost,
+		ServiceExposure:                (*v1alpha1.ServiceExposure)(src.Spec.Quay.Networking.ServiceExposure),
+		SkipSetup:                      src.Spec.Quay.SkipSetup,
+		SslCertificatesSecretName:      src.Spec.Quay.Security.SslCertificatesSecretName,
+		SuperuserCredentialsSecretName: src.Spec.Quay.Security.SuperuserCredentialsSecretName,
+	}
+
+	if src.Spec.Quay.Storage.Backends != nil {
+		dst.Spec.Quay.RegistryBackends = make([]v1alpha1.RegistryBackend, len(src.Spec.Quay.Storage.Backends))
+		for i, registryBackend := range src.Spec.Quay.Storage.Backends {
+			dst.Spec.Quay.RegistryBackends[i] = v1alpha1.RegistryBackend{
+				Name:               registryBackend.Name,
+				Preferred:          registryBackend.Preferred,
+				ReplicateByDefault: registryBackend.ReplicateByDefault,
+				RegistryBackendSource: v1alpha1.RegistryBackendSource{
+					Local:             (*v1alpha1.LocalRegistryBackendSource)(registryBackend.Local),
+					S3:                (*v1alpha1.S3RegistryBackendSource)(registryBackend.S3),
+					Azure:             (*v1alpha1.AzureRegistryBackendSource)(registryBackend.Azure),
+					GoogleCloud:       (*v1alpha1.GoogleCloudRegistryBackendSource)(registryBackend.GoogleCloud),
+					Swift:             (*v1alpha1.SwiftRegistryBackendSource)(registryBackend.Swift),
+					ObjectBucketClaim: (*v1alpha1.ObjectBucketClaimRegistryBackendSource)(registryBackend.ObjectBucketClaim),
+				},
+			}
+		}
+	}
+
+	dst.Spec.Quay.RegistryStorage = v1alpha1.RegistryStorage{
+		PersistentVolumeAccessModes:      src.Spec.Quay.Storage.PersistentVolume.AccessModes,
+		PersistentVolumeSize:             src.Spec.Quay.Storage.PersistentVolume.Size,
+		PersistentVolumeStorageClassName: src.Spec.Quay.Storage.PersistentVolume.StorageClassName,
+		PersistentVolumeRetentionPolicy:  v1alpha1.PersistentVolumeClaimRetentionPolicy(src.Spec.Quay.Storage.PersistentVolume.RetentionPolicy),
+	}
+
+	// Redis
+	dst.Spec.Redis = v1alpha1.Redis{
+		Hostname:            src.Spec.Redis.Hostname,
+		Image:               src.Spec.Redis.Image,
+		ImagePullSecretName: src.Spec.Redis.ImagePullSecretName,
+		PodSettings:         v1alpha1.ComponentPodSettings(src.Spec.Redis.PodSettings),
+		Port:                src.Spec.Redis.Port,
+		Replicas:            src.Spec.Redis.Replicas,
+	}
+
+	// Clair
+	dst.Spec.Clair = v1alpha1.Clair{
+		APITimeout:                   src.Spec.Clair.API.Timeout,
+		Autoscaling:                  (*v1alpha1.Autoscaling)(src.Spec.Clair.Autoscaling),
+		Database:                     convertDatabaseTo(src.Spec.Clair.Database),
+		DatabaseCacheSize:            src.Spec.Clair.DatabaseCacheSize,
+		DeploymentMode:               v1alpha1.ClairDeploymentMode(src.Spec.Clair.DeploymentMode),
+		Enabled:                      src.Spec.Clair.Enabled,
+		EnabledUpdaters:              src.Spec.Clair.Updater.EnabledUpdaters,
+		ExtraCACertsSecretName:       src.Spec.Clair.Security.ExtraCACertsSecretName,
+		InjectTrustedCABundle:        src.Spec.Clair.Security.InjectTrustedCABundle,
+		Image:                        src.Spec.Clair.Image,
+		ImagePullSecretName:          src.Spec.Clair.ImagePullSecretName,
+		NotifierAttempts:             src.Spec.Clair.Notifier.Attempts,
+		NotifierRenotifyInterval:     src.Spec.Clair.Notifier.RenotifyInterval,
+		PodSettings:                  v1alpha1.ComponentPodSettings(src.Spec.Clair.PodSettings),
+		Replicas:                     src.Spec.Clair.Replicas,
+		SecurityScannerKeyExpiration: src.Spec.Clair.Security.ScannerKeyExpiration,
+		SslCertificatesSecretName:    src.Spec.Clair.Security.SslCertificatesSecretName,
+		UpdateInterval:               src.Spec.Clair.Updater.Interval,
+		Version:                      v1alpha1.ClairVersion(src.Spec.Clair.Version),
+	}
+
+	// High Availability
+	dst.Spec.HighAvailability = v1alpha1.HighAvailability{
+		Enabled:      src.Spec.HighAvailability.Enabled,
+		MinAvailable: src.Spec.HighAvailability.MinAvailable,
+		Mode:         v1alpha1.HighAvailabilityMode(src.Spec.HighAvailability.Mode),
+		TopologyKey:  src.Spec.HighAvailability.TopologyKey,
+	}
+
+	// Status
+	dst.Status = v1alpha1.QuayEcosystemStatus{
+		Message:       src.Status.Message,
+		Phase:         v1alpha1.QuayEcosystemPhase(src.Status.Phase),
+		Hostname:      src.Status.Hostname,
+		SetupComplete: src.Status.SetupComplete,
+	}
+
+	if src.Status.Certificate != nil {
+		dst.Status.Certificate = (*v1alpha1.CertificateStatus)(src.Status.Certificate)
+	}
+
+	if src.Status.SecurityScannerKey != nil {
+		dst.Status.SecurityScannerKey = (*v1alpha1.SecurityScannerKeyStatus)(src.Status.SecurityScannerKey)
+	}
+
+	if src.Status.Components != nil {
+		dst.Status.Components = make(map[v1alpha1.QuayEcosystemComponent]v1alpha1.ComponentStatus, len(src.Status.Components))
+		for component, componentStatus := range src.Status.Components {
+			dst.Status.Components[v1alpha1.QuayEcosystemComponent(component)] = v1alpha1.ComponentStatus(componentStatus)
+		}
+	}
+
+	if src.Status.Conditions != nil {
+		dst.Status.Conditions = make([]v1alpha1.QuayEcosystemCondition, len(src.Status.Conditions))
+		for i, condition := range src.Status.Conditions {
+			dst.Status.Conditions[i] = v1alpha1.QuayEcosystemCondition{
+				LastTransitionTime: condition.LastTransitionTime,
+				LastUpdateTime:     condition.LastUpdateTime,
+				Message:            condition.Message,
+				Reason:             condition.Reason,
+				Type:               v1alpha1.QuayEcosystemConditionType(condition.Type),
+				Status:             condition.Status,
+			}
+		}
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the v1alpha1 storage version to this QuayEcosystem
+func (dst *QuayEcosystem) ConvertFrom(src *v1alpha1.QuayEcosystem) error {
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.TypeMeta.APIVersion = SchemeGroupVersion.String()
+	dst.TypeMeta.Kind = src.TypeMeta.Kind
+
+	// Quay
+	dst.Spec.Quay = Quay{
+		Autoscaling:          (*Autoscaling)(src.Spec.Quay.Autoscaling),
+		Database:             convertDatabaseFrom(src.Spec.Quay.Database),
+		Image:                src.Spec.Quay.Image,
+		ImagePullSecretName:  src.Spec.Quay.ImagePullSecretName,
+		KeepConfigDeployment: src.Spec.Quay.KeepConfigDeployment,
+		Networking: Networking{
+			ConfigRouteHost:       src.Spec.Quay.ConfigRouteHost,
+			ConfigServiceExposure: (*ServiceExposure)(src.Spec.Quay.ConfigServiceExposure),
+			EnableNodePortService: src.Spec.Quay.EnableNodePortService,
+			ExternalAccess:        convertExternalAccessFrom(src.Spec.Quay.ExternalAccess),
+			IsOpenShift:           src.Spec.Quay.IsOpenShift,
+			RouteHost:             src.Spec.Quay.RouteHost,
+			ServiceExposure:       (*ServiceExposure)(src.Spec.Quay.ServiceExposure),
+		},
+		PodSettings:       ComponentPodSettings(src.Spec.Quay.PodSettings),
+		ConfigPodSettings: ComponentPodSettings(src.Spec.Quay.ConfigPodSettings),
+		Replicas:          src.Spec.Quay.Replicas,
+		Security: Security{
+			CertificateIssuer:              convertCertificateIssuerFrom(src.Spec.Quay.CertificateIssuer),
+			ConfigSecretName:               src.Spec.Quay.ConfigSecretName,
+			ExtraCACertsSecretName:         src.Spec.Quay.ExtraCACertsSecretName,
+			InjectTrustedCABundle:          src.Spec.Quay.InjectTrustedCABundle,
+			SslCertificatesSecretName:      src.Spec.Quay.SslCertificatesSecretName,
+			SuperuserCredentialsSecretName: src.Spec.Quay.SuperuserCredentialsSecretName,
+		},
+		SkipSetup: src.Spec.Quay.SkipSetup,
+		Storage: Storage{
+			PersistentVolume: PersistentVolume{
+				AccessModes:      src.Spec.Quay.RegistryStorage.PersistentVolumeAccessModes,
+				Size:             src.Spec.Quay.RegistryStorage.PersistentVolumeSize,
+				StorageClassName: src.Spec.Quay.RegistryStorage.PersistentVolumeStorageClassName,
+				RetentionPolicy:  PersistentVolumeClaimRetentionPolicy(src.Spec.Quay.RegistryStorage.PersistentVolumeRetentionPolicy),
+			},
+		},
+	}
+
+	if src.Spec.Quay.RegistryBackends != nil {
+		dst.Spec.Quay.Storage.Backends = make([]RegistryBackend, len(src.Spec.Quay.RegistryBackends))
+		for i, registryBackend := range src.Spec.Quay.RegistryBackends {
+			dst.Spec.Quay.Storage.Backends[i] = RegistryBackend{
+				Name:               registryBackend.Name,
+				Preferred:          registryBackend.Preferred,
+				ReplicateByDefault: registryBackend.ReplicateByDefault,
+				RegistryBackendSource: RegistryBackendSource{
+					Local:             (*LocalRegistryBackendSource)(registryBackend.Local),
+					S3:                (*S3RegistryBackendSource)(registryBackend.S3),
+					Azure:             (*AzureRegistryBackendSource)(registryBackend.Azure),
+					GoogleCloud:       (*GoogleCloudRegistryBackendSource)(registryBackend.GoogleCloud),
+					Swift:             (*SwiftRegistryBackendSource)(registryBackend.Swift),
+					ObjectBucketClaim: (*ObjectBucketClaimRegistryBackendSource)(registryBackend.ObjectBucketClaim),
+				},
+			}
+		}
+	}
+
+	// Redis
+	dst.Spec.Redis = Redis{
+		Hostname:            src.Spec.Redis.Hostname,
+		Image:               src.Spec.Redis.Image,
+		ImagePullSecretName: src.Spec.Redis.ImagePullSecretName,
+		PodSettings:         ComponentPodSettings(src.Spec.Redis.PodSettings),
+		Port:                src.Spec.Redis.Port,
+		Replicas:            src.Spec.Redis.Replicas,
+	}
+
+	// Clair
+	dst.Spec.Clair = Clair{
+		API: ClairAPI{
+			Timeout: src.Spec.Clair.APITimeout,
+		},
+		Autoscaling:         (*Autoscaling)(src.Spec.Clair.Autoscaling),
+		Database:            convertDatabaseFrom(src.Spec.Clair.Database),
+		DatabaseCacheSize:   src.Spec.Clair.DatabaseCacheSize,
+		DeploymentMode:      ClairDeploymentMode(src.Spec.Clair.DeploymentMode),
+		Enabled:             src.Spec.Clair.Enabled,
+		Image:               src.Spec.Clair.Image,
+		ImagePullSecretName: src.Spec.Clair.ImagePullSecretName,
+		Notifier: ClairNotifier{
+			Attempts:         src.Spec.Clair.NotifierAttempts,
+			RenotifyInterval: src.Spec.Clair.NotifierRenotifyInterval,
+		},
+		PodSettings: ComponentPodSettings(src.Spec.Clair.PodSettings),
+		Replicas:    src.Spec.Clair.Replicas,
+		Security: ClairSecurity{
+			ExtraCACertsSecretName:    src.Spec.Clair.ExtraCACertsSecretName,
+			InjectTrustedCABundle:     src.Spec.Clair.InjectTrustedCABundle,
+			ScannerKeyExpiration:      src.Spec.Clair.SecurityScannerKeyExpiration,
+			SslCertificatesSecretName: src.Spec.Clair.SslCertificatesSecretName,
+		},
+		Updater: ClairUpdater{
+			EnabledUpdaters: src.Spec.Clair.EnabledUpdaters,
+			Interval:        src.Spec.Clair.UpdateInterval,
+		},
+		Version: ClairVersion(src.Spec.Clair.Version),
+	}
+
+	// High Availability
+	dst.Spec.HighAvailability = HighAvailability{
+		Enabled:      src.Spec.HighAvailability.Enabled,
+		MinAvailable: src.Spec.HighAvailability.MinAvailable,
+		Mode:         HighAvailabilityMode(src.Spec.HighAvailability.Mode),
+		TopologyKey:  src.Spec.HighAvailability.TopologyKey,
+	}
+
+	// Status
+	dst.Status = QuayEcosystemStatus{
+		Message:       src.Status.Message,
+		Phase:         QuayEcosystemPhase(src.Status.Phase),
+		Hostname:      src.Status.Hostname,
+		SetupComplete: src.Status.SetupComplete,
+	}
+
+	if src.Status.Certificate != nil {
+		dst.Status.Certificate = (*CertificateStatus)(src.Status.Certificate)
+	}
+
+	if src.Status.SecurityScannerKey != nil {
+		dst.Status.SecurityScannerKey = (*SecurityScannerKeyStatus)(src.Status.SecurityScannerKey)
+	}
+
+	if src.Status.Components != nil {
+		dst.Status.Components = make(map[QuayEcosystemComponent]ComponentStatus, len(src.Status.Components))
+		for component, componentStatus := range src.Status.Components {
+			dst.Status.Components[QuayEcosystemComponent(component)] = ComponentStatus(componentStatus)
+		}
+	}
+
+	if src.Status.Conditions != nil {
+		dst.Status.Conditions = make([]QuayEcosystemCondition, len(src.Status.Conditions))
+		for i, condition := range src.Status.Conditions {
+			dst.Status.Conditions[i] = QuayEcosystemCondition{
+				LastTransitionTime: condition.LastTransitionTime,
+				LastUpdateTime:     condition.LastUpdateTime,
+				Message:            condition.Message,
+				Reason:             condition.Reason,
+				Type:               QuayEcosystemConditionType(condition.Type),
+				Status:             condition.Status,
+			}
+		}
+	}
+
+	return nil
+}
+
+func convertCertificateIssuerTo(src *CertificateIssuer) *v1alpha1.CertificateIssuer {
+	if src == nil {
+		return nil
+	}
+
+	return &v1alpha1.CertificateIssuer{
+		IncludeClairHostname:  src.IncludeClairHostname,
+		IncludeConfigHostname: src.IncludeConfigHostname,
+		Kind:                  v1alpha1.CertificateIssuerKind(src.Kind),
+		Name:                  src.Name,
+	}
+}
+
+func convertCertificateIssuerFrom(src *v1alpha1.CertificateIssuer) *CertificateIssuer {
+	if src == nil {
+		return nil
+	}
+
+	return &CertificateIssuer{
+		IncludeClairHostname:  src.IncludeClairHostname,
+		IncludeConfigHostname: src.IncludeConfigHostname,
+		Kind:                  CertificateIssuerKind(src.Kind),
+		Name:                  src.Name,
+	}
+}
+
+func convertExternalAccessTo(src *ExternalAccess) *v1alpha1.ExternalAccess {
+	if src == nil {
+		return nil
+	}
+
+	return &v1alpha1.ExternalAccess{
+		Annotations:  src.Annotations,
+		IngressClass: src.IngressClass,
+		Type:         v1alpha1.ExternalAccessType(src.Type),
+	}
+}
+
+func convertExternalAccessFrom(src *v1alpha1.ExternalAccess) *ExternalAccess {
+	if src == nil {
+		return nil
+	}
+
+	return &ExternalAccess{
+		Annotations:  src.Annotations,
+		IngressClass: src.IngressClass,
+		Type:         ExternalAccessType(src.Type),
+	}
+}
+
+func convertDatabaseTo(src Database) v1alpha1.Database {
+	return v1alpha1.Database{
+		CPU:                   src.CPU,
+		CredentialsSecretName: src.CredentialsSecretName,
+		Image:                 src.Image,
+		ImagePullSecretName:   src.ImagePullSecretName,
+		Memory:                src.Memory,
+		PodSettings:           v1alpha1.ComponentPodSettings(src.PodSettings),
+		Replicas:              src.Replicas,
+		RetentionPolicy:       v1alpha1.PersistentVolumeClaimRetentionPolicy(src.RetentionPolicy),
+		Server:                src.Server,
+		VolumeSize:            src.VolumeSize,
+	}
+}
+
+func convertDatabaseFrom(src v1alpha1.Database) Database {
+	return Database{
+		CPU:                   src.CPU,
+		CredentialsSecretName: src.CredentialsSecretName,
+		Image:                 src.Image,
+		ImagePullSecretName:   src.ImagePullSecretName,
+		Memory:                src.Memory,
+		PodSettings:           ComponentPodSettings(src.PodSettings),
+		Replicas:              src.Replicas,
+		RetentionPolicy:       PersistentVolumeClaimRetentionPolicy(src.RetentionPolicy),
+		Server:                src.Server,
+		VolumeSize:            src.VolumeSize,
+	}
+}
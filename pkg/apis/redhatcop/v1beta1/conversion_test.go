@@ -0,0 +1,149 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConvertTo(t *testing.T) {
+
+	replicas := int32(2)
+
+	cases := []struct {
+		quayEcosystem *QuayEcosystem
+		expected      v1alpha1.QuayEcosystemSpec
+	}{
+		{
+			quayEcosystem: &QuayEcosystem{},
+			expected:      v1alpha1.QuayEcosystemSpec{},
+		},
+		{
+			quayEcosystem: &QuayEcosystem{
+				Spec: QuayEcosystemSpec{
+					Quay: Quay{
+						Replicas: &replicas,
+						Networking: Networking{
+							RouteHost: "quay.example.com",
+						},
+						Security: Security{
+							SslCertificatesSecretName: "quay-ssl",
+						},
+						Storage: Storage{
+							Backends: []RegistryBackend{
+								{
+									Name: "local",
+									RegistryBackendSource: RegistryBackendSource{
+										Local: &LocalRegistryBackendSource{
+											StoragePath: "/datastorage/registry",
+										},
+									},
+								},
+							},
+							PersistentVolume: PersistentVolume{
+								Size: "10Gi",
+							},
+						},
+					},
+					Clair: Clair{
+						Security: ClairSecurity{
+							SslCertificatesSecretName: "clair-ssl",
+						},
+					},
+				},
+			},
+			expected: v1alpha1.QuayEcosystemSpec{
+				Quay: v1alpha1.Quay{
+					Replicas:                  &replicas,
+					RouteHost:                 "quay.example.com",
+					SslCertificatesSecretName: "quay-ssl",
+					RegistryBackends: []v1alpha1.RegistryBackend{
+						{
+							Name: "local",
+							RegistryBackendSource: v1alpha1.RegistryBackendSource{
+								Local: &v1alpha1.LocalRegistryBackendSource{
+									StoragePath: "/datastorage/registry",
+								},
+							},
+						},
+					},
+					RegistryStorage: v1alpha1.RegistryStorage{
+						PersistentVolumeSize: "10Gi",
+					},
+				},
+				Clair: v1alpha1.Clair{
+					SslCertificatesSecretName: "clair-ssl",
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		result := &v1alpha1.QuayEcosystem{}
+
+		if err := c.quayEcosystem.ConvertTo(result); err != nil {
+			t.Errorf("Test case %d failed to convert: %s", i, err.Error())
+			continue
+		}
+
+		if !reflect.DeepEqual(c.expected, result.Spec) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result.Spec)
+		}
+	}
+}
+
+func TestRoundTripFromHub(t *testing.T) {
+
+	f := fuzz.NewWithSeed(1).NilChance(0.3).NumElements(0, 3)
+
+	for i := 0; i < 100; i++ {
+
+		original := &v1alpha1.QuayEcosystem{}
+		f.Fuzz(original)
+		original.TypeMeta = metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "QuayEcosystem"}
+
+		spoke := &QuayEcosystem{}
+		if err := spoke.ConvertFrom(original); err != nil {
+			t.Fatalf("Test case %d failed to convert from v1alpha1: %s", i, err.Error())
+		}
+
+		result := &v1alpha1.QuayEcosystem{}
+		if err := spoke.ConvertTo(result); err != nil {
+			t.Fatalf("Test case %d failed to convert to v1alpha1: %s", i, err.Error())
+		}
+
+		if !reflect.DeepEqual(original, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, original, result)
+		}
+	}
+}
+
+func TestRoundTripFromSpoke(t *testing.T) {
+
+	f := fuzz.NewWithSeed(1).NilChance(0.3).NumElements(0, 3)
+
+	for i := 0; i < 100; i++ {
+
+		original := &QuayEcosystem{}
+		f.Fuzz(original)
+		original.TypeMeta = metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "QuayEcosystem"}
+
+		hub := &v1alpha1.QuayEcosystem{}
+		if err := original.ConvertTo(hub); err != nil {
+			t.Fatalf("Test case %d failed to convert to v1alpha1: %s", i, err.Error())
+		}
+
+		result := &QuayEcosystem{}
+		if err := result.ConvertFrom(hub); err != nil {
+			t.Fatalf("Test case %d failed to convert from v1alpha1: %s", i, err.Error())
+		}
+
+		if !reflect.DeepEqual(original, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, original, result)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// QuayEcosystemSpec defines the desired state of QuayEcosystem
+// +k8s:openapi-gen=true
+type QuayEcosystemSpec struct {
+	Quay             Quay             `json:"quay,omitempty"`
+	Redis            Redis            `json:"redis,omitempty"`
+	Clair            Clair            `json:"clair,omitempty"`
+	HighAvailability HighAvailability `json:"highAvailability,omitempty"`
+}
+
+// QuayEcosystemPhase defines the phase of lifecycle the operator is running in
+type QuayEcosystemPhase string
+
+// QuayEcosystemConditionType defines the types of conditions the operator will run through
+type QuayEcosystemConditionType string
+
+// ClairVersion defines the major version of Clair that is deployed
+type ClairVersion string
+
+// ClairDeploymentMode defines how the Clair v4 services are deployed
+type ClairDeploymentMode string
+
+// CertificateIssuerKind defines the kind of cert-manager issuer from which certificates are requested
+type CertificateIssuerKind string
+
+// ExternalAccessType defines the kind of resource through which Quay is exposed outside of the cluster
+type ExternalAccessType string
+
+// HighAvailabilityMode defines how the replicas of a component are spread across the cluster
+type HighAvailabilityMode string
+
+// QuayEcosystemComponent identifies a component of the QuayEcosystem
+type QuayEcosystemComponent string
+
+// PersistentVolumeClaimRetentionPolicy defines what happens to a PersistentVolumeClaim when the QuayEcosystem is deleted
+type PersistentVolumeClaimRetentionPolicy string
+
+// QuayEcosystemStatus defines the observed state of QuayEcosystem
+// +k8s:openapi-gen=true
+type QuayEcosystemStatus struct {
+	Message  string             `json:"message,omitempty"`
+	Phase    QuayEcosystemPhase `json:"phase,omitempty"`
+	Hostname string             `json:"hostname,omitempty"`
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions    []QuayEcosystemCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
+	SetupComplete bool                     `json:"setupComplete,omitempty"`
+	// +optional
+	Components map[QuayEcosystemComponent]ComponentStatus `json:"components,omitempty"`
+	// +optional
+	Certificate *CertificateStatus `json:"certificate,omitempty"`
+	// +optional
+	SecurityScannerKey *SecurityScannerKeyStatus `json:"securityScannerKey,omitempty"`
+}
+
+// ComponentStatus defines the observed state of a component deployed by the operator
+type ComponentStatus struct {
+	DesiredReplicas int32  `json:"desiredReplicas"`
+	ReadyReplicas   int32  `json:"readyReplicas"`
+	Image           string `json:"image,omitempty"`
+	LastError       string `json:"lastError,omitempty"`
+}
+
+// CertificateStatus defines the observed state of the certificate served by Quay
+type CertificateStatus struct {
+	NotAfter   metav1.Time `json:"notAfter,omitempty"`
+	SelfSigned bool        `json:"selfSigned,omitempty"`
+}
+
+// SecurityScannerKeyStatus defines the observed state of the service key Clair uses to authenticate against Quay
+type SecurityScannerKeyStatus struct {
+	CreatedAt   metav1.Time  `json:"createdAt,omitempty"`
+	ExpiresAt   *metav1.Time `json:"expiresAt,omitempty"`
+	ID          string       `json:"id,omitempty"`
+	PreviousIDs []string     `json:"previousIDs,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuayEcosystem is the Schema for the quayecosystems API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type QuayEcosystem struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QuayEcosystemSpec   `json:"spec,omitempty"`
+	Status QuayEcosystemStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuayEcosystemList contains a list of QuayEcosystem
+type QuayEcosystemList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuayEcosystem `json:"items"`
+}
+
+// Quay defines the properies of a deployment of Quay
+type Quay struct {
+	Autoscaling          *Autoscaling         `json:"autoscaling,omitempty"`
+	ConfigPodSettings    ComponentPodSettings `json:"configPodSettings,omitempty"`
+	Database             Database             `json:"database,omitempty"`
+	Image                string               `json:"image,omitempty"`
+	ImagePullSecretName  string               `json:"imagePullSecretName,omitempty"`
+	KeepConfigDeployment bool                 `json:"keepConfigDeployment,omitempty"`
+	Networking           Networking           `json:"networking,omitempty"`
+	PodSettings          ComponentPodSettings `json:"podSettings,omitempty"`
+	Replicas             *int32               `json:"replicas,omitempty"`
+	Security             Security             `json:"security,omitempty"`
+	SkipSetup            bool                 `json:"skipSetup,omitempty"`
+	Storage              Storage              `json:"storage,omitempty"`
+}
+
+// Networking defines how Quay is exposed
+type Networking struct {
+	ConfigRouteHost       string           `json:"configRouteHost,omitempty"`
+	ConfigServiceExposure *ServiceExposure `json:"configServiceExposure,omitempty"`
+	EnableNodePortService bool             `json:"enableNodePortService,omitempty"`
+	ExternalAccess        *ExternalAccess  `json:"externalAccess,omitempty"`
+	// Deprecated: The operator detects whether the cluster serves the OpenShift APIs. Use ExternalAccess.Type to select how Quay is exposed
+	IsOpenShift     bool             `json:"isOpenShift,omitempty"`
+	RouteHost       string           `json:"routeHost,omitempty"`
+	ServiceExposure *ServiceExposure `json:"serviceExposure,omitempty"`
+}
+
+// Security defines the credentials and certificates used by Quay
+type Security struct {
+	CertificateIssuer              *CertificateIssuer `json:"certificateIssuer,omitempty"`
+	ConfigSecretName               string             `json:"configSecretName,omitempty"`
+	ExtraCACertsSecretName         string             `json:"extraCACertsSecretName,omitempty"`
+	InjectTrustedCABundle          bool               `json:"injectTrustedCABundle,omitempty"`
+	SslCertificatesSecretName      string             `json:"sslCertificatesSecretName,omitempty"`
+	SuperuserCredentialsSecretName string             `json:"superuserCredentialsSecretName,omitempty"`
+}
+
+// Storage defines the registry storage of Quay
+type Storage struct {
+	Backends         []RegistryBackend `json:"backends,omitempty"`
+	PersistentVolume PersistentVolume  `json:"persistentVolume,omitempty"`
+}
+
+// PersistentVolume defines the persistent volume supporting local registry storage
+type PersistentVolume struct {
+	AccessModes      []corev1.PersistentVolumeAccessMode  `json:"accessModes,omitempty"`
+	Size             string                               `json:"size,omitempty"`
+	StorageClassName string                               `json:"storageClassName,omitempty"`
+	RetentionPolicy  PersistentVolumeClaimRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// QuayEcosystemCondition defines a list of conditions that the object will transiton through
+type QuayEcosystemCondition struct {
+	LastTransitionTime metav1.Time                `json:"lastTransitionTime,omitempty" protobuf:"bytes,4,opt,name=lastTransitionTime"`
+	LastUpdateTime     metav1.Time                `json:"lastUpdateTime,omitempty" protobuf:"bytes,3,opt,name=lastUpdateTime"`
+	Message            string                     `json:"message,omitempty" protobuf:"bytes,6,opt,name=message"`
+	Reason             string                     `json:"reason,omitempty" protobuf:"bytes,5,opt,name=reason"`
+	Type               QuayEcosystemConditionType `json:"type" protobuf:"bytes,1,opt,name=type,casttype=QuayEcosystemConditionType"`
+	Status             corev1.ConditionStatus     `json:"status" protobuf:"bytes,2,opt,name=status,casttype=k8s.io/kubernetes/pkg/api/v1.ConditionStatus"`
+}
+
+// Redis defines the properies of a deployment of Redis
+type Redis struct {
+	Hostname            string               `json:"hostname,omitempty"`
+	Image               string               `json:"image,omitempty"`
+	ImagePullSecretName string               `json:"imagePullSecretName,omitempty"`
+	PodSettings         ComponentPodSettings `json:"podSettings,omitempty"`
+	Port                *int32               `json:"port,omitempty"`
+	Replicas            *int32               `json:"replicas,omitempty"`
+}
+
+// Database defines a database that will be deployed to support a particular component
+type Database struct {
+	CPU                   string                               `json:"cpu,omitempty"`
+	CredentialsSecretName string                               `json:"credentialsSecretName,omitempty"`
+	Image                 string                               `json:"image,omitempty"`
+	ImagePullSecretName   string                               `json:"imagePullSecretName,omitempty"`
+	Memory                string                               `json:"memory,omitempty"`
+	PodSettings           ComponentPodSettings                 `json:"podSettings,omitempty"`
+	Replicas              *int32                               `json:"replicas,omitempty"`
+	RetentionPolicy       PersistentVolumeClaimRetentionPolicy `json:"retentionPolicy,omitempty"`
+	Server                string                               `json:"server,omitempty"`
+	VolumeSize            string                               `json:"volumeSize,omitempty"`
+}
+
+// CertificateIssuer defines the cert-manager issuer from which the certificate served by Quay is requested
+type CertificateIssuer struct {
+	IncludeClairHostname  bool                  `json:"includeClairHostname,omitempty"`
+	IncludeConfigHostname bool                  `json:"includeConfigHostname,omitempty"`
+	Kind                  CertificateIssuerKind `json:"kind,omitempty"`
+	Name                  string                `json:"name"`
+}
+
+// ExternalAccess defines how Quay is exposed outside of the cluster
+type ExternalAccess struct {
+	Annotations  map[string]string  `json:"annotations,omitempty"`
+	IngressClass string             `json:"ingressClass,omitempty"`
+	Type         ExternalAccessType `json:"type,omitempty"`
+}
+
+// ServiceExposure defines how the Service of a component is exposed
+type ServiceExposure struct {
+	Annotations              map[string]string                       `json:"annotations,omitempty"`
+	ExternalTrafficPolicy    corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+	LoadBalancerSourceRanges []string                                `json:"loadBalancerSourceRanges,omitempty"`
+	NodePort                 int32                                   `json:"nodePort,omitempty"`
+	Type                     corev1.ServiceType                      `json:"type,omitempty"`
+}
+
+// Autoscaling defines the HorizontalPodAutoscaler that manages the replicas of a component
+type Autoscaling struct {
+	MaxReplicas                       int32  `json:"maxReplicas"`
+	MinReplicas                       *int32 `json:"minReplicas,omitempty"`
+	TargetCPUUtilizationPercentage    *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// HighAvailability defines how the Quay and Clair replicas are protected against node failures and voluntary disruptions
+type HighAvailability struct {
+	Enabled      bool                 `json:"enabled,omitempty"`
+	MinAvailable *intstr.IntOrString  `json:"minAvailable,omitempty"`
+	Mode         HighAvailabilityMode `json:"mode,omitempty"`
+	TopologyKey  string               `json:"topologyKey,omitempty"`
+}
+
+// Clair defines the properties of a deployment of Clair
+type Clair struct {
+	API                 ClairAPI             `json:"api,omitempty"`
+	Autoscaling         *Autoscaling         `json:"autoscaling,omitempty"`
+	Database            Database             `json:"database,omitempty"`
+	DatabaseCacheSize   *int32               `json:"databaseCacheSize,omitempty"`
+	DeploymentMode      ClairDeploymentMode  `json:"deploymentMode,omitempty"`
+	Enabled             *bool                `json:"enabled,omitempty"`
+	Image               string               `json:"image,omitempty"`
+	ImagePullSecretName string               `json:"imagePullSecretName,omitempty"`
+	Notifier            ClairNotifier        `json:"notifier,omitempty"`
+	PodSettings         ComponentPodSettings `json:"podSettings,omitempty"`
+	Replicas            *int32               `json:"replicas,omitempty"`
+	Security            ClairSecurity        `json:"security,omitempty"`
+	Updater             ClairUpdater         `json:"updater,omitempty"`
+	Version             ClairVersion         `json:"version,omitempty"`
+}
+
+// ClairAPI defines the behavior of the Clair API
+type ClairAPI struct {
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ClairNotifier defines how Clair notifies Quay of new vulnerabilities
+type ClairNotifier struct {
+	Attempts         *int32 `json:"attempts,omitempty"`
+	RenotifyInterval string `json:"renotifyInterval,omitempty"`
+}
+
+// ClairUpdater defines how Clair refreshes its vulnerability data
+type ClairUpdater struct {
+	EnabledUpdaters []string `json:"enabledUpdaters,omitempty"`
+	Interval        string   `json:"interval,omitempty"`
+}
+
+// ComponentPodSettings defines the resources and scheduling constraints applied to the pods of a component
+type ComponentPodSettings struct {
+	Affinity          *corev1.Affinity            `json:"affinity,omitempty"`
+	NodeSelector      map[string]string           `json:"nodeSelector,omitempty"`
+	PriorityClassName string                      `json:"priorityClassName,omitempty"`
+	Resources         corev1.ResourceRequirements `json:"resources,omitempty"`
+	Tolerations       []corev1.Toleration         `json:"tolerations,omitempty"`
+}
+
+// ClairSecurity defines the certificates used by Clair
+type ClairSecurity struct {
+	ExtraCACertsSecretName    string `json:"extraCACertsSecretName,omitempty"`
+	InjectTrustedCABundle     bool   `json:"injectTrustedCABundle,omitempty"`
+	ScannerKeyExpiration      string `json:"scannerKeyExpiration,omitempty"`
+	SslCertificatesSecretName string `json:"sslCertificatesSecretName,omitempty"`
+}
+
+// RegistryBackend defines a particular backend supporting the Quay registry
+type RegistryBackend struct {
+	Name                  string `json:"name"`
+	Preferred             bool   `json:"preferred,omitempty"`
+	ReplicateByDefault    bool   `json:"replicateByDefault,omitempty"`
+	RegistryBackendSource `json:",inline" protobuf:"bytes,2,opt,name=registryBackendSource"`
+}
+
+// RegistryBackendSource defines the specific configurations to support the Quay registry
+type RegistryBackendSource struct {
+	Local             *LocalRegistryBackendSource             `json:"local,omitempty,name=local"`
+	S3                *S3RegistryBackendSource                `json:"s3,omitempty,name=s3"`
+	Azure             *AzureRegistryBackendSource             `json:"azure,omitempty,name=azure"`
+	GoogleCloud       *GoogleCloudRegistryBackendSource       `json:"googleCloud,omitempty,name=googleCloud"`
+	Swift             *SwiftRegistryBackendSource             `json:"swift,omitempty,name=swift"`
+	ObjectBucketClaim *ObjectBucketClaimRegistryBackendSource `json:"objectBucketClaim,omitempty,name=objectBucketClaim"`
+}
+
+// LocalRegistryBackendSource defines local registry storage
+type LocalRegistryBackendSource struct {
+	StoragePath string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// S3RegistryBackendSource defines S3 or S3 compatible (RADOS Gateway, MinIO) registry storage
+type S3RegistryBackendSource struct {
+	Bucket                string `json:"bucket,omitempty,name=bucket"`
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	Endpoint              string `json:"endpoint,omitempty,name=endpoint"`
+	Region                string `json:"region,omitempty,name=region"`
+	StoragePath           string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// AzureRegistryBackendSource defines Azure Blob Storage registry storage
+type AzureRegistryBackendSource struct {
+	AccountName           string `json:"accountName,omitempty,name=accountName"`
+	Container             string `json:"container,omitempty,name=container"`
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	StoragePath           string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// GoogleCloudRegistryBackendSource defines Google Cloud Storage registry storage
+type GoogleCloudRegistryBackendSource struct {
+	Bucket                string `json:"bucket,omitempty,name=bucket"`
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	StoragePath           string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// SwiftRegistryBackendSource defines OpenStack Swift registry storage
+type SwiftRegistryBackendSource struct {
+	AuthURL               string            `json:"authURL,omitempty,name=authURL"`
+	AuthVersion           int               `json:"authVersion,omitempty,name=authVersion"`
+	CACertSecretName      string            `json:"caCertSecretName,omitempty,name=caCertSecretName"`
+	Container             string            `json:"container,omitempty,name=container"`
+	CredentialsSecretName string            `json:"credentialsSecretName,omitempty,name=credentialsSecretName"`
+	OSOptions             map[string]string `json:"osOptions,omitempty,name=osOptions"`
+	StoragePath           string            `json:"storagePath,omitempty,name=storagePath"`
+}
+
+// ObjectBucketClaimRegistryBackendSource defines registry storage provisioned through an ObjectBucketClaim (Ceph RADOS Gateway)
+type ObjectBucketClaimRegistryBackendSource struct {
+	Name             string `json:"name,omitempty,name=name"`
+	Secure           bool   `json:"secure,omitempty,name=secure"`
+	StorageClassName string `json:"storageClassName,omitempty,name=storageClassName"`
+	StoragePath      string `json:"storagePath,omitempty,name=storagePath"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuayEcosystem{}, &QuayEcosystemList{})
+}
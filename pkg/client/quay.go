@@ -73,16 +73,27 @@ func (c *QuayClient) GetRegistryStatus() (*http.Response, RegistryStatus, error)
 	return resp, registryStatus, err
 }
 
-// func (c *QuayClient) GetQuayKeys() (*http.Response, QuayKeys, error) {
-// 	req, err := c.newRequest("GET", "/api/v1/superuser/keys", nil)
-// 	if err != nil {
-// 		return nil, QuayKeys{}, err
-// 	}
-// 	var quayKeys QuayKeys
-// 	resp, err := c.do(req, &quayKeys)
-
-// 	return resp, quayKeys, err
-// }
+func (c *QuayClient) GetQuayKeys() (*http.Response, QuayKeys, error) {
+	req, err := c.newRequest("GET", "/api/v1/superuser/keys", nil)
+	if err != nil {
+		return nil, QuayKeys{}, err
+	}
+	var quayKeys QuayKeys
+	resp, err := c.do(req, &quayKeys)
+
+	return resp, quayKeys, err
+}
+
+func (c *QuayClient) DeleteQuayKey(kid string) (*http.Response, StringValue, error) {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/superuser/keys/%s", kid), nil)
+	if err != nil {
+		return nil, StringValue{}, err
+	}
+	var deleteResponse StringValue
+	resp, err := c.do(req, &deleteResponse)
+
+	return resp, deleteResponse, err
+}
 
 func (c *QuayClient) ValidateDatabase(config QuayConfig) (*http.Response, QuayStatusResponse, error) {
 	req, err := c.newRequest("POST", "/api/v1/superuser/config/validate/database", config)
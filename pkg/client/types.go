@@ -8,9 +8,17 @@ type QuayConfig struct {
 	Config map[string]interface{} `json:"config"`
 }
 
-// type QuayKeys struct {
-// 	Keys []interface{} `json:"keys"`
-// }
+type QuayKeys struct {
+	Keys []QuayKey `json:"keys"`
+}
+
+type QuayKey struct {
+	Kid            string `json:"kid"`
+	Name           string `json:"name"`
+	Service        string `json:"service"`
+	CreatedDate    string `json:"created_date"`
+	ExpirationDate string `json:"expiration_date"`
+}
 
 type QuayStatusResponse struct {
 	Status bool   `json:"status,omitempty"`
@@ -5,12 +5,12 @@ import (
 )
 
 // AddToManagerFuncs is a list of functions to add all Controllers to the Manager
-var AddToManagerFuncs []func(manager.Manager) error
+var AddToManagerFuncs []func(manager.Manager, bool) error
 
-// AddToManager adds all Controllers to the Manager
-func AddToManager(m manager.Manager) error {
+// AddToManager adds all Controllers to the Manager. isOpenShift reports whether the cluster serves the OpenShift APIs
+func AddToManager(m manager.Manager, isOpenShift bool) error {
 	for _, f := range AddToManagerFuncs {
-		if err := f(m); err != nil {
+		if err := f(m, isOpenShift); err != nil {
 			return err
 		}
 	}
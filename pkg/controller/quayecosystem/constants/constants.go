@@ -1,7 +1,10 @@
 package constants
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -13,6 +16,8 @@ const (
 	QuayImage = "quay.io/redhat/quay:v3.0.3"
 	// ClairImage is the Clair image
 	ClairImage = "quay.io/redhat/clair-jwt:v3.0.4"
+	// ClairV4Image is the Clair v4 image
+	ClairV4Image = "quay.io/projectquay/clair:4.0.0"
 	// ImagePullSecret is the name of the image pull secret for retrieving images from a protected image registry
 	ImagePullSecret = "redhat-pull-secret"
 	// RedisImage is the name of the Redis Image
@@ -33,6 +38,16 @@ const (
 	LabelComponentClairValue = "clair"
 	// LabelComponentQuayDatabaseValue is the name of the Quay database label
 	LabelComponentQuayDatabaseValue = "quay-database"
+	// LabelComponentClairDatabaseValue is the name of the Clair database label
+	LabelComponentClairDatabaseValue = "clair-database"
+	// LabelComponentClairIndexerValue is the name of the Clair v4 indexer label
+	LabelComponentClairIndexerValue = "clair-indexer"
+	// LabelComponentClairMatcherValue is the name of the Clair v4 matcher label
+	LabelComponentClairMatcherValue = "clair-matcher"
+	// LabelComponentClairNotifierValue is the name of the Clair v4 notifier label
+	LabelComponentClairNotifierValue = "clair-notifier"
+	// LabelComponentStorageReplicationValue is the name of the storage replication worker label
+	LabelComponentStorageReplicationValue = "storage-replication"
 	// LabelQuayCRKey is the label name of the quay custom resource
 	LabelQuayCRKey = "quay-enterprise-cr"
 	// AnyUIDSCC is the name of the anyuid SCC
@@ -106,6 +121,8 @@ const (
 	QuayContainerConfigName = "quay-enterprise-config"
 	// QuayContainerAppName represents the name of the Quay app container
 	QuayContainerAppName = "quay-enterprise-app"
+	// QuayContainerStorageReplicationName represents the name of the Quay storage replication worker container
+	QuayContainerStorageReplicationName = "quay-enterprise-storage-replication"
 	// QuayContainerCertSecret is the name of the secret for extra Quay certificates
 	QuayContainerCertSecret = "quay-enterprise-cert-secret"
 	// QuaySuperuserUsernameKey represents the key for the superuser username
@@ -134,11 +151,134 @@ const (
 	RegistryStorageDefaultName = "default"
 	// RegistryStorageTypeLocalStorageName is the value of the Local Quay Storage type
 	RegistryStorageTypeLocalStorageName = "LocalStorage"
+	// RegistryStorageTypeS3StorageName is the value of the AWS S3 Quay Storage type
+	RegistryStorageTypeS3StorageName = "S3Storage"
+	// RegistryStorageTypeRadosGWStorageName is the value of the RADOS Gateway (S3 compatible) Quay Storage type
+	RegistryStorageTypeRadosGWStorageName = "RadosGWStorage"
+	// RegistryStorageTypeAzureStorageName is the value of the Azure Blob Quay Storage type
+	RegistryStorageTypeAzureStorageName = "AzureStorage"
+	// RegistryStorageTypeGoogleCloudStorageName is the value of the Google Cloud Quay Storage type
+	RegistryStorageTypeGoogleCloudStorageName = "GoogleCloudStorage"
+	// RegistryStorageTypeSwiftStorageName is the value of the OpenStack Swift Quay Storage type
+	RegistryStorageTypeSwiftStorageName = "SwiftStorage"
+
+	// RegistryBackendAccessKeyKey represents the key for the registry backend access key
+	RegistryBackendAccessKeyKey = "access-key"
+	// RegistryBackendSecretKeyKey represents the key for the registry backend secret key
+	RegistryBackendSecretKeyKey = "secret-key"
+	// RegistryBackendAzureAccountKeyKey represents the key for the Azure storage account key
+	RegistryBackendAzureAccountKeyKey = "account-key"
+	// RegistryBackendAzureSasTokenKey represents the key for the Azure storage SAS token
+	RegistryBackendAzureSasTokenKey = "sas-token"
+	// RegistryBackendSwiftUserKey represents the key for the Swift user
+	RegistryBackendSwiftUserKey = "user"
+	// RegistryBackendSwiftPasswordKey represents the key for the Swift password
+	RegistryBackendSwiftPasswordKey = "password"
+	// RegistryBackendSwiftApplicationCredentialIDKey represents the key for the Swift (Keystone v3) application credential ID
+	RegistryBackendSwiftApplicationCredentialIDKey = "application-credential-id"
+	// RegistryBackendSwiftApplicationCredentialSecretKey represents the key for the Swift (Keystone v3) application credential secret
+	RegistryBackendSwiftApplicationCredentialSecretKey = "application-credential-secret"
+	// RegistryBackendCACertificateKey represents the key for a CA certificate used by a registry backend
+	RegistryBackendCACertificateKey = "ca.crt"
+
+	// QuayConfigDirectory represents the location where the Quay configuration is mounted in the container
+	QuayConfigDirectory = "/conf/stack"
+	// QuayExtraCACertsDirectory represents the directory within the Quay configuration containing additional trusted certificates
+	QuayExtraCACertsDirectory = "extra_ca_certs"
+	// ClairTrustAnchorsDirectory represents the directory of the Clair container from which additional trusted certificates are loaded
+	ClairTrustAnchorsDirectory = "/etc/pki/ca-trust/source/anchors"
+	// ClairTrustCAExtraCertPrefix represents the prefix of the extra CA certificates stored in the Clair trust CA secret
+	ClairTrustCAExtraCertPrefix = "extra-"
+
+	// TrustedCABundleInjectLabel represents the label requesting OpenShift to inject the cluster trusted CA bundle into a ConfigMap
+	TrustedCABundleInjectLabel = "config.openshift.io/inject-trusted-cabundle"
+	// TrustedCABundleKey represents the key in the trusted CA bundle ConfigMap containing the certificates
+	TrustedCABundleKey = "ca-bundle.crt"
+
+	// IngressClassAnnotation represents the annotation selecting the controller that serves an Ingress
+	IngressClassAnnotation = "kubernetes.io/ingress.class"
+
+	// IngressBackendProtocolAnnotation represents the annotation selecting the protocol the NGINX ingress controller uses to connect to its backend
+	IngressBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+
+	// ObjectBucketClaimAPIVersion represents the API version of the ObjectBucketClaim resource
+	ObjectBucketClaimAPIVersion = "objectbucket.io/v1alpha1"
+	// ObjectBucketClaimKind represents the kind of the ObjectBucketClaim resource
+	ObjectBucketClaimKind = "ObjectBucketClaim"
+	// ObjectBucketClaimBoundPhase represents the phase of an ObjectBucketClaim once the bucket has been provisioned
+	ObjectBucketClaimBoundPhase = "Bound"
+	// ObjectBucketClaimBucketHostKey represents the key in the generated ConfigMap for the bucket host
+	ObjectBucketClaimBucketHostKey = "BUCKET_HOST"
+	// ObjectBucketClaimBucketPortKey represents the key in the generated ConfigMap for the bucket port
+	ObjectBucketClaimBucketPortKey = "BUCKET_PORT"
+	// ObjectBucketClaimBucketNameKey represents the key in the generated ConfigMap for the bucket name
+	ObjectBucketClaimBucketNameKey = "BUCKET_NAME"
+	// ObjectBucketClaimAccessKeyIDKey represents the key in the generated Secret for the access key
+	ObjectBucketClaimAccessKeyIDKey = "AWS_ACCESS_KEY_ID"
+	// ObjectBucketClaimSecretAccessKeyKey represents the key in the generated Secret for the secret key
+	ObjectBucketClaimSecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+
+	// VolumeSnapshotAPIVersion represents the API version of the VolumeSnapshot resource
+	VolumeSnapshotAPIVersion = "snapshot.storage.k8s.io/v1alpha1"
+	// VolumeSnapshotKind represents the kind of the VolumeSnapshot resource
+	VolumeSnapshotKind = "VolumeSnapshot"
+
+	// CertificateAPIVersion represents the API version of the cert-manager Certificate resource
+	CertificateAPIVersion = "cert-manager.io/v1alpha2"
+	// CertificateKind represents the kind of the cert-manager Certificate resource
+	CertificateKind = "Certificate"
+	// CertificateIssuerGroup represents the API group of cert-manager issuers
+	CertificateIssuerGroup = "cert-manager.io"
+	// CertificateNameAnnotation represents the annotation cert-manager sets on an issued Secret to reference its Certificate
+	CertificateNameAnnotation = "cert-manager.io/certificate-name"
+
+	// HighAvailabilityTopologyKey represents the default topology domain across which Quay and Clair replicas are spread
+	HighAvailabilityTopologyKey = "kubernetes.io/hostname"
+
+	// ClairPaginationKeyRotationAnnotation represents the annotation whose value, when changed, triggers the regeneration of the Clair pagination key
+	ClairPaginationKeyRotationAnnotation = "redhatcop.redhat.io/clair-pagination-key-rotation"
+
+	// SslCertificateNotAfterAnnotation represents the pod annotation holding the expiry of the certificate served by Quay
+	SslCertificateNotAfterAnnotation = "redhatcop.redhat.io/ssl-certificate-not-after"
+
+	// SecurityScannerKeyIDAnnotation represents the Clair pod annotation holding the ID of the security scanner key in use
+	SecurityScannerKeyIDAnnotation = "redhatcop.redhat.io/security-scanner-key-id"
 
+	// SecurityScannerModeAnnotation represents the Quay pod annotation holding how security scanning is configured
+	SecurityScannerModeAnnotation = "redhatcop.redhat.io/security-scanner-mode"
+
+	// ExtraCACertsHashAnnotation represents the pod annotation holding a hash of the extra CA certificates trusted by Quay or Clair
+	ExtraCACertsHashAnnotation = "redhatcop.redhat.io/extra-ca-certs-hash"
+
+	// QuayEcosystemFinalizer represents the finalizer used to clean up resources that cannot be garbage collected
+	QuayEcosystemFinalizer = "finalizer.redhatcop.redhat.io"
+
+	// QuayConfigKey is key in the Quay config secret representing the Quay configuration
+	QuayConfigKey = "config.yaml"
 	// ClairConfigKey is key in the Clair config secret representing the Clair configuration
 	ClairConfigKey = "config.yaml"
+	// ClairPaginationKeySecretKey is key in the Clair config secret representing the key used to encrypt pagination tokens
+	ClairPaginationKeySecretKey = "paginationkey"
+	// ClairPSKSecretKey is key in the Clair config secret representing the pre-shared key used to authenticate against Clair v4
+	ClairPSKSecretKey = "psk"
 	// ClairTrustCASecretKey is key in the clair trust ca secret representing the Clair trust CA Certificate
 	ClairTrustCASecretKey = "ca.crt"
+	// ClairUpdateInterval represents the default interval between Clair vulnerability updates
+	ClairUpdateInterval = "6h"
+	// ClairNotifierRenotifyInterval represents the default interval before Clair resends a notification
+	ClairNotifierRenotifyInterval = "1h"
+	// ClairAPITimeout represents the default timeout of the Clair API
+	ClairAPITimeout = "900s"
+	// ClairPaginationKeyLength represents the number of random bytes in a generated Clair pagination key
+	ClairPaginationKeyLength = 32
+	// ClairPSKLength represents the number of random bytes in a generated Clair v4 pre-shared key
+	ClairPSKLength = 32
+	// SecurityScannerKeyName represents the name of the service key created for the security scanner
+	SecurityScannerKeyName = "security_scanner Service Key"
+	// SecurityScannerKeyService represents the Quay service the security scanner key is registered for
+	SecurityScannerKeyService = "security_scanner"
+	// SecurityScannerKeyRotationThreshold represents the fraction of the security scanner key lifetime after which the key is rotated
+	SecurityScannerKeyRotationThreshold = 0.8
 	// SecurityScannerKeySecretKey is key in the security scanner key secret representing the security scanner private key
 	SecurityScannerKeySecretKey = "security_scanner.pem"
 
@@ -148,6 +288,10 @@ const (
 	QuayAppConfigSSLPrivateKeySecretKey = "ssl.key"
 	//QuayNamespaceEnvironmentVariable is the name of the environment variable to specify the namespace Quay is deployed within
 	QuayNamespaceEnvironmentVariable = "QE_K8S_NAMESPACE"
+	// QuayServicesEnvironmentVariable is the name of the environment variable to limit the services started within the Quay container
+	QuayServicesEnvironmentVariable = "QUAY_SERVICES"
+	// QuayStorageReplicationServiceName is the name of the Quay storage replication worker service
+	QuayStorageReplicationServiceName = "storagereplication"
 )
 
 var (
@@ -177,6 +321,27 @@ var (
 	// RequiredDatabaseCredentialKeys represents the keys that are required for a provided database credential
 	RequiredDatabaseCredentialKeys = []string{DatabaseCredentialsUsernameKey, DatabaseCredentialsPasswordKey, DatabaseCredentialsDatabaseKey}
 
+	// RequiredS3CredentialKeys represents the keys that are required for a provided S3 registry backend credential
+	RequiredS3CredentialKeys = []string{RegistryBackendAccessKeyKey, RegistryBackendSecretKeyKey}
+
+	// RequiredGoogleCloudCredentialKeys represents the keys that are required for a provided Google Cloud registry backend credential
+	RequiredGoogleCloudCredentialKeys = []string{RegistryBackendAccessKeyKey, RegistryBackendSecretKeyKey}
+
+	// RequiredSwiftPasswordCredentialKeys represents the keys that are required for a provided Swift user/password credential
+	RequiredSwiftPasswordCredentialKeys = []string{RegistryBackendSwiftUserKey, RegistryBackendSwiftPasswordKey}
+
+	// RequiredSwiftApplicationCredentialKeys represents the keys that are required for a provided Swift application credential
+	RequiredSwiftApplicationCredentialKeys = []string{RegistryBackendSwiftApplicationCredentialIDKey, RegistryBackendSwiftApplicationCredentialSecretKey}
+
+	// RequiredObjectBucketClaimConfigMapKeys represents the keys that are required in the ConfigMap generated for an ObjectBucketClaim
+	RequiredObjectBucketClaimConfigMapKeys = []string{ObjectBucketClaimBucketHostKey, ObjectBucketClaimBucketPortKey, ObjectBucketClaimBucketNameKey}
+
+	// RequiredObjectBucketClaimSecretKeys represents the keys that are required in the Secret generated for an ObjectBucketClaim
+	RequiredObjectBucketClaimSecretKeys = []string{ObjectBucketClaimAccessKeyIDKey, ObjectBucketClaimSecretAccessKeyKey}
+
+	// RequiredCACertificateKeys represents the keys that are required for a provided CA certificate
+	RequiredCACertificateKeys = []string{RegistryBackendCACertificateKey}
+
 	// RequiredSslCertificateKeys represents the keys that are required for a provided SSL certificate
 	RequiredSslCertificateKeys = []string{QuayAppConfigSSLCertificateSecretKey, QuayAppConfigSSLPrivateKeySecretKey}
 
@@ -191,6 +356,23 @@ var (
 	RedisPort int32 = 6379
 	// ClairReplicas is the port number for Clair
 	ClairReplicas int32 = 1
+	// ClairNotifierAttempts represents the default number of attempts Clair makes to deliver a notification
+	ClairNotifierAttempts int32 = 3
+	// ClairDatabaseCacheSize represents the default size of the Clair database cache
+	ClairDatabaseCacheSize int32 = 16384
+
+	// ClairComponents represents every component Clair can be deployed as
+	ClairComponents = []string{LabelComponentClairValue, LabelComponentClairIndexerValue, LabelComponentClairMatcherValue, LabelComponentClairNotifierValue}
+
+	// QuaySslCertificateRenewBefore represents how long before its expiry a self-signed Quay certificate is regenerated
+	QuaySslCertificateRenewBefore = 30 * 24 * time.Hour
+	// ClairRolloutPollInterval represents how often the Clair rollout is checked before the security scanner keys it replaced are deleted
+	ClairRolloutPollInterval = 10 * time.Second
+	// OperationAnnotations represents the QuayEcosystem annotations whose changes trigger a reconciliation
+	OperationAnnotations = []string{ClairPaginationKeyRotationAnnotation}
+
+	// HighAvailabilityMinAvailable represents the default number of Quay and Clair pods that must remain available during a disruption
+	HighAvailabilityMinAvailable = intstr.FromInt(1)
 
 	// QuayRegistryStoragePersistentVolumeAccessModes represents the access modes for the registry storage persistent volume
 	QuayRegistryStoragePersistentVolumeAccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
@@ -0,0 +1,297 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	ossecurityv1 "github.com/openshift/api/security/v1"
+	"github.com/redhat-cop/operator-utils/pkg/util"
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// CleanupResources removes the side effects of the QuayEcosystem that are not garbage collected through owner references
+// and applies the retention policy of each PersistentVolumeClaim
+func (r *ReconcileQuayEcosystemConfiguration) CleanupResources(metaObject metav1.ObjectMeta) (*reconcile.Result, error) {
+
+	// SCCs are specific to OpenShift
+	if r.quayConfiguration.IsOpenShift {
+		if err := r.removeAnyUIDSCCUsers(metaObject); err != nil {
+			logging.Log.Error(err, "Failed to remove users from SCC")
+			return nil, err
+		}
+	}
+
+	persistentVolumeClaims := map[string]redhatcopv1alpha1.PersistentVolumeClaimRetentionPolicy{
+		resources.GetQuayDatabaseName(r.quayConfiguration.QuayEcosystem):  r.quayConfiguration.QuayEcosystem.Spec.Quay.Database.RetentionPolicy,
+		resources.GetClairDatabaseName(r.quayConfiguration.QuayEcosystem): r.quayConfiguration.QuayEcosystem.Spec.Clair.Database.RetentionPolicy,
+	}
+
+	for _, registryBackend := range r.quayConfiguration.QuayEcosystem.Spec.Quay.RegistryBackends {
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Local) {
+			persistentVolumeClaims[resources.GetRegistryStorageVolumeName(r.quayConfiguration.QuayEcosystem, registryBackend.Name)] = r.quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeRetentionPolicy
+		}
+	}
+
+	for persistentVolumeClaimName, retentionPolicy := range persistentVolumeClaims {
+
+		result, err := r.applyRetentionPolicy(metaObject, persistentVolumeClaimName, retentionPolicy)
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to apply PersistentVolumeClaim retention policy", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", persistentVolumeClaimName, "RetentionPolicy", retentionPolicy)
+			return nil, err
+		}
+
+		if result != nil {
+			return result, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// RemoveClairResources tears down the resources supporting Clair once security scanning has been disabled. The Clair
+// database credentials and storage are kept so that scanning can be enabled again without losing its data
+func (r *ReconcileQuayEcosystemConfiguration) RemoveClairResources(metaObject metav1.ObjectMeta) error {
+
+	namespace := r.quayConfiguration.QuayEcosystem.Namespace
+	clairDatabaseName := resources.GetClairDatabaseName(r.quayConfiguration.QuayEcosystem)
+
+	clairResources := []runtime.Object{
+		&routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: resources.GetClairResourcesName(r.quayConfiguration.QuayEcosystem), Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.GetClairConfigSecretName(r.quayConfiguration.QuayEcosystem), Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.GetSecurityScannerKeySecretName(r.quayConfiguration.QuayEcosystem), Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.GetClairTrustCASecretName(r.quayConfiguration.QuayEcosystem), Namespace: namespace}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: clairDatabaseName, Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: clairDatabaseName, Namespace: namespace}},
+	}
+
+	for _, clairComponent := range constants.ClairComponents {
+		clairResources = append(clairResources, r.getClairComponentResources(clairComponent)...)
+	}
+
+	return r.deleteResources(clairResources)
+}
+
+// removeQuayExternalAccess removes the Route or Ingress of Quay once it is reached directly through its load balancer
+func (r *ReconcileQuayEcosystemConfiguration) removeQuayExternalAccess(metaObject metav1.ObjectMeta) error {
+
+	namespace := r.quayConfiguration.QuayEcosystem.Namespace
+	name := resources.GetQuayResourcesName(r.quayConfiguration.QuayEcosystem)
+
+	return r.deleteResources([]runtime.Object{
+		&routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&extensionsv1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+	})
+}
+
+// removeUnusedClairComponents removes the resources of the Clair components that are not part of the current deployment mode
+func (r *ReconcileQuayEcosystemConfiguration) removeUnusedClairComponents(metaObject metav1.ObjectMeta) error {
+
+	usedClairComponents := map[string]bool{}
+
+	for _, clairComponent := range resources.GetClairComponents(r.quayConfiguration.QuayEcosystem) {
+		usedClairComponents[clairComponent] = true
+	}
+
+	clairResources := []runtime.Object{}
+
+	for _, clairComponent := range constants.ClairComponents {
+		if !usedClairComponents[clairComponent] {
+			clairResources = append(clairResources, r.getClairComponentResources(clairComponent)...)
+		}
+	}
+
+	return r.deleteResources(clairResources)
+}
+
+// getClairComponentResources returns the resources that are created for a single Clair component
+func (r *ReconcileQuayEcosystemConfiguration) getClairComponentResources(clairComponent string) []runtime.Object {
+
+	namespace := r.quayConfiguration.QuayEcosystem.Namespace
+	name := resources.GetClairComponentResourcesName(r.quayConfiguration.QuayEcosystem, clairComponent)
+
+	clairComponentResources := []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&autoscalingv2beta1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&policyv1beta1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+	}
+
+	// The Clair route is shared by every component. Only the indexer and notifier have a route of their own
+	if _, found := clairComponentPaths[clairComponent]; found {
+		clairComponentResources = append(clairComponentResources, &routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}})
+	}
+
+	return clairComponentResources
+}
+
+// deleteResources deletes resources of the QuayEcosystem, ignoring those that do not exist
+func (r *ReconcileQuayEcosystemConfiguration) deleteResources(objects []runtime.Object) error {
+
+	for _, object := range objects {
+
+		err := r.reconcilerBase.GetClient().Delete(context.TODO(), object)
+
+		// Routes are not available outside of OpenShift
+		if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err) {
+			logging.Log.Error(err, "Failed to remove resource", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Kind", fmt.Sprintf("%T", object))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeAnyUIDSCCUsers removes the service accounts added by configureAnyUIDSCCs from the anyuid SCC
+func (r *ReconcileQuayEcosystemConfiguration) removeAnyUIDSCCUsers(metaObject metav1.ObjectMeta) error {
+
+	anyUIDSCC := &ossecurityv1.SecurityContextConstraints{}
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: constants.AnyUIDSCC, Namespace: ""}, anyUIDSCC)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	// The service account is shared by every QuayEcosystem in the namespace
+	quayEcosystems := &redhatcopv1alpha1.QuayEcosystemList{}
+
+	if err := r.reconcilerBase.GetClient().List(context.TODO(), &client.ListOptions{Namespace: metaObject.Namespace}, quayEcosystems); err != nil {
+		return err
+	}
+
+	for _, quayEcosystem := range quayEcosystems.Items {
+		if quayEcosystem.UID != r.quayConfiguration.QuayEcosystem.UID && !util.IsBeingDeleted(&quayEcosystem) {
+			return nil
+		}
+	}
+
+	sccUser := getSCCUser(metaObject.Namespace, constants.QuayServiceAccount)
+
+	users := []string{}
+
+	for _, user := range anyUIDSCC.Users {
+		if user != sccUser {
+			users = append(users, user)
+		}
+	}
+
+	if len(users) == len(anyUIDSCC.Users) {
+		return nil
+	}
+
+	anyUIDSCC.Users = users
+
+	return r.reconcilerBase.GetClient().Update(context.TODO(), anyUIDSCC)
+}
+
+// applyRetentionPolicy prepares a PersistentVolumeClaim owned by the QuayEcosystem for its deletion
+func (r *ReconcileQuayEcosystemConfiguration) applyRetentionPolicy(metaObject metav1.ObjectMeta, persistentVolumeClaimName string, retentionPolicy redhatcopv1alpha1.PersistentVolumeClaimRetentionPolicy) (*reconcile.Result, error) {
+
+	persistentVolumeClaim := &corev1.PersistentVolumeClaim{}
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: persistentVolumeClaimName, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, persistentVolumeClaim)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	switch retentionPolicy {
+	case redhatcopv1alpha1.RetainPersistentVolumeClaimRetentionPolicy:
+
+		// Release the PersistentVolumeClaim from the QuayEcosystem so that it is not garbage collected
+		ownerReferences := []metav1.OwnerReference{}
+
+		for _, ownerReference := range persistentVolumeClaim.OwnerReferences {
+			if ownerReference.UID != r.quayConfiguration.QuayEcosystem.UID {
+				ownerReferences = append(ownerReferences, ownerReference)
+			}
+		}
+
+		if len(ownerReferences) == len(persistentVolumeClaim.OwnerReferences) {
+			return nil, nil
+		}
+
+		persistentVolumeClaim.OwnerReferences = ownerReferences
+
+		return nil, r.reconcilerBase.GetClient().Update(context.TODO(), persistentVolumeClaim)
+
+	case redhatcopv1alpha1.SnapshotPersistentVolumeClaimRetentionPolicy:
+		return r.snapshotPersistentVolumeClaim(metaObject, persistentVolumeClaimName)
+	}
+
+	// The PersistentVolumeClaim is garbage collected along with the QuayEcosystem
+	return nil, nil
+}
+
+// snapshotPersistentVolumeClaim takes a VolumeSnapshot of a PersistentVolumeClaim and waits for it to become ready
+func (r *ReconcileQuayEcosystemConfiguration) snapshotPersistentVolumeClaim(metaObject metav1.ObjectMeta, persistentVolumeClaimName string) (*reconcile.Result, error) {
+
+	metaObject.Name = resources.GetVolumeSnapshotName(r.quayConfiguration.QuayEcosystem, persistentVolumeClaimName)
+
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetAPIVersion(constants.VolumeSnapshotAPIVersion)
+	volumeSnapshot.SetKind(constants.VolumeSnapshotKind)
+
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: metaObject.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, volumeSnapshot)
+
+	if err != nil {
+
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		// The VolumeSnapshot is not owned by the QuayEcosystem so that it outlives it
+		err = r.reconcilerBase.CreateResourceIfNotExists(nil, r.quayConfiguration.QuayEcosystem.Namespace, resources.GetVolumeSnapshotDefinition(metaObject, persistentVolumeClaimName))
+
+		if err != nil {
+			return nil, err
+		}
+
+		logging.Log.Info("Created VolumeSnapshot", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", metaObject.Name, "PersistentVolumeClaim", persistentVolumeClaimName)
+
+		return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+	}
+
+	errorMessage, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "error", "message")
+
+	if found && !utils.IsZeroOfUnderlyingType(errorMessage) {
+		return nil, fmt.Errorf("Failed to snapshot PersistentVolumeClaim %s: %s", persistentVolumeClaimName, errorMessage)
+	}
+
+	readyToUse, _, _ := unstructured.NestedBool(volumeSnapshot.Object, "status", "readyToUse")
+
+	if !readyToUse {
+		logging.Log.Info("Waiting for VolumeSnapshot to become ready", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", metaObject.Name)
+		return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+	}
+
+	return nil, nil
+}
+
+func getSCCUser(namespace string, serviceAccountName string) string {
+	return "system:serviceaccount:" + namespace + ":" + serviceAccountName
+}
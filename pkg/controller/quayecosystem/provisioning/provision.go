@@ -1,14 +1,21 @@
 package provisioning
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"net"
+	"reflect"
 	"strings"
 	"time"
 
 	routev1 "github.com/openshift/api/route/v1"
 	ossecurityv1 "github.com/openshift/api/security/v1"
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
@@ -18,18 +25,30 @@ import (
 	"github.com/theodor2311/quay-operator/pkg/k8sutils"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
 
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/redhat-cop/operator-utils/pkg/util"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/cert"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
 )
 
+// clairComponentPaths maps the Clair v4 components that are exposed separately in split mode to the path they serve
+var clairComponentPaths = map[string]string{
+	constants.LabelComponentClairIndexerValue:  "/indexer",
+	constants.LabelComponentClairNotifierValue: "/notifier",
+}
+
 // ReconcileQuayEcosystemConfiguration defines values required for Quay configuration
 type ReconcileQuayEcosystemConfiguration struct {
 	reconcilerBase    util.ReconcilerBase
@@ -54,16 +73,19 @@ func (r *ReconcileQuayEcosystemConfiguration) CoreResourceDeployment(metaObject
 		return nil, err
 	}
 
-	if err := r.createClairConfigSecret(metaObject); err != nil {
-		return nil, err
-	}
+	if resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem) {
 
-	if err := r.createSecurityScannerKeySecret(metaObject); err != nil {
-		return nil, err
-	}
+		if err := r.createClairConfigSecret(metaObject); err != nil {
+			return nil, err
+		}
 
-	if err := r.createClairTrustCASecret(metaObject); err != nil {
-		return nil, err
+		if err := r.createSecurityScannerKeySecret(metaObject); err != nil {
+			return nil, err
+		}
+
+		if err := r.createClairTrustCASecret(metaObject); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := r.createRBAC(metaObject); err != nil {
@@ -76,9 +98,12 @@ func (r *ReconcileQuayEcosystemConfiguration) CoreResourceDeployment(metaObject
 		return nil, err
 	}
 
-	if err := r.configureAnyUIDSCCs(metaObject); err != nil {
-		logging.Log.Error(err, "Failed to configure SCCs")
-		return nil, err
+	// SCCs are specific to OpenShift
+	if r.quayConfiguration.IsOpenShift {
+		if err := r.configureAnyUIDSCCs(metaObject); err != nil {
+			logging.Log.Error(err, "Failed to configure SCCs")
+			return nil, err
+		}
 	}
 
 	// Redis
@@ -119,8 +144,25 @@ func (r *ReconcileQuayEcosystemConfiguration) CoreResourceDeployment(metaObject
 
 	}
 
+	// Clair Database
+	if resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem) && resources.IsClairDatabaseDedicated(r.quayConfiguration.QuayEcosystem) && utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Clair.Database.Server) {
+
+		createDatabaseResult, err := r.createClairDatabase(metaObject)
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to create Clair database")
+			return nil, err
+		}
+
+		if createDatabaseResult != nil {
+			return createDatabaseResult, nil
+		}
+	}
+
 	// Quay Resources
-	if err := r.createQuayService(metaObject); err != nil {
+	quayService, err := r.createQuayService(metaObject)
+
+	if err != nil {
 		logging.Log.Error(err, "Failed to create Quay service")
 		return nil, err
 	}
@@ -130,33 +172,80 @@ func (r *ReconcileQuayEcosystemConfiguration) CoreResourceDeployment(metaObject
 		return nil, err
 	}
 
-	if err := r.createClairService(metaObject); err != nil {
-		logging.Log.Error(err, "Failed to create Clair service")
-		return nil, err
+	if resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem) {
+		if err := r.createClairService(metaObject); err != nil {
+			logging.Log.Error(err, "Failed to create Clair service")
+			return nil, err
+		}
 	}
 
-	if err := r.createQuayRoute(metaObject); err != nil {
-		logging.Log.Error(err, "Failed to create Quay route")
-		return nil, err
+	// Quay is reached directly through its service when it is exposed through a load balancer
+	quayLoadBalancer := resources.IsLoadBalancerServiceExposure(r.quayConfiguration.QuayEcosystem.Spec.Quay.ServiceExposure)
+
+	if resources.GetExternalAccessType(r.quayConfiguration) == redhatcopv1alpha1.IngressExternalAccessType {
+
+		if !quayLoadBalancer {
+			if err := r.createQuayIngress(metaObject); err != nil {
+				logging.Log.Error(err, "Failed to create Quay ingress")
+				return nil, err
+			}
+		}
+
+		// The config app is only reachable from within the cluster unless a host is provided for it
+		if !utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Quay.ConfigRouteHost) {
+			if err := r.createQuayConfigIngress(metaObject); err != nil {
+				logging.Log.Error(err, "Failed to create Quay Config ingress")
+				return nil, err
+			}
+		}
+
+		// Quay reaches Clair through its service as Clair is not exposed through an Ingress
+		if resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem) {
+			r.quayConfiguration.ClairHostname = fmt.Sprintf("%s:6060", resources.GetClairResourcesName(r.quayConfiguration.QuayEcosystem))
+		}
+
+	} else {
+
+		if !quayLoadBalancer {
+			if err := r.createQuayRoute(metaObject); err != nil {
+				logging.Log.Error(err, "Failed to create Quay route")
+				return nil, err
+			}
+		}
+
+		if err := r.createQuayConfigRoute(metaObject); err != nil {
+			logging.Log.Error(err, "Failed to create Quay Config route")
+			return nil, err
+		}
+
+		if resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem) {
+			if err := r.createClairRoute(metaObject); err != nil {
+				logging.Log.Error(err, "Failed to create Clair route")
+				return nil, err
+			}
+		}
 	}
 
-	if err := r.createQuayConfigRoute(metaObject); err != nil {
-		logging.Log.Error(err, "Failed to create Quay Config route")
-		return nil, err
+	if quayLoadBalancer {
+		if err := r.removeQuayExternalAccess(metaObject); err != nil {
+			logging.Log.Error(err, "Failed to remove Quay external access")
+			return nil, err
+		}
 	}
 
-	if err := r.createClairRoute(metaObject); err != nil {
-		logging.Log.Error(err, "Failed to create Clair route")
+	registryStorageResult, err := r.quayRegistryStorage(metaObject)
+
+	if err != nil {
+		logging.Log.Error(err, "Failed to create registry storage")
 		return nil, err
 	}
 
-	if !utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage) {
-
-		if err := r.quayRegistryStorage(metaObject); err != nil {
-			logging.Log.Error(err, "Failed to create registry storage")
-			return nil, err
-		}
+	if registryStorageResult != nil {
+		return registryStorageResult, nil
+	}
 
+	if quayLoadBalancer {
+		return r.quayLoadBalancerHostname(quayService), nil
 	}
 
 	return nil, nil
@@ -165,17 +254,52 @@ func (r *ReconcileQuayEcosystemConfiguration) CoreResourceDeployment(metaObject
 // DeployClair takes care of the deployment
 func (r *ReconcileQuayEcosystemConfiguration) DeployClair(metaObject metav1.ObjectMeta) (*reconcile.Result, error) {
 
-	if err := r.clairDeployment(metaObject); err != nil {
-		logging.Log.Error(err, "Failed to create Clair deployment")
+	if err := r.removeUnusedClairComponents(metaObject); err != nil {
+		logging.Log.Error(err, "Failed to remove unused Clair components")
 		return nil, err
 	}
 
+	clairReplicas := utils.CheckValue(r.quayConfiguration.QuayEcosystem.Spec.Clair.Replicas, &constants.ClairReplicas).(*int32)
+
+	if r.quayConfiguration.QuayEcosystem.Spec.Clair.Autoscaling != nil {
+		clairReplicas = utils.CheckValue(r.quayConfiguration.QuayEcosystem.Spec.Clair.Autoscaling.MinReplicas, &constants.OneInt).(*int32)
+	}
+
+	clairComponents := resources.GetClairComponents(r.quayConfiguration.QuayEcosystem)
+
+	for _, clairComponent := range clairComponents {
+
+		if err := r.clairDeployment(metaObject, clairComponent); err != nil {
+			logging.Log.Error(err, "Failed to create Clair deployment", "Component", clairComponent)
+			return nil, err
+		}
+
+		if err := r.manageHorizontalPodAutoscaler(resources.GetClairHorizontalPodAutoscalerDefinition(metaObject, r.quayConfiguration.QuayEcosystem, clairComponent), r.quayConfiguration.QuayEcosystem.Spec.Clair.Autoscaling != nil); err != nil {
+			logging.Log.Error(err, "Failed to manage Clair HorizontalPodAutoscaler", "Component", clairComponent)
+			return nil, err
+		}
+
+		if err := r.managePodDisruptionBudget(resources.GetClairPodDisruptionBudgetDefinition(metaObject, r.quayConfiguration.QuayEcosystem, clairComponent), *clairReplicas); err != nil {
+			logging.Log.Error(err, "Failed to manage Clair PodDisruptionBudget", "Component", clairComponent)
+			return nil, err
+		}
+	}
+
 	time.Sleep(time.Duration(2) * time.Second)
 
-	// Verify Deployment
-	deploymentName := resources.GetClairResourcesName(r.quayConfiguration.QuayEcosystem)
+	// Verify Deployments
+	for _, clairComponent := range clairComponents {
 
-	return r.verifyDeployment(deploymentName, r.quayConfiguration.QuayEcosystem.ObjectMeta.Namespace)
+		deploymentName := resources.GetClairComponentResourcesName(r.quayConfiguration.QuayEcosystem, clairComponent)
+
+		result, err := r.verifyDeployment(deploymentName, r.quayConfiguration.QuayEcosystem.ObjectMeta.Namespace)
+
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+
+	return nil, nil
 
 }
 
@@ -204,6 +328,27 @@ func (r *ReconcileQuayEcosystemConfiguration) DeployQuay(metaObject metav1.Objec
 		return nil, err
 	}
 
+	if err := r.quayStorageReplicationDeployment(metaObject); err != nil {
+		logging.Log.Error(err, "Failed to create Quay storage replication deployment")
+		return nil, err
+	}
+
+	if err := r.manageHorizontalPodAutoscaler(resources.GetQuayHorizontalPodAutoscalerDefinition(metaObject, r.quayConfiguration.QuayEcosystem), r.quayConfiguration.QuayEcosystem.Spec.Quay.Autoscaling != nil); err != nil {
+		logging.Log.Error(err, "Failed to manage Quay HorizontalPodAutoscaler")
+		return nil, err
+	}
+
+	quayReplicas := utils.CheckValue(r.quayConfiguration.QuayEcosystem.Spec.Quay.Replicas, &constants.OneInt).(*int32)
+
+	if r.quayConfiguration.QuayEcosystem.Spec.Quay.Autoscaling != nil {
+		quayReplicas = utils.CheckValue(r.quayConfiguration.QuayEcosystem.Spec.Quay.Autoscaling.MinReplicas, &constants.OneInt).(*int32)
+	}
+
+	if err := r.managePodDisruptionBudget(resources.GetQuayPodDisruptionBudgetDefinition(metaObject, r.quayConfiguration.QuayEcosystem), *quayReplicas); err != nil {
+		logging.Log.Error(err, "Failed to manage Quay PodDisruptionBudget")
+		return nil, err
+	}
+
 	if !r.quayConfiguration.QuayEcosystem.Spec.Quay.SkipSetup {
 
 		time.Sleep(time.Duration(2) * time.Second)
@@ -232,19 +377,17 @@ func (r *ReconcileQuayEcosystemConfiguration) RemoveQuayConfigResources(metaObje
 		return nil, err
 	}
 
-	// OpenShift Route
-	route := &routev1.Route{}
-	err = r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: quayName, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, route)
+	// OpenShift Route or Kubernetes Ingress
+	var externalAccess runtime.Object = &routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: quayName, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}}
 
-	if err != nil && !apierrors.IsNotFound(err) {
-		logging.Log.Error(err, "Error Finding Quay Config Route", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", quayName)
-		return nil, err
+	if resources.GetExternalAccessType(r.quayConfiguration) == redhatcopv1alpha1.IngressExternalAccessType {
+		externalAccess = &extensionsv1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: quayName, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}}
 	}
 
-	err = r.reconcilerBase.GetClient().Delete(context.TODO(), route)
+	err = r.reconcilerBase.GetClient().Delete(context.TODO(), externalAccess)
 
 	if err != nil && !apierrors.IsNotFound(err) {
-		logging.Log.Error(err, "Failed to Delete Quay Config Route", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", quayName)
+		logging.Log.Error(err, "Failed to Delete Quay Config external access", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", quayName, "Kind", fmt.Sprintf("%T", externalAccess))
 		return nil, err
 	}
 
@@ -264,34 +407,49 @@ func (r *ReconcileQuayEcosystemConfiguration) createQuayDatabase(meta metav1.Obj
 	meta = resources.UpdateMetaWithName(meta, resources.GetQuayDatabaseName(r.quayConfiguration.QuayEcosystem))
 	resources.BuildQuayDatabaseResourceLabels(meta.Labels)
 
+	return r.createDatabase(meta, r.quayConfiguration.QuayEcosystem.Spec.Quay.Database, r.quayConfiguration.ValidProvidedQuayDatabaseSecret, constants.DefaultQuayDatabaseCredentials, &r.quayConfiguration.QuayDatabase)
+}
+
+func (r *ReconcileQuayEcosystemConfiguration) createClairDatabase(meta metav1.ObjectMeta) (*reconcile.Result, error) {
+
+	// Update Metadata
+	meta = resources.UpdateMetaWithName(meta, resources.GetClairDatabaseName(r.quayConfiguration.QuayEcosystem))
+	resources.BuildClairDatabaseResourceLabels(meta.Labels)
+
+	return r.createDatabase(meta, r.quayConfiguration.QuayEcosystem.Spec.Clair.Database, r.quayConfiguration.ValidProvidedClairDatabaseSecret, constants.DefaultClairDatabaseCredentials, &r.quayConfiguration.ClairDatabase)
+}
+
+// createDatabase provisions the credentials, storage, service and deployment of a PostgreSQL database named after the metadata
+func (r *ReconcileQuayEcosystemConfiguration) createDatabase(meta metav1.ObjectMeta, database redhatcopv1alpha1.Database, validProvidedSecret bool, defaultCredentials map[string]string, databaseConfig *resources.DatabaseConfig) (*reconcile.Result, error) {
+
 	var databaseResources []metav1.Object
 
-	if !r.quayConfiguration.ValidProvidedQuayDatabaseSecret {
-		quayDatabaseSecret := resources.GetSecretDefinitionFromCredentialsMap(resources.GetQuayDatabaseName(r.quayConfiguration.QuayEcosystem), meta, constants.DefaultQuayDatabaseCredentials)
-		databaseResources = append(databaseResources, quayDatabaseSecret)
+	if !validProvidedSecret {
+		databaseSecret := resources.GetSecretDefinitionFromCredentialsMap(meta.Name, meta, defaultCredentials)
+		databaseResources = append(databaseResources, databaseSecret)
 
-		r.quayConfiguration.QuayDatabase.Username = constants.DefaultQuayDatabaseCredentials[constants.DatabaseCredentialsUsernameKey]
-		r.quayConfiguration.QuayDatabase.Password = constants.DefaultQuayDatabaseCredentials[constants.DatabaseCredentialsPasswordKey]
-		r.quayConfiguration.QuayDatabase.Database = constants.DefaultQuayDatabaseCredentials[constants.DatabaseCredentialsDatabaseKey]
+		databaseConfig.Username = defaultCredentials[constants.DatabaseCredentialsUsernameKey]
+		databaseConfig.Password = defaultCredentials[constants.DatabaseCredentialsPasswordKey]
+		databaseConfig.Database = defaultCredentials[constants.DatabaseCredentialsDatabaseKey]
 
 	}
 
 	// Create PVC
-	if !utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Quay.Database.VolumeSize) {
-		databasePvc := resources.GetDatabasePVCDefinition(meta, r.quayConfiguration.QuayEcosystem.Spec.Quay.Database.VolumeSize)
+	if !utils.IsZeroOfUnderlyingType(database.VolumeSize) {
+		databasePvc := resources.GetDatabasePVCDefinition(meta, database.VolumeSize)
 		databaseResources = append(databaseResources, databasePvc)
 	}
 
 	service := resources.GetDatabaseServiceResourceDefinition(meta, constants.PostgreSQLPort)
 	databaseResources = append(databaseResources, service)
 
-	deployment := resources.GetDatabaseDeploymentDefinition(meta, r.quayConfiguration)
+	deployment := resources.GetDatabaseDeploymentDefinition(meta, database)
 	databaseResources = append(databaseResources, deployment)
 
 	for _, resource := range databaseResources {
 		err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, resource)
 		if err != nil {
-			logging.Log.Error(err, "Error applying Quay database Resource")
+			logging.Log.Error(err, "Error applying database Resource", "Name", meta.Name)
 			return nil, err
 		}
 	}
@@ -344,8 +502,11 @@ func (r *ReconcileQuayEcosystemConfiguration) configurePostgreSQL(meta metav1.Ob
 		return fmt.Errorf("Failed to add pg_trim extension: %s", stderr)
 	}
 
-	//Create Clair Database
-	//TODO Convert Clair Database to Parameter
+	// Clair uses a database within the Quay PostgreSQL instance unless a dedicated database is specified
+	if !resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem) || resources.IsClairDatabaseDedicated(r.quayConfiguration.QuayEcosystem) {
+		return nil
+	}
+
 	success, stdout, stderr = k8sutils.ExecIntoPod(r.k8sclient, podName, fmt.Sprintf("echo \"create database clair\" | /opt/rh/rh-postgresql96/root/usr/bin/psql -d %s", r.quayConfiguration.QuayDatabase.Database), "", r.quayConfiguration.QuayEcosystem.Namespace)
 
 	if !success {
@@ -508,16 +669,11 @@ func (r *ReconcileQuayEcosystemConfiguration) createRBAC(meta metav1.ObjectMeta)
 
 }
 
-func (r *ReconcileQuayEcosystemConfiguration) createQuayService(meta metav1.ObjectMeta) error {
+func (r *ReconcileQuayEcosystemConfiguration) createQuayService(meta metav1.ObjectMeta) (*corev1.Service, error) {
 
 	service := resources.GetQuayServiceDefinition(meta, r.quayConfiguration.QuayEcosystem)
 
-	err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, service)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return r.createOrUpdateService(service)
 
 }
 
@@ -525,22 +681,88 @@ func (r *ReconcileQuayEcosystemConfiguration) createQuayConfigService(meta metav
 
 	service := resources.GetQuayConfigServiceDefinition(meta, r.quayConfiguration.QuayEcosystem)
 
-	err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, service)
+	_, err := r.createOrUpdateService(service)
+
+	return err
+
+}
+
+// createOrUpdateService applies the exposure of a Service and returns the Service as found in the cluster. The values
+// allocated by the cluster are carried over as they cannot be changed or would otherwise be reallocated
+func (r *ReconcileQuayEcosystemConfiguration) createOrUpdateService(service *corev1.Service) (*corev1.Service, error) {
+
+	existingService := &corev1.Service{}
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: service.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, existingService)
+
 	if err != nil {
-		return err
+
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		return service, r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, service)
 	}
 
-	return nil
+	service.Spec.ClusterIP = existingService.Spec.ClusterIP
+
+	if service.Spec.Type != corev1.ServiceTypeClusterIP && existingService.Spec.Type != corev1.ServiceTypeClusterIP {
+
+		for i := range service.Spec.Ports {
+			if service.Spec.Ports[i].NodePort == 0 && i < len(existingService.Spec.Ports) {
+				service.Spec.Ports[i].NodePort = existingService.Spec.Ports[i].NodePort
+			}
+		}
+
+		if service.Spec.Type == existingService.Spec.Type && service.Spec.ExternalTrafficPolicy == existingService.Spec.ExternalTrafficPolicy {
+			service.Spec.HealthCheckNodePort = existingService.Spec.HealthCheckNodePort
+		}
+	}
+
+	err = r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, service)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The status of the load balancer is only reported by the Service found in the cluster
+	service.Status = existingService.Status
+
+	return service, nil
+}
+
+// quayLoadBalancerHostname serves Quay on its Route Host or, when none is provided, on the address of the load balancer of
+// its service. Quay cannot be configured until the address has been assigned in the latter case
+func (r *ReconcileQuayEcosystemConfiguration) quayLoadBalancerHostname(quayService *corev1.Service) *reconcile.Result {
+
+	r.quayConfiguration.QuayHostname = r.quayConfiguration.QuayEcosystem.Spec.Quay.RouteHost
+
+	if utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayHostname) {
 
+		loadBalancerAddress := resources.GetLoadBalancerAddress(quayService)
+
+		if utils.IsZeroOfUnderlyingType(loadBalancerAddress) {
+			logging.Log.Info("Waiting for load balancer address", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", quayService.Name)
+			return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}
+		}
+
+		r.quayConfiguration.QuayHostname = loadBalancerAddress
+	}
+
+	r.quayConfiguration.QuayEcosystem.Status.Hostname = r.quayConfiguration.QuayHostname
+
+	return nil
 }
 
 func (r *ReconcileQuayEcosystemConfiguration) createClairService(meta metav1.ObjectMeta) error {
 
-	service := resources.GetClairServiceDefinition(meta, r.quayConfiguration.QuayEcosystem)
+	for _, clairComponent := range resources.GetClairComponents(r.quayConfiguration.QuayEcosystem) {
 
-	err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, service)
-	if err != nil {
-		return err
+		service := resources.GetClairServiceDefinition(meta, r.quayConfiguration.QuayEcosystem, clairComponent)
+
+		err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, service)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -617,13 +839,58 @@ func (r *ReconcileQuayEcosystemConfiguration) createClairRoute(meta metav1.Objec
 
 	r.quayConfiguration.ClairHostname = createdRoute.Spec.Host
 
+	// The indexer and notifier of a split Clair v4 deployment are exposed under their own paths of the Clair route host
+	if len(resources.GetClairComponents(r.quayConfiguration.QuayEcosystem)) > 1 {
+
+		for clairComponent, path := range clairComponentPaths {
+
+			componentRoute := resources.GetClairComponentRouteDefinition(meta, r.quayConfiguration.QuayEcosystem, clairComponent, r.quayConfiguration.ClairHostname, path)
+
+			if err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, componentRoute); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+
+}
+
+func (r *ReconcileQuayEcosystemConfiguration) createQuayIngress(meta metav1.ObjectMeta) error {
+
+	ingress := resources.GetQuayIngressDefinition(meta, r.quayConfiguration.QuayEcosystem)
+
+	err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, ingress)
+
+	if err != nil {
+		return err
+	}
+
+	// Ingresses are not assigned a host, so Quay is always served on its Route Host
+	r.quayConfiguration.QuayHostname = r.quayConfiguration.QuayEcosystem.Spec.Quay.RouteHost
+	r.quayConfiguration.QuayEcosystem.Status.Hostname = r.quayConfiguration.QuayHostname
+
+	return nil
+
+}
+
+func (r *ReconcileQuayEcosystemConfiguration) createQuayConfigIngress(meta metav1.ObjectMeta) error {
+
+	ingress := resources.GetQuayConfigIngressDefinition(meta, r.quayConfiguration.QuayEcosystem)
+
+	err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, ingress)
+
+	if err != nil {
+		return err
+	}
+
 	return nil
 
 }
 
 func (r *ReconcileQuayEcosystemConfiguration) configureAnyUIDSCC(serviceAccountName string, meta metav1.ObjectMeta) error {
 
-	sccUser := "system:serviceaccount:" + meta.Namespace + ":" + serviceAccountName
+	sccUser := getSCCUser(meta.Namespace, serviceAccountName)
 
 	anyUIDSCC := &ossecurityv1.SecurityContextConstraints{}
 	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: constants.AnyUIDSCC, Namespace: ""}, anyUIDSCC)
@@ -654,11 +921,11 @@ func (r *ReconcileQuayEcosystemConfiguration) configureAnyUIDSCC(serviceAccountN
 
 }
 
-func (r *ReconcileQuayEcosystemConfiguration) quayRegistryStorage(meta metav1.ObjectMeta) error {
+func (r *ReconcileQuayEcosystemConfiguration) quayRegistryStorage(meta metav1.ObjectMeta) (*reconcile.Result, error) {
 
 	for _, registryBackend := range r.quayConfiguration.QuayEcosystem.Spec.Quay.RegistryBackends {
 
-		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Local) {
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Local) && !utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage) {
 			registryVolumeName := resources.GetRegistryStorageVolumeName(r.quayConfiguration.QuayEcosystem, registryBackend.Name)
 
 			meta.Name = registryVolumeName
@@ -668,86 +935,320 @@ func (r *ReconcileQuayEcosystemConfiguration) quayRegistryStorage(meta metav1.Ob
 			err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, registryStoragePVC)
 
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 		}
 
-	}
-
-	return nil
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.ObjectBucketClaim) {
 
-}
+			objectBucketClaimResult, err := r.quayObjectBucketClaim(meta, registryBackend)
 
-func (r *ReconcileQuayEcosystemConfiguration) removeQuayRegistryStorage(meta metav1.ObjectMeta) error {
+			if err != nil {
+				return nil, err
+			}
 
-	registryPVC := resources.GetQuayRegistryStorageName(r.quayConfiguration.QuayEcosystem)
+			if objectBucketClaimResult != nil {
+				return objectBucketClaimResult, nil
+			}
 
-	err := r.k8sclient.CoreV1().PersistentVolumeClaims(r.quayConfiguration.QuayEcosystem.Namespace).Delete(registryPVC, &metav1.DeleteOptions{})
+		}
 
-	if err != nil && !apierrors.IsNotFound(err) {
-		logging.Log.Error(err, "Error Deleting Quay Registry PVC", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", registryPVC)
-		return err
 	}
 
-	return nil
+	return nil, nil
 
 }
 
-func (r *ReconcileQuayEcosystemConfiguration) ManageQuayEcosystemCertificates(meta metav1.ObjectMeta) (*reconcile.Result, error) {
+// quayObjectBucketClaim creates an ObjectBucketClaim for a registry backend and, once bound, collects the bucket details from the generated ConfigMap and Secret
+func (r *ReconcileQuayEcosystemConfiguration) quayObjectBucketClaim(meta metav1.ObjectMeta, registryBackend redhatcopv1alpha1.RegistryBackend) (*reconcile.Result, error) {
 
-	configSecretName := resources.GetConfigMapSecretName(r.quayConfiguration.QuayEcosystem)
+	meta.Name = resources.GetRegistryObjectBucketClaimName(r.quayConfiguration.QuayEcosystem, registryBackend)
 
-	meta.Name = configSecretName
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.ObjectBucketClaim.StorageClassName) {
 
-	appConfigSecret := &corev1.Secret{}
-	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: configSecretName, Namespace: r.quayConfiguration.QuayEcosystem.ObjectMeta.Namespace}, appConfigSecret)
+		objectBucketClaim := resources.GetObjectBucketClaimDefinition(meta, registryBackend.RegistryBackendSource.ObjectBucketClaim.StorageClassName)
 
-	if err != nil {
+		err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, objectBucketClaim)
 
-		if apierrors.IsNotFound(err) {
-			// Config Secret Not Found. Requeue object
-			return &reconcile.Result{}, nil
+		if err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 
-	if !isQuayCertificatesConfigured(appConfigSecret) {
-
-		if utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Quay.SslCertificatesSecretName) {
-			certBytes, privKeyBytes, err := cert.GenerateSelfSignedCertKey(constants.QuayEnterprise, []net.IP{}, []string{r.quayConfiguration.QuayHostname})
-			if err != nil {
-				logging.Log.Error(err, "Error creating public/private key")
-				return nil, err
-			}
-
-			r.quayConfiguration.QuaySslCertificate = certBytes
-			r.quayConfiguration.QuaySslPrivateKey = privKeyBytes
+	objectBucketClaim := resources.GetObjectBucketClaimDefinition(meta, "")
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: meta.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, objectBucketClaim)
 
+	if err != nil {
+		// A claim referenced by name may be created after the QuayEcosystem
+		if apierrors.IsNotFound(err) {
+			logging.Log.Info("Waiting for ObjectBucketClaim to be created", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", meta.Name)
+			return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
 		}
-	} else {
-		if utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Quay.SslCertificatesSecretName) {
-			r.quayConfiguration.QuaySslPrivateKey = appConfigSecret.Data[constants.QuayAppConfigSSLPrivateKeySecretKey]
-			r.quayConfiguration.QuaySslCertificate = appConfigSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey]
-		}
-
-	}
-
-	if appConfigSecret.Data == nil {
-		appConfigSecret.Data = map[string][]byte{}
+		return nil, err
 	}
 
-	appConfigSecret.Data[constants.QuayAppConfigSSLPrivateKeySecretKey] = r.quayConfiguration.QuaySslPrivateKey
-	appConfigSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey] = r.quayConfiguration.QuaySslCertificate
-
-	err = r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, appConfigSecret)
+	phase, _, err := unstructured.NestedString(objectBucketClaim.Object, "status", "phase")
 
 	if err != nil {
-		logging.Log.Error(err, "Error Updating app secret with certificates")
 		return nil, err
 	}
 
+	if phase != constants.ObjectBucketClaimBoundPhase {
+		logging.Log.Info("Waiting for ObjectBucketClaim to be bound", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", meta.Name, "Phase", phase)
+		return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+	}
+
+	// The bucket provisioner generates a ConfigMap and Secret with the same name as the claim
+	objectBucketConfigMap := &corev1.ConfigMap{}
+	err = r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: meta.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, objectBucketConfigMap)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+		}
+		return nil, err
+	}
+
+	objectBucketSecret := &corev1.Secret{}
+	err = r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: meta.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, objectBucketSecret)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+		}
+		return nil, err
+	}
+
+	credentials := map[string]string{}
+
+	for _, key := range constants.RequiredObjectBucketClaimConfigMapKeys {
+		if _, found := objectBucketConfigMap.Data[key]; !found {
+			return nil, fmt.Errorf("Failed to locate key %s in ObjectBucketClaim ConfigMap %s", key, meta.Name)
+		}
+		credentials[key] = objectBucketConfigMap.Data[key]
+	}
+
+	for _, key := range constants.RequiredObjectBucketClaimSecretKeys {
+		if _, found := objectBucketSecret.Data[key]; !found {
+			return nil, fmt.Errorf("Failed to locate key %s in ObjectBucketClaim Secret %s", key, meta.Name)
+		}
+		credentials[key] = string(objectBucketSecret.Data[key])
+	}
+
+	if r.quayConfiguration.RegistryBackendCredentials == nil {
+		r.quayConfiguration.RegistryBackendCredentials = map[string]map[string]string{}
+	}
+
+	r.quayConfiguration.RegistryBackendCredentials[registryBackend.Name] = credentials
+
 	return nil, nil
+
+}
+
+func (r *ReconcileQuayEcosystemConfiguration) removeQuayRegistryStorage(meta metav1.ObjectMeta) error {
+
+	registryPVC := resources.GetQuayRegistryStorageName(r.quayConfiguration.QuayEcosystem)
+
+	err := r.k8sclient.CoreV1().PersistentVolumeClaims(r.quayConfiguration.QuayEcosystem.Namespace).Delete(registryPVC, &metav1.DeleteOptions{})
+
+	if err != nil && !apierrors.IsNotFound(err) {
+		logging.Log.Error(err, "Error Deleting Quay Registry PVC", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", registryPVC)
+		return err
+	}
+
+	return nil
+
+}
+
+func (r *ReconcileQuayEcosystemConfiguration) ManageQuayEcosystemCertificates(meta metav1.ObjectMeta) (*reconcile.Result, error) {
+
+	configSecretName := resources.GetConfigMapSecretName(r.quayConfiguration.QuayEcosystem)
+
+	meta.Name = configSecretName
+
+	appConfigSecret := &corev1.Secret{}
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: configSecretName, Namespace: r.quayConfiguration.QuayEcosystem.ObjectMeta.Namespace}, appConfigSecret)
+
+	if err != nil {
+
+		if apierrors.IsNotFound(err) {
+			// Config Secret Not Found. Requeue object
+			return &reconcile.Result{}, nil
+		}
+		return nil, err
+	}
+
+	// Certificates issued by cert-manager are renewed by the issuer and picked up from its secret on every reconcile
+	if r.quayConfiguration.QuayEcosystem.Spec.Quay.CertificateIssuer != nil {
+
+		issuedCertificateResult, err := r.quayIssuedCertificate(meta)
+
+		if err != nil || issuedCertificateResult != nil {
+			return issuedCertificateResult, err
+		}
+	}
+
+	// Certificates are generated by the operator unless provided in a secret or by an issuer
+	selfSigned := utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayEcosystem.Spec.Quay.SslCertificatesSecretName) && r.quayConfiguration.QuayEcosystem.Spec.Quay.CertificateIssuer == nil
+
+	if selfSigned {
+
+		certificateHostname := r.quayConfiguration.QuayHostname
+
+		if host, _, err := net.SplitHostPort(certificateHostname); err == nil {
+			certificateHostname = host
+		}
+
+		// The certificate is replaced when it expires or Quay is no longer served on the hostname it was generated for
+		hostnameChanged := isQuayCertificatesConfigured(appConfigSecret) && !utils.IsZeroOfUnderlyingType(certificateHostname) &&
+			!isCertificateValidForHostname(appConfigSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey], certificateHostname)
+
+		if !isQuayCertificatesConfigured(appConfigSecret) || hostnameChanged || isCertificateRenewalDue(appConfigSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey], time.Now()) {
+
+			// Quay is served on an IP address when exposed through a load balancer without a Route Host
+			certificateIPs := []net.IP{}
+			certificateHostnames := []string{certificateHostname}
+
+			if ip := net.ParseIP(certificateHostname); ip != nil {
+				certificateIPs = append(certificateIPs, ip)
+				certificateHostnames = []string{}
+			}
+
+			certBytes, privKeyBytes, err := cert.GenerateSelfSignedCertKey(constants.QuayEnterprise, certificateIPs, certificateHostnames)
+			if err != nil {
+				logging.Log.Error(err, "Error creating public/private key")
+				return nil, err
+			}
+
+			if hostnameChanged {
+				logging.Log.Info("Regenerating self-signed certificate for changed hostname", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", r.quayConfiguration.QuayEcosystem.Name, "Hostname", certificateHostname)
+			} else if isQuayCertificatesConfigured(appConfigSecret) {
+				logging.Log.Info("Renewing expiring self-signed certificate", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", r.quayConfiguration.QuayEcosystem.Name)
+			}
+
+			r.quayConfiguration.QuaySslCertificate = certBytes
+			r.quayConfiguration.QuaySslPrivateKey = privKeyBytes
+
+		} else {
+			r.quayConfiguration.QuaySslPrivateKey = appConfigSecret.Data[constants.QuayAppConfigSSLPrivateKeySecretKey]
+			r.quayConfiguration.QuaySslCertificate = appConfigSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey]
+		}
+	}
+
+	// Report the expiry of the certificate so that pods are restarted once it is replaced
+	notAfter, err := getCertificateNotAfter(r.quayConfiguration.QuaySslCertificate)
+
+	if err != nil {
+		logging.Log.Error(err, "Error reading certificate expiry", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", r.quayConfiguration.QuayEcosystem.Name)
+	} else {
+		r.quayConfiguration.QuayEcosystem.Status.Certificate = &redhatcopv1alpha1.CertificateStatus{
+			NotAfter:   metav1.NewTime(notAfter),
+			SelfSigned: selfSigned,
+		}
+	}
+
+	if appConfigSecret.Data == nil {
+		appConfigSecret.Data = map[string][]byte{}
+	}
+
+	appConfigSecret.Data[constants.QuayAppConfigSSLPrivateKeySecretKey] = r.quayConfiguration.QuaySslPrivateKey
+	appConfigSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey] = r.quayConfiguration.QuaySslCertificate
+
+	err = r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, appConfigSecret)
+
+	if err != nil {
+		logging.Log.Error(err, "Error Updating app secret with certificates")
+		return nil, err
+	}
+
+	// Ingresses terminate TLS using the Quay certificate in the standard TLS secret format
+	if resources.GetExternalAccessType(r.quayConfiguration) == redhatcopv1alpha1.IngressExternalAccessType {
+
+		err = r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, resources.GetQuayIngressTLSSecretDefinition(meta, r.quayConfiguration))
+
+		if err != nil {
+			logging.Log.Error(err, "Error Updating Ingress TLS secret with certificates")
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// ManageSecurityScannerConfig applies the security scanner settings to the Quay configuration stored by the config app.
+// Quay is only configured through the config app during setup, so enabling or disabling Clair afterwards is applied here
+func (r *ReconcileQuayEcosystemConfiguration) ManageSecurityScannerConfig(meta metav1.ObjectMeta) error {
+	return r.updateQuayConfig(func(quayConfig map[string]interface{}) {
+		resources.SetSecurityScannerConfig(quayConfig, r.quayConfiguration)
+	})
+}
+
+// ManageStorageReplicationConfig applies the storage preference and replication settings to the Quay configuration stored
+// by the config app, so that the replication worker and Quay agree once the registry backends change after setup
+func (r *ReconcileQuayEcosystemConfiguration) ManageStorageReplicationConfig(meta metav1.ObjectMeta) error {
+	return r.updateQuayConfig(func(quayConfig map[string]interface{}) {
+		resources.SetStorageReplicationConfig(quayConfig, r.quayConfiguration.QuayEcosystem)
+	})
+}
+
+// updateQuayConfig applies settings to the Quay configuration stored by the config app and stores it when it changed
+func (r *ReconcileQuayEcosystemConfiguration) updateQuayConfig(setConfig func(map[string]interface{})) error {
+
+	configSecretName := resources.GetConfigMapSecretName(r.quayConfiguration.QuayEcosystem)
+
+	configSecret := &corev1.Secret{}
+
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: configSecretName, Namespace: r.quayConfiguration.QuayEcosystem.ObjectMeta.Namespace}, configSecret)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// The configuration is written by the config app once setup has completed
+	currentQuayConfig, found := configSecret.Data[constants.QuayConfigKey]
+
+	if !found || len(currentQuayConfig) == 0 {
+		return nil
+	}
+
+	quayConfig := map[string]interface{}{}
+
+	if err := yaml.Unmarshal(currentQuayConfig, &quayConfig); err != nil {
+		logging.Log.Error(err, "Error reading current quay config")
+		return err
+	}
+
+	currentQuayConfig, err = yaml.Marshal(quayConfig)
+
+	if err != nil {
+		return err
+	}
+
+	setConfig(quayConfig)
+
+	updatedQuayConfig, err := yaml.Marshal(quayConfig)
+
+	if err != nil {
+		logging.Log.Error(err, "Error generating quay config")
+		return err
+	}
+
+	if bytes.Equal(currentQuayConfig, updatedQuayConfig) {
+		return nil
+	}
+
+	configSecret.Data[constants.QuayConfigKey] = updatedQuayConfig
+
+	if err := r.reconcilerBase.GetClient().Update(context.TODO(), configSecret); err != nil {
+		logging.Log.Error(err, "Error Updating quay config secret")
+		return err
+	}
+
+	return nil
 }
 
 func (r *ReconcileQuayEcosystemConfiguration) ManageClairConfig(meta metav1.ObjectMeta) (*reconcile.Result, error) {
@@ -773,55 +1274,64 @@ func (r *ReconcileQuayEcosystemConfiguration) ManageClairConfig(meta metav1.Obje
 		clairConfigSecret.Data = map[string][]byte{}
 	}
 
-	//TODO update paginationkey
-	clairConfig := fmt.Sprintf(
-		`clair:
-  database:
-    type: pgsql
-    options:
-      source: postgresql://%s:%s@%s:5432/clair?sslmode=disable
-      cachesize: 16384
-  api:
-    healthport: 6061
-    port: 6062
-    timeout: 900s
-    paginationkey: "XxoPtCUzrUv4JV5dS+yQ+MdW7yLEJnRMwigVY/bpgtQ="
-  updater:
-    interval: 6h
-    notifier:
-      attempts: 3
-      renotifyinterval: 1h
-      http:
-        endpoint: https://%s/secscan/notify
-        proxy: http://localhost:6063
-jwtproxy:
-  signer_proxy:
-    enabled: true
-    listen_addr: :6063
-    ca_key_file: /certificates/mitm.key # Generated internally, do not change.
-    ca_crt_file: /certificates/mitm.crt # Generated internally, do not change.
-    signer:
-      issuer: security_scanner
-      expiration_time: 5m
-      max_skew: 1m
-      nonce_length: 32
-      private_key:
-        type: preshared
-        options:
-          key_id: %s
-          private_key_path: /clair/config/security_scanner.pem
-  verifier_proxies:
-  - enabled: true
-    listen_addr: :6060
-    verifier:
-      audience: http://%s
-      upstream: http://localhost:6062
-      key_server:
-        type: keyregistry
-        options:
-          registry: https://%s/keys/`, r.quayConfiguration.QuayDatabase.Username, r.quayConfiguration.QuayDatabase.Password, r.quayConfiguration.QuayDatabase.Server, r.quayConfiguration.QuayHostname, r.quayConfiguration.SecurityScannerKeyKid, r.quayConfiguration.ClairHostname, r.quayConfiguration.QuayHostname)
-
-	clairConfigSecret.Data[constants.ClairConfigKey] = []byte(clairConfig)
+	clairDatabase := r.quayConfiguration.ClairDatabase
+
+	// Clair shares the credentials of the Quay database unless a dedicated database is specified
+	if !resources.IsClairDatabaseDedicated(r.quayConfiguration.QuayEcosystem) {
+		clairDatabase.Username = r.quayConfiguration.QuayDatabase.Username
+		clairDatabase.Password = r.quayConfiguration.QuayDatabase.Password
+		clairDatabase.Database = constants.ClairDatabaseCredentialsDefaultDatabaseName
+	}
+
+	var clairConfig []byte
+
+	if resources.IsClairV4(r.quayConfiguration.QuayEcosystem) {
+
+		r.quayConfiguration.ClairPSK = string(clairConfigSecret.Data[constants.ClairPSKSecretKey])
+
+		clairConfig, err = yaml.Marshal(resources.GetClairV4ConfigFile(r.quayConfiguration, clairDatabase))
+
+	} else {
+
+		// The security scanner key is only known while setup runs. Afterwards, the key referenced by the current configuration is kept
+		if utils.IsZeroOfUnderlyingType(r.quayConfiguration.SecurityScannerKeyKid) {
+
+			currentClairConfig := resources.ClairConfigFile{}
+
+			if err := yaml.Unmarshal(clairConfigSecret.Data[constants.ClairConfigKey], &currentClairConfig); err != nil {
+				logging.Log.Error(err, "Error reading current clair config")
+				return nil, err
+			}
+
+			r.quayConfiguration.SecurityScannerKeyKid = currentClairConfig.JWTProxy.SignerProxy.Signer.PrivateKey.Options.KeyID
+		}
+
+		// The configuration is recreated after Clair has been enabled again
+		if utils.IsZeroOfUnderlyingType(r.quayConfiguration.SecurityScannerKeyKid) && r.quayConfiguration.QuayEcosystem.Status.SecurityScannerKey != nil {
+			r.quayConfiguration.SecurityScannerKeyKid = r.quayConfiguration.QuayEcosystem.Status.SecurityScannerKey.ID
+		}
+
+		if utils.IsZeroOfUnderlyingType(r.quayConfiguration.SecurityScannerKeyKid) {
+			// Security scanner key not available until setup has completed
+			return nil, nil
+		}
+
+		r.quayConfiguration.ClairPaginationKey, err = r.manageClairPaginationKey(clairConfigSecret)
+
+		if err != nil {
+			logging.Log.Error(err, "Error managing clair pagination key")
+			return nil, err
+		}
+
+		clairConfig, err = yaml.Marshal(resources.GetClairConfigFile(r.quayConfiguration, clairDatabase))
+	}
+
+	if err != nil {
+		logging.Log.Error(err, "Error generating clair config")
+		return nil, err
+	}
+
+	clairConfigSecret.Data[constants.ClairConfigKey] = clairConfig
 
 	err = r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, clairConfigSecret)
 
@@ -832,6 +1342,178 @@ jwtproxy:
 	return nil, nil
 }
 
+// manageClairPaginationKey returns the key Clair uses to encrypt pagination tokens. A random key is generated when the Clair config secret does not contain one or when the rotation annotation on the QuayEcosystem changes
+func (r *ReconcileQuayEcosystemConfiguration) manageClairPaginationKey(clairConfigSecret *corev1.Secret) (string, error) {
+
+	rotation := r.quayConfiguration.QuayEcosystem.ObjectMeta.Annotations[constants.ClairPaginationKeyRotationAnnotation]
+	paginationKey := string(clairConfigSecret.Data[constants.ClairPaginationKeySecretKey])
+
+	if !utils.IsZeroOfUnderlyingType(paginationKey) && clairConfigSecret.ObjectMeta.Annotations[constants.ClairPaginationKeyRotationAnnotation] == rotation {
+		return paginationKey, nil
+	}
+
+	paginationKey, err := generateRandomKey(constants.ClairPaginationKeyLength)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate Clair pagination key: %s", err.Error())
+	}
+
+	clairConfigSecret.Data[constants.ClairPaginationKeySecretKey] = []byte(paginationKey)
+
+	if clairConfigSecret.ObjectMeta.Annotations == nil {
+		clairConfigSecret.ObjectMeta.Annotations = map[string]string{}
+	}
+
+	clairConfigSecret.ObjectMeta.Annotations[constants.ClairPaginationKeyRotationAnnotation] = rotation
+
+	return paginationKey, nil
+}
+
+// ManageClairPSK stores the pre-shared key used by Quay to authenticate against Clair v4 in the Clair config secret
+func (r *ReconcileQuayEcosystemConfiguration) ManageClairPSK(meta metav1.ObjectMeta) (*reconcile.Result, error) {
+
+	clairConfigSecretName := resources.GetClairConfigSecretName(r.quayConfiguration.QuayEcosystem)
+
+	clairConfigSecret := &corev1.Secret{}
+
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: clairConfigSecretName, Namespace: r.quayConfiguration.QuayEcosystem.ObjectMeta.Namespace}, clairConfigSecret)
+
+	if err != nil {
+
+		if apierrors.IsNotFound(err) {
+			// Config Secret Not Found. Requeue object
+			return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+		}
+		return nil, err
+	}
+
+	if psk, found := clairConfigSecret.Data[constants.ClairPSKSecretKey]; found && len(psk) > 0 {
+		r.quayConfiguration.ClairPSK = string(psk)
+		return nil, nil
+	}
+
+	psk, err := generateRandomKey(constants.ClairPSKLength)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate Clair pre-shared key: %s", err.Error())
+	}
+
+	if clairConfigSecret.Data == nil {
+		clairConfigSecret.Data = map[string][]byte{}
+	}
+
+	clairConfigSecret.Data[constants.ClairPSKSecretKey] = []byte(psk)
+
+	err = r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, clairConfigSecret)
+
+	if err != nil {
+		logging.Log.Error(err, "Error Updating clair config secret")
+		return nil, err
+	}
+
+	r.quayConfiguration.ClairPSK = psk
+
+	return nil, nil
+}
+
+// generateRandomKey returns a base64 encoded key made of length random bytes
+func generateRandomKey(length int) (string, error) {
+
+	key := make([]byte, length)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ManageExtraCACerts merges the cluster trusted CA bundle into the extra CA certificates when requested and stores the extra CA certificates trusted by Quay
+func (r *ReconcileQuayEcosystemConfiguration) ManageExtraCACerts(meta metav1.ObjectMeta) (*reconcile.Result, error) {
+
+	injectQuayTrustedCABundle := r.quayConfiguration.QuayEcosystem.Spec.Quay.InjectTrustedCABundle
+	injectClairTrustedCABundle := r.quayConfiguration.QuayEcosystem.Spec.Clair.InjectTrustedCABundle && resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem)
+
+	if injectQuayTrustedCABundle || injectClairTrustedCABundle {
+
+		trustedCABundleResult, trustedCABundle, err := r.trustedCABundle(meta)
+
+		if err != nil || trustedCABundleResult != nil {
+			return trustedCABundleResult, err
+		}
+
+		if injectQuayTrustedCABundle {
+			r.quayConfiguration.QuayExtraCACerts = mergeCertificate(r.quayConfiguration.QuayExtraCACerts, constants.TrustedCABundleKey, trustedCABundle)
+		}
+
+		if injectClairTrustedCABundle {
+			r.quayConfiguration.ClairExtraCACerts = mergeCertificate(r.quayConfiguration.ClairExtraCACerts, constants.TrustedCABundleKey, trustedCABundle)
+		}
+	}
+
+	if len(r.quayConfiguration.QuayExtraCACerts) == 0 {
+		return nil, nil
+	}
+
+	meta.Name = resources.GetQuayExtraCertsSecretName(r.quayConfiguration.QuayEcosystem)
+
+	extraCACertsSecret := resources.GetSecretDefinition(meta)
+	extraCACertsSecret.Data = r.quayConfiguration.QuayExtraCACerts
+
+	err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, extraCACertsSecret)
+
+	if err != nil {
+		logging.Log.Error(err, "Error Updating Quay extra CA certificates secret")
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// trustedCABundle creates the ConfigMap into which OpenShift injects the cluster trusted CA bundle and returns the bundle once injected
+func (r *ReconcileQuayEcosystemConfiguration) trustedCABundle(meta metav1.ObjectMeta) (*reconcile.Result, []byte, error) {
+
+	meta.Name = resources.GetTrustedCABundleConfigMapName(r.quayConfiguration.QuayEcosystem)
+
+	// The injected data is owned by OpenShift and must not be overwritten
+	err := r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, resources.GetTrustedCABundleConfigMapDefinition(meta))
+
+	if err != nil {
+		logging.Log.Error(err, "Error creating trusted CA bundle ConfigMap")
+		return nil, nil, err
+	}
+
+	trustedCABundleConfigMap := &corev1.ConfigMap{}
+	err = r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: meta.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, trustedCABundleConfigMap)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trustedCABundle, found := trustedCABundleConfigMap.Data[constants.TrustedCABundleKey]
+
+	if !found || utils.IsZeroOfUnderlyingType(trustedCABundle) {
+		logging.Log.Info("Waiting for trusted CA bundle to be injected", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", meta.Name)
+		return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil, nil
+	}
+
+	return nil, []byte(trustedCABundle), nil
+}
+
+// mergeCertificate returns a copy of a set of certificates that also contains the provided certificate
+func mergeCertificate(certificates map[string][]byte, fileName string, certificate []byte) map[string][]byte {
+
+	mergedCertificates := map[string][]byte{}
+
+	for existingFileName, existingCertificate := range certificates {
+		mergedCertificates[existingFileName] = existingCertificate
+	}
+
+	mergedCertificates[fileName] = certificate
+
+	return mergedCertificates
+}
+
 func (r *ReconcileQuayEcosystemConfiguration) ManageClairTrustCA(meta metav1.ObjectMeta) (*reconcile.Result, error) {
 
 	trustCASecretName := resources.GetClairTrustCASecretName(r.quayConfiguration.QuayEcosystem)
@@ -851,11 +1533,14 @@ func (r *ReconcileQuayEcosystemConfiguration) ManageClairTrustCA(meta metav1.Obj
 		return nil, err
 	}
 
-	if trustCASecret.Data == nil {
-		trustCASecret.Data = map[string][]byte{}
+	// Extra CA certificates are replaced as a whole so that removed certificates are no longer trusted
+	trustCASecret.Data = map[string][]byte{
+		constants.ClairTrustCASecretKey: r.quayConfiguration.QuaySslCertificate,
 	}
 
-	trustCASecret.Data[constants.ClairTrustCASecretKey] = r.quayConfiguration.QuaySslCertificate
+	for fileName, certificate := range r.quayConfiguration.ClairExtraCACerts {
+		trustCASecret.Data[constants.ClairTrustCAExtraCertPrefix+fileName] = certificate
+	}
 
 	err = r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, trustCASecret)
 
@@ -904,6 +1589,10 @@ func (r *ReconcileQuayEcosystemConfiguration) quayDeployment(meta metav1.ObjectM
 
 	quayDeployment := resources.GetQuayDeploymentDefinition(meta, r.quayConfiguration)
 
+	if err := r.retainAutoscaledReplicas(quayDeployment); err != nil {
+		return err
+	}
+
 	err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, quayDeployment)
 
 	if err != nil {
@@ -914,6 +1603,34 @@ func (r *ReconcileQuayEcosystemConfiguration) quayDeployment(meta metav1.ObjectM
 
 }
 
+func (r *ReconcileQuayEcosystemConfiguration) quayStorageReplicationDeployment(meta metav1.ObjectMeta) error {
+
+	if !resources.IsStorageReplicationEnabled(r.quayConfiguration.QuayEcosystem) {
+
+		storageReplicationName := resources.GetQuayStorageReplicationResourcesName(r.quayConfiguration.QuayEcosystem)
+
+		err := r.k8sclient.AppsV1().Deployments(r.quayConfiguration.QuayEcosystem.Namespace).Delete(storageReplicationName, &metav1.DeleteOptions{})
+
+		if err != nil && !apierrors.IsNotFound(err) {
+			logging.Log.Error(err, "Error Deleting Quay Storage Replication Deployment", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", storageReplicationName)
+			return err
+		}
+
+		return nil
+	}
+
+	storageReplicationDeployment := resources.GetQuayStorageReplicationDeploymentDefinition(meta, r.quayConfiguration)
+
+	err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, storageReplicationDeployment)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (r *ReconcileQuayEcosystemConfiguration) quayConfigDeployment(meta metav1.ObjectMeta) error {
 
 	if !r.quayConfiguration.ValidProvidedQuayConfigPasswordSecret {
@@ -939,9 +1656,13 @@ func (r *ReconcileQuayEcosystemConfiguration) quayConfigDeployment(meta metav1.O
 
 }
 
-func (r *ReconcileQuayEcosystemConfiguration) clairDeployment(meta metav1.ObjectMeta) error {
+func (r *ReconcileQuayEcosystemConfiguration) clairDeployment(meta metav1.ObjectMeta, clairComponent string) error {
+
+	clairDeployment := resources.GetClairDeploymentDefinition(meta, r.quayConfiguration, clairComponent)
 
-	clairDeployment := resources.GetClairDeploymentDefinition(meta, r.quayConfiguration)
+	if err := r.retainAutoscaledReplicas(clairDeployment); err != nil {
+		return err
+	}
 
 	err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, clairDeployment)
 
@@ -953,6 +1674,83 @@ func (r *ReconcileQuayEcosystemConfiguration) clairDeployment(meta metav1.Object
 
 }
 
+// retainAutoscaledReplicas keeps the replicas chosen by the HorizontalPodAutoscaler when the Deployment does not specify them
+func (r *ReconcileQuayEcosystemConfiguration) retainAutoscaledReplicas(deployment *appsv1.Deployment) error {
+
+	if deployment.Spec.Replicas != nil {
+		return nil
+	}
+
+	existingDeployment := &appsv1.Deployment{}
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: deployment.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, existingDeployment)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	deployment.Spec.Replicas = existingDeployment.Spec.Replicas
+
+	return nil
+}
+
+// manageHorizontalPodAutoscaler creates or updates the HorizontalPodAutoscaler of a component when autoscaling is enabled and removes it otherwise
+func (r *ReconcileQuayEcosystemConfiguration) manageHorizontalPodAutoscaler(horizontalPodAutoscaler *autoscalingv2beta1.HorizontalPodAutoscaler, enabled bool) error {
+
+	if enabled {
+		return r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, horizontalPodAutoscaler)
+	}
+
+	err := r.reconcilerBase.GetClient().Delete(context.TODO(), horizontalPodAutoscaler)
+
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// managePodDisruptionBudget creates the PodDisruptionBudget of a component when high availability is enabled and it runs more than one replica, and removes it otherwise
+func (r *ReconcileQuayEcosystemConfiguration) managePodDisruptionBudget(podDisruptionBudget *policyv1beta1.PodDisruptionBudget, replicas int32) error {
+
+	existingPodDisruptionBudget := &policyv1beta1.PodDisruptionBudget{}
+	err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: podDisruptionBudget.Name, Namespace: podDisruptionBudget.Namespace}, existingPodDisruptionBudget)
+
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		existingPodDisruptionBudget = nil
+	}
+
+	if !r.quayConfiguration.QuayEcosystem.Spec.HighAvailability.Enabled || replicas < 2 {
+
+		if existingPodDisruptionBudget == nil {
+			return nil
+		}
+
+		logging.Log.Info("Removing PodDisruptionBudget", "Namespace", podDisruptionBudget.Namespace, "Name", podDisruptionBudget.Name)
+
+		return r.reconcilerBase.GetClient().Delete(context.TODO(), existingPodDisruptionBudget)
+	}
+
+	if existingPodDisruptionBudget != nil {
+
+		if reflect.DeepEqual(existingPodDisruptionBudget.Spec, podDisruptionBudget.Spec) {
+			return nil
+		}
+
+		// The spec of a PodDisruptionBudget cannot be updated in place
+		if err := r.reconcilerBase.GetClient().Delete(context.TODO(), existingPodDisruptionBudget); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return r.reconcilerBase.CreateResourceIfNotExists(r.quayConfiguration.QuayEcosystem, podDisruptionBudget.Namespace, podDisruptionBudget)
+}
+
 func (r *ReconcileQuayEcosystemConfiguration) createRedisService(meta metav1.ObjectMeta) error {
 
 	service := resources.GetRedisServiceDefinition(meta, r.quayConfiguration.QuayEcosystem)
@@ -1006,6 +1804,115 @@ func (r *ReconcileQuayEcosystemConfiguration) verifyDeployment(deploymentName st
 
 }
 
+// quayIssuedCertificate requests a certificate for the Quay hostnames from a cert-manager issuer and, once issued, collects it from the generated Secret
+func (r *ReconcileQuayEcosystemConfiguration) quayIssuedCertificate(meta metav1.ObjectMeta) (*reconcile.Result, error) {
+
+	certificateIssuer := r.quayConfiguration.QuayEcosystem.Spec.Quay.CertificateIssuer
+
+	meta.Name = resources.GetQuayCertificateName(r.quayConfiguration.QuayEcosystem)
+
+	if utils.IsZeroOfUnderlyingType(r.quayConfiguration.QuayHostname) {
+		return nil, fmt.Errorf("Failed to determine the Quay hostname to request a certificate for")
+	}
+
+	dnsNames := []string{r.quayConfiguration.QuayHostname}
+
+	if certificateIssuer.IncludeConfigHostname {
+		dnsNames = append(dnsNames, r.quayConfiguration.QuayEcosystem.Spec.Quay.ConfigRouteHost)
+	}
+
+	// Clair is reached on its service, including the port, unless it is exposed through a Route
+	if certificateIssuer.IncludeClairHostname && resources.IsClairEnabled(r.quayConfiguration.QuayEcosystem) && !utils.IsZeroOfUnderlyingType(r.quayConfiguration.ClairHostname) {
+
+		clairHostname := r.quayConfiguration.ClairHostname
+
+		if host, _, err := net.SplitHostPort(clairHostname); err == nil {
+			clairHostname = host
+		}
+
+		dnsNames = append(dnsNames, clairHostname)
+	}
+
+	certificate := resources.GetCertificateDefinition(meta, *certificateIssuer, dnsNames)
+
+	err := r.reconcilerBase.CreateOrUpdateResource(r.quayConfiguration.QuayEcosystem, r.quayConfiguration.QuayEcosystem.Namespace, certificate)
+
+	if err != nil {
+		logging.Log.Error(err, "Error creating Certificate", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", meta.Name)
+		return nil, err
+	}
+
+	// The issuer stores the key pair in a Secret with the name requested by the Certificate
+	certificateSecret := &corev1.Secret{}
+	err = r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: meta.Name, Namespace: r.quayConfiguration.QuayEcosystem.Namespace}, certificateSecret)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logging.Log.Info("Waiting for Certificate to be issued", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", meta.Name)
+			return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+		}
+		return nil, err
+	}
+
+	if len(certificateSecret.Data[corev1.TLSCertKey]) == 0 || len(certificateSecret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		logging.Log.Info("Waiting for Certificate to be issued", "Namespace", r.quayConfiguration.QuayEcosystem.Namespace, "Name", meta.Name)
+		return &reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+	}
+
+	r.quayConfiguration.QuaySslCertificate = certificateSecret.Data[corev1.TLSCertKey]
+	r.quayConfiguration.QuaySslPrivateKey = certificateSecret.Data[corev1.TLSPrivateKeyKey]
+
+	return nil, nil
+}
+
+// getCertificateNotAfter returns the expiry of the first certificate in a PEM encoded bundle
+func getCertificateNotAfter(certificate []byte) (time.Time, error) {
+
+	parsedCertificate, err := parseCertificate(certificate)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parsedCertificate.NotAfter, nil
+}
+
+// isCertificateValidForHostname returns whether a certificate can be read and its subject alternative names match a hostname
+func isCertificateValidForHostname(certificate []byte, hostname string) bool {
+
+	parsedCertificate, err := parseCertificate(certificate)
+
+	if err != nil {
+		return false
+	}
+
+	return parsedCertificate.VerifyHostname(hostname) == nil
+}
+
+// parseCertificate parses the first PEM encoded certificate
+func parseCertificate(certificate []byte) (*x509.Certificate, error) {
+
+	block, _ := pem.Decode(certificate)
+
+	if block == nil || block.Type != cert.CertificateBlockType {
+		return nil, fmt.Errorf("Failed to locate a PEM encoded certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// isCertificateRenewalDue returns whether a certificate cannot be read or expires within the renewal window
+func isCertificateRenewalDue(certificate []byte, now time.Time) bool {
+
+	notAfter, err := getCertificateNotAfter(certificate)
+
+	if err != nil {
+		return true
+	}
+
+	return !now.Add(constants.QuaySslCertificateRenewBefore).Before(notAfter)
+}
+
 func isQuayCertificatesConfigured(secret *corev1.Secret) bool {
 
 	if !utils.IsZeroOfUnderlyingType(secret) {
@@ -1,10 +1,29 @@
 package provisioning
 
 import (
+	"context"
+	"net"
+	"reflect"
 	"testing"
+	"time"
 
+	ossecurityv1 "github.com/openshift/api/security/v1"
+	"github.com/redhat-cop/operator-utils/pkg/util"
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
 )
 
 func TestAllowedNamespaces(t *testing.T) {
@@ -44,3 +63,1568 @@ func TestAllowedNamespaces(t *testing.T) {
 		}
 	}
 }
+
+func TestIsCertificateRenewalDue(t *testing.T) {
+
+	certificate, _, err := cert.GenerateSelfSignedCertKey(constants.QuayEnterprise, []net.IP{}, []string{})
+
+	if err != nil {
+		t.Fatalf("Failed to generate certificate: %v", err)
+	}
+
+	notAfter, err := getCertificateNotAfter(certificate)
+
+	if err != nil {
+		t.Fatalf("Failed to read certificate expiry: %v", err)
+	}
+
+	cases := []struct {
+		certificate []byte
+		now         time.Time
+		expected    bool
+	}{
+		{
+			certificate: certificate,
+			now:         time.Now(),
+			expected:    false,
+		},
+		{
+			certificate: certificate,
+			now:         notAfter.Add(-constants.QuaySslCertificateRenewBefore).Add(-time.Hour),
+			expected:    false,
+		},
+		{
+			certificate: certificate,
+			now:         notAfter.Add(-constants.QuaySslCertificateRenewBefore),
+			expected:    true,
+		},
+		{
+			certificate: certificate,
+			now:         notAfter.Add(time.Hour),
+			expected:    true,
+		},
+		{
+			certificate: []byte("invalid"),
+			now:         time.Now(),
+			expected:    true,
+		},
+	}
+
+	for i, c := range cases {
+		result := isCertificateRenewalDue(c.certificate, c.now)
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+// fakeObjectBucketProvisioner mimics a bucket provisioner by binding the claim and generating its ConfigMap and Secret
+func fakeObjectBucketProvisioner(t *testing.T, k8sclient client.Client, namespace string, name string) {
+
+	objectBucketClaim := resources.GetObjectBucketClaimDefinition(metav1.ObjectMeta{Name: name, Namespace: namespace}, "")
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, objectBucketClaim); err != nil {
+		t.Fatalf("Failed to locate ObjectBucketClaim: %v", err)
+	}
+
+	unstructured.SetNestedField(objectBucketClaim.Object, constants.ObjectBucketClaimBoundPhase, "status", "phase")
+
+	if err := k8sclient.Update(context.TODO(), objectBucketClaim); err != nil {
+		t.Fatalf("Failed to bind ObjectBucketClaim: %v", err)
+	}
+
+	objectBucketConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string]string{
+			constants.ObjectBucketClaimBucketHostKey: "rook-ceph-rgw-store.rook-ceph",
+			constants.ObjectBucketClaimBucketPortKey: "80",
+			constants.ObjectBucketClaimBucketNameKey: "quay-registry-0d8e6a",
+		},
+	}
+
+	objectBucketSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string][]byte{
+			constants.ObjectBucketClaimAccessKeyIDKey:     []byte("access"),
+			constants.ObjectBucketClaimSecretAccessKeyKey: []byte("secret"),
+		},
+	}
+
+	for _, object := range []runtime.Object{objectBucketConfigMap, objectBucketSecret} {
+		if err := k8sclient.Create(context.TODO(), object); err != nil {
+			t.Fatalf("Failed to create ObjectBucketClaim resources: %v", err)
+		}
+	}
+}
+
+func TestIsCertificateValidForHostname(t *testing.T) {
+
+	certificate, _, err := cert.GenerateSelfSignedCertKey(constants.QuayEnterprise, []net.IP{net.ParseIP("203.0.113.10")}, []string{"quay.example.com"})
+
+	if err != nil {
+		t.Fatalf("Failed to generate certificate: %v", err)
+	}
+
+	cases := []struct {
+		certificate []byte
+		hostname    string
+		expected    bool
+	}{
+		{
+			certificate: certificate,
+			hostname:    "quay.example.com",
+			expected:    true,
+		},
+		{
+			certificate: certificate,
+			hostname:    "203.0.113.10",
+			expected:    true,
+		},
+		{
+			certificate: certificate,
+			hostname:    "registry.example.com",
+			expected:    false,
+		},
+		{
+			certificate: []byte("invalid"),
+			hostname:    "quay.example.com",
+			expected:    false,
+		},
+	}
+
+	for i, c := range cases {
+		result := isCertificateValidForHostname(c.certificate, c.hostname)
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestSelfSignedCertificateHostname(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+	}
+
+	certificate, privateKey, err := cert.GenerateSelfSignedCertKey(constants.QuayEnterprise, []net.IP{}, []string{"quay.example.com"})
+
+	if err != nil {
+		t.Fatalf("Failed to generate certificate: %v", err)
+	}
+
+	cases := []struct {
+		hostname    string
+		regenerated bool
+	}{
+		{
+			hostname:    "quay.example.com",
+			regenerated: false,
+		},
+		{
+			hostname:    "quay.example.com:8443",
+			regenerated: false,
+		},
+		{
+			hostname:    "registry.example.com",
+			regenerated: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		configSecret := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: corev1.SchemeGroupVersion.String(),
+				Kind:       "Secret",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resources.GetConfigMapSecretName(quayEcosystem),
+				Namespace: quayEcosystem.Namespace,
+			},
+			Data: map[string][]byte{
+				constants.QuayAppConfigSSLCertificateSecretKey: certificate,
+				constants.QuayAppConfigSSLPrivateKeySecretKey:  privateKey,
+			},
+		}
+
+		k8sclient := fake.NewFakeClient(configSecret)
+
+		quayConfiguration := &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, QuayHostname: c.hostname}
+		r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, quayConfiguration)
+
+		if _, err := r.ManageQuayEcosystemCertificates(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+			t.Fatalf("Test case %d failed to manage certificates: %v", i, err)
+		}
+
+		if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: configSecret.Name, Namespace: configSecret.Namespace}, configSecret); err != nil {
+			t.Fatalf("Test case %d failed to locate config secret: %v", i, err)
+		}
+
+		storedCertificate := configSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey]
+
+		if regenerated := !reflect.DeepEqual(certificate, storedCertificate); c.regenerated != regenerated {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.regenerated, regenerated)
+		}
+
+		if host, _, err := net.SplitHostPort(c.hostname); err == nil {
+			c.hostname = host
+		}
+
+		if !isCertificateValidForHostname(storedCertificate, c.hostname) {
+			t.Errorf("Test case %d certificate is not valid for hostname %s", i, c.hostname)
+		}
+	}
+}
+
+func TestObjectBucketClaimRegistryStorage(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+			Quay: redhatcopv1alpha1.Quay{
+				RegistryBackends: []redhatcopv1alpha1.RegistryBackend{
+					{
+						Name: "rgw",
+						RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+							ObjectBucketClaim: &redhatcopv1alpha1.ObjectBucketClaimRegistryBackendSource{
+								StorageClassName: "rook-ceph-bucket",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	k8sclient := fake.NewFakeClient()
+
+	quayConfiguration := &resources.QuayConfiguration{QuayEcosystem: quayEcosystem}
+	r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, quayConfiguration)
+	meta := resources.NewResourceObjectMeta(quayEcosystem)
+
+	// Claim is created but not yet bound
+	result, err := r.quayRegistryStorage(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to provision registry storage: %v", err)
+	}
+
+	if result == nil || !result.Requeue {
+		t.Errorf("Expected a requeue while the ObjectBucketClaim is pending\nActual: %#v", result)
+	}
+
+	claimName := resources.GetRegistryObjectBucketClaimName(quayEcosystem, quayEcosystem.Spec.Quay.RegistryBackends[0])
+
+	fakeObjectBucketProvisioner(t, k8sclient, quayEcosystem.Namespace, claimName)
+
+	// Claim is bound and the generated resources are available
+	result, err = r.quayRegistryStorage(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to provision registry storage: %v", err)
+	}
+
+	if result != nil {
+		t.Errorf("Expected provisioning to complete once the ObjectBucketClaim is bound\nActual: %#v", result)
+	}
+
+	expected := map[string]string{
+		constants.ObjectBucketClaimBucketHostKey:      "rook-ceph-rgw-store.rook-ceph",
+		constants.ObjectBucketClaimBucketPortKey:      "80",
+		constants.ObjectBucketClaimBucketNameKey:      "quay-registry-0d8e6a",
+		constants.ObjectBucketClaimAccessKeyIDKey:     "access",
+		constants.ObjectBucketClaimSecretAccessKeyKey: "secret",
+	}
+
+	if actual := quayConfiguration.RegistryBackendCredentials["rgw"]; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Registry backend credentials did not match\nExpected: %#v\nActual: %#v", expected, actual)
+	}
+}
+
+func TestNamedObjectBucketClaimRegistryStorage(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+			Quay: redhatcopv1alpha1.Quay{
+				RegistryBackends: []redhatcopv1alpha1.RegistryBackend{
+					{
+						Name: "rgw",
+						RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+							ObjectBucketClaim: &redhatcopv1alpha1.ObjectBucketClaimRegistryBackendSource{
+								Name: "quay-bucket",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	k8sclient := fake.NewFakeClient()
+
+	quayConfiguration := &resources.QuayConfiguration{QuayEcosystem: quayEcosystem}
+	r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, quayConfiguration)
+	meta := resources.NewResourceObjectMeta(quayEcosystem)
+
+	// Claim has not been created yet
+	result, err := r.quayRegistryStorage(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to provision registry storage: %v", err)
+	}
+
+	if result == nil || !result.Requeue {
+		t.Errorf("Expected a requeue while the ObjectBucketClaim does not exist\nActual: %#v", result)
+	}
+
+	objectBucketClaim := resources.GetObjectBucketClaimDefinition(metav1.ObjectMeta{Name: "quay-bucket", Namespace: quayEcosystem.Namespace}, "rook-ceph-bucket")
+
+	if err := k8sclient.Create(context.TODO(), objectBucketClaim); err != nil {
+		t.Fatalf("Failed to create ObjectBucketClaim: %v", err)
+	}
+
+	fakeObjectBucketProvisioner(t, k8sclient, quayEcosystem.Namespace, "quay-bucket")
+
+	result, err = r.quayRegistryStorage(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to provision registry storage: %v", err)
+	}
+
+	if result != nil {
+		t.Errorf("Expected provisioning to complete once the ObjectBucketClaim is bound\nActual: %#v", result)
+	}
+}
+
+// fakeCertificateIssuer mimics cert-manager by issuing a certificate for the hostnames of a Certificate into the Secret it requests
+func fakeCertificateIssuer(t *testing.T, k8sclient client.Client, namespace string, name string) []byte {
+
+	certificate := resources.GetCertificateDefinition(metav1.ObjectMeta{Name: name, Namespace: namespace}, redhatcopv1alpha1.CertificateIssuer{}, nil)
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, certificate); err != nil {
+		t.Fatalf("Failed to locate Certificate: %v", err)
+	}
+
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+
+	certBytes, privKeyBytes, err := cert.GenerateSelfSignedCertKey(dnsNames[0], []net.IP{}, dnsNames)
+
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+
+	certificateSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certBytes,
+			corev1.TLSPrivateKeyKey: privKeyBytes,
+		},
+	}
+
+	if err := k8sclient.Create(context.TODO(), certificateSecret); err != nil {
+		t.Fatalf("Failed to create Certificate Secret: %v", err)
+	}
+
+	return certBytes
+}
+
+func TestIssuedCertificate(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+			Quay: redhatcopv1alpha1.Quay{
+				CertificateIssuer: &redhatcopv1alpha1.CertificateIssuer{
+					IncludeConfigHostname: true,
+					Kind:                  redhatcopv1alpha1.ClusterIssuerCertificateIssuerKind,
+					Name:                  "letsencrypt",
+				},
+				ConfigRouteHost: "quay-config.apps.example.com",
+			},
+		},
+	}
+
+	appConfigSecret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.GetConfigMapSecretName(quayEcosystem),
+			Namespace: quayEcosystem.Namespace,
+		},
+	}
+
+	k8sclient := fake.NewFakeClient(appConfigSecret)
+
+	quayConfiguration := &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, QuayHostname: "quay.apps.example.com"}
+	r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, quayConfiguration)
+	meta := resources.NewResourceObjectMeta(quayEcosystem)
+
+	// Certificate is requested but not yet issued
+	result, err := r.ManageQuayEcosystemCertificates(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to manage certificates: %v", err)
+	}
+
+	if result == nil || !result.Requeue {
+		t.Errorf("Expected a requeue while the Certificate is pending\nActual: %#v", result)
+	}
+
+	certificateName := resources.GetQuayCertificateName(quayEcosystem)
+	issuedCertificate := fakeCertificateIssuer(t, k8sclient, quayEcosystem.Namespace, certificateName)
+
+	// Certificate is issued and copied into the app config secret
+	result, err = r.ManageQuayEcosystemCertificates(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to manage certificates: %v", err)
+	}
+
+	if result != nil {
+		t.Errorf("Expected certificate management to complete once the Certificate is issued\nActual: %#v", result)
+	}
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: appConfigSecret.Name, Namespace: appConfigSecret.Namespace}, appConfigSecret); err != nil {
+		t.Fatalf("Failed to locate app config secret: %v", err)
+	}
+
+	if !reflect.DeepEqual(issuedCertificate, appConfigSecret.Data[constants.QuayAppConfigSSLCertificateSecretKey]) {
+		t.Errorf("App config secret does not contain the issued certificate")
+	}
+
+	if quayEcosystem.Status.Certificate == nil || quayEcosystem.Status.Certificate.SelfSigned {
+		t.Errorf("Expected the status to report an issued certificate\nActual: %#v", quayEcosystem.Status.Certificate)
+	}
+
+	certificate := resources.GetCertificateDefinition(metav1.ObjectMeta{Name: certificateName, Namespace: quayEcosystem.Namespace}, redhatcopv1alpha1.CertificateIssuer{}, nil)
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: certificateName, Namespace: quayEcosystem.Namespace}, certificate); err != nil {
+		t.Fatalf("Failed to locate Certificate: %v", err)
+	}
+
+	expected := []string{"quay.apps.example.com", "quay-config.apps.example.com"}
+
+	if dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames"); !reflect.DeepEqual(expected, dnsNames) {
+		t.Errorf("Certificate hostnames did not match\nExpected: %#v\nActual: %#v", expected, dnsNames)
+	}
+
+	if kind, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "kind"); kind != string(redhatcopv1alpha1.ClusterIssuerCertificateIssuerKind) {
+		t.Errorf("Certificate issuer kind did not match\nExpected: %#v\nActual: %#v", redhatcopv1alpha1.ClusterIssuerCertificateIssuerKind, kind)
+	}
+}
+
+func TestIssuedCertificateDNSNames(t *testing.T) {
+
+	disabled := false
+
+	cases := []struct {
+		certificateIssuer redhatcopv1alpha1.CertificateIssuer
+		clairEnabled      *bool
+		clairHostname     string
+		expected          []string
+	}{
+		{
+			certificateIssuer: redhatcopv1alpha1.CertificateIssuer{Name: "letsencrypt"},
+			clairHostname:     "quay-clair.apps.example.com",
+			expected:          []string{"quay.apps.example.com"},
+		},
+		{
+			certificateIssuer: redhatcopv1alpha1.CertificateIssuer{Name: "letsencrypt", IncludeClairHostname: true},
+			clairHostname:     "quay-clair.apps.example.com",
+			expected:          []string{"quay.apps.example.com", "quay-clair.apps.example.com"},
+		},
+		{
+			// Clair reached on its service
+			certificateIssuer: redhatcopv1alpha1.CertificateIssuer{Name: "letsencrypt", IncludeClairHostname: true},
+			clairHostname:     "quay-clair:6060",
+			expected:          []string{"quay.apps.example.com", "quay-clair"},
+		},
+		{
+			certificateIssuer: redhatcopv1alpha1.CertificateIssuer{Name: "letsencrypt", IncludeClairHostname: true},
+			clairEnabled:      &disabled,
+			expected:          []string{"quay.apps.example.com"},
+		},
+	}
+
+	for i, c := range cases {
+
+		certificateIssuer := c.certificateIssuer
+
+		quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "quay",
+				Namespace: "quay-enterprise",
+			},
+			Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+				Clair: redhatcopv1alpha1.Clair{
+					Enabled: c.clairEnabled,
+				},
+				Quay: redhatcopv1alpha1.Quay{
+					CertificateIssuer: &certificateIssuer,
+				},
+			},
+		}
+
+		k8sclient := fake.NewFakeClient()
+
+		quayConfiguration := &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, QuayHostname: "quay.apps.example.com", ClairHostname: c.clairHostname}
+		r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, quayConfiguration)
+
+		if _, err := r.quayIssuedCertificate(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+			t.Fatalf("Test case %d returned an error: %v", i, err)
+		}
+
+		certificateName := resources.GetQuayCertificateName(quayEcosystem)
+		certificate := resources.GetCertificateDefinition(metav1.ObjectMeta{Name: certificateName, Namespace: quayEcosystem.Namespace}, redhatcopv1alpha1.CertificateIssuer{}, nil)
+
+		if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: certificateName, Namespace: quayEcosystem.Namespace}, certificate); err != nil {
+			t.Fatalf("Test case %d failed to locate Certificate: %v", i, err)
+		}
+
+		if dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames"); !reflect.DeepEqual(c.expected, dnsNames) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, dnsNames)
+		}
+	}
+}
+
+func TestManageExtraCACerts(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+			Quay: redhatcopv1alpha1.Quay{
+				InjectTrustedCABundle: true,
+			},
+		},
+	}
+
+	k8sclient := fake.NewFakeClient()
+
+	quayConfiguration := &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, QuayExtraCACerts: map[string][]byte{"proxy.crt": []byte("proxy")}}
+	r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, quayConfiguration)
+	meta := resources.NewResourceObjectMeta(quayEcosystem)
+
+	// Trusted CA bundle ConfigMap is created but not yet injected
+	result, err := r.ManageExtraCACerts(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to manage extra CA certificates: %v", err)
+	}
+
+	if result == nil || !result.Requeue {
+		t.Errorf("Expected a requeue while the trusted CA bundle is pending\nActual: %#v", result)
+	}
+
+	trustedCABundleConfigMap := &corev1.ConfigMap{}
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: resources.GetTrustedCABundleConfigMapName(quayEcosystem), Namespace: quayEcosystem.Namespace}, trustedCABundleConfigMap); err != nil {
+		t.Fatalf("Failed to locate trusted CA bundle ConfigMap: %v", err)
+	}
+
+	if trustedCABundleConfigMap.Labels[constants.TrustedCABundleInjectLabel] != "true" {
+		t.Errorf("Trusted CA bundle ConfigMap is missing the inject label\nActual: %#v", trustedCABundleConfigMap.Labels)
+	}
+
+	trustedCABundleConfigMap.Data = map[string]string{constants.TrustedCABundleKey: "bundle"}
+
+	if err := k8sclient.Update(context.TODO(), trustedCABundleConfigMap); err != nil {
+		t.Fatalf("Failed to inject trusted CA bundle: %v", err)
+	}
+
+	// Trusted CA bundle is merged with the provided extra CA certificates
+	result, err = r.ManageExtraCACerts(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to manage extra CA certificates: %v", err)
+	}
+
+	if result != nil {
+		t.Errorf("Expected extra CA certificate management to complete once the bundle is injected\nActual: %#v", result)
+	}
+
+	extraCACertsSecret := &corev1.Secret{}
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: resources.GetQuayExtraCertsSecretName(quayEcosystem), Namespace: quayEcosystem.Namespace}, extraCACertsSecret); err != nil {
+		t.Fatalf("Failed to locate extra CA certificates secret: %v", err)
+	}
+
+	expected := map[string][]byte{"proxy.crt": []byte("proxy"), constants.TrustedCABundleKey: []byte("bundle")}
+
+	if !reflect.DeepEqual(expected, extraCACertsSecret.Data) {
+		t.Errorf("Extra CA certificates did not match\nExpected: %#v\nActual: %#v", expected, extraCACertsSecret.Data)
+	}
+}
+
+func TestQuayLoadBalancerService(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+			Quay: redhatcopv1alpha1.Quay{
+				ServiceExposure: &redhatcopv1alpha1.ServiceExposure{
+					Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+					Type:        corev1.ServiceTypeLoadBalancer,
+				},
+			},
+		},
+	}
+
+	existingService := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.GetQuayResourcesName(quayEcosystem),
+			Namespace: quayEcosystem.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "172.30.0.10",
+			Type:      corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{
+				{
+					NodePort: 31443,
+					Port:     443,
+				},
+			},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}},
+			},
+		},
+	}
+
+	k8sclient := fake.NewFakeClient(existingService)
+
+	quayConfiguration := &resources.QuayConfiguration{QuayEcosystem: quayEcosystem}
+	r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, quayConfiguration)
+
+	quayService, err := r.createQuayService(resources.NewResourceObjectMeta(quayEcosystem))
+
+	if err != nil {
+		t.Fatalf("Failed to create Quay service: %v", err)
+	}
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: existingService.Name, Namespace: existingService.Namespace}, existingService); err != nil {
+		t.Fatalf("Failed to locate Quay service: %v", err)
+	}
+
+	if existingService.Spec.ClusterIP != "172.30.0.10" || existingService.Spec.Ports[0].NodePort != 31443 {
+		t.Errorf("Allocated values of the Quay service were not kept\nActual: %#v", existingService.Spec)
+	}
+
+	if existingService.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"] != "true" {
+		t.Errorf("Quay service is missing the exposure annotations\nActual: %#v", existingService.Annotations)
+	}
+
+	if result := r.quayLoadBalancerHostname(quayService); result != nil {
+		t.Fatalf("Expected the load balancer address to be available\nActual: %#v", result)
+	}
+
+	if quayEcosystem.Status.Hostname != "203.0.113.10" || quayConfiguration.QuayHostname != "203.0.113.10" {
+		t.Errorf("Hostname did not match\nExpected: %#v\nActual: %#v", "203.0.113.10", quayEcosystem.Status.Hostname)
+	}
+
+	// Quay is served on its Route Host without waiting for the load balancer address
+	quayEcosystem.Spec.Quay.RouteHost = "quay.example.com"
+	quayService.Status = corev1.ServiceStatus{}
+
+	if result := r.quayLoadBalancerHostname(quayService); result != nil {
+		t.Fatalf("Expected the Route Host to be served without a load balancer address\nActual: %#v", result)
+	}
+
+	if quayEcosystem.Status.Hostname != "quay.example.com" || quayConfiguration.QuayHostname != "quay.example.com" {
+		t.Errorf("Hostname did not match\nExpected: %#v\nActual: %#v", "quay.example.com", quayEcosystem.Status.Hostname)
+	}
+}
+
+func TestRemoveQuayExternalAccess(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+	}
+
+	quayName := resources.GetQuayResourcesName(quayEcosystem)
+	quayConfigName := resources.GetQuayConfigResourcesName(quayEcosystem)
+
+	k8sclient := fake.NewFakeClient(
+		&extensionsv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: quayName, Namespace: quayEcosystem.Namespace},
+		},
+		&extensionsv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: quayConfigName, Namespace: quayEcosystem.Namespace},
+		},
+	)
+
+	// Routes are not registered in the scheme, as on clusters other than OpenShift
+	r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem})
+
+	if err := r.removeQuayExternalAccess(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+		t.Fatalf("Failed to remove Quay external access: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		expected bool
+	}{
+		{
+			name:     quayName,
+			expected: false,
+		},
+		{
+			name:     quayConfigName,
+			expected: true,
+		},
+	}
+
+	for i, c := range cases {
+		result := k8sclient.Get(context.TODO(), types.NamespacedName{Name: c.name, Namespace: quayEcosystem.Namespace}, &extensionsv1beta1.Ingress{}) == nil
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestReadyCondition(t *testing.T) {
+
+	cases := []struct {
+		components map[redhatcopv1alpha1.QuayEcosystemComponent]redhatcopv1alpha1.ComponentStatus
+		expected   corev1.ConditionStatus
+	}{
+		{
+			components: map[redhatcopv1alpha1.QuayEcosystemComponent]redhatcopv1alpha1.ComponentStatus{},
+			expected:   corev1.ConditionFalse,
+		},
+		{
+			components: map[redhatcopv1alpha1.QuayEcosystemComponent]redhatcopv1alpha1.ComponentStatus{
+				redhatcopv1alpha1.QuayEcosystemComponentQuay:  {DesiredReplicas: 2, ReadyReplicas: 2},
+				redhatcopv1alpha1.QuayEcosystemComponentRedis: {DesiredReplicas: 1, ReadyReplicas: 0},
+			},
+			expected: corev1.ConditionFalse,
+		},
+		{
+			components: map[redhatcopv1alpha1.QuayEcosystemComponent]redhatcopv1alpha1.ComponentStatus{
+				redhatcopv1alpha1.QuayEcosystemComponentQuay:  {DesiredReplicas: 2, ReadyReplicas: 2},
+				redhatcopv1alpha1.QuayEcosystemComponentRedis: {DesiredReplicas: 1, ReadyReplicas: 1},
+			},
+			expected: corev1.ConditionTrue,
+		},
+	}
+
+	for i, c := range cases {
+		result := GetReadyCondition(c.components).Status
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestUpdateComponentStatus(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: redhatcopv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "QuayEcosystem",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+			Clair: redhatcopv1alpha1.Clair{
+				DeploymentMode: redhatcopv1alpha1.SplitClairDeploymentMode,
+				Version:        redhatcopv1alpha1.V4ClairVersion,
+			},
+		},
+	}
+
+	deployment := func(name string, readyReplicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: quayEcosystem.Namespace,
+			},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas: readyReplicas,
+			},
+		}
+	}
+
+	s := runtime.NewScheme()
+	scheme.AddToScheme(s)
+	redhatcopv1alpha1.SchemeBuilder.AddToScheme(s)
+
+	k8sclient := fake.NewFakeClientWithScheme(s,
+		quayEcosystem.DeepCopy(),
+		deployment(resources.GetQuayResourcesName(quayEcosystem), 1),
+		deployment(resources.GetClairComponentResourcesName(quayEcosystem, constants.LabelComponentClairIndexerValue), 1),
+		deployment(resources.GetClairComponentResourcesName(quayEcosystem, constants.LabelComponentClairMatcherValue), 1),
+		deployment(resources.GetClairComponentResourcesName(quayEcosystem, constants.LabelComponentClairNotifierValue), 0),
+	)
+
+	r := New(util.NewReconcilerBase(k8sclient, s, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem})
+
+	if err := r.UpdateComponentStatus(); err != nil {
+		t.Fatalf("Failed to update component status: %v", err)
+	}
+
+	expected := map[redhatcopv1alpha1.QuayEcosystemComponent]redhatcopv1alpha1.ComponentStatus{
+		redhatcopv1alpha1.QuayEcosystemComponentQuay:          {DesiredReplicas: 1, ReadyReplicas: 1},
+		redhatcopv1alpha1.QuayEcosystemComponentClairIndexer:  {DesiredReplicas: 1, ReadyReplicas: 1},
+		redhatcopv1alpha1.QuayEcosystemComponentClairMatcher:  {DesiredReplicas: 1, ReadyReplicas: 1},
+		redhatcopv1alpha1.QuayEcosystemComponentClairNotifier: {DesiredReplicas: 1, ReadyReplicas: 0},
+	}
+
+	if !reflect.DeepEqual(expected, quayEcosystem.Status.Components) {
+		t.Errorf("Components did not match\nExpected: %#v\nActual: %#v", expected, quayEcosystem.Status.Components)
+	}
+
+	readyCondition, _ := quayEcosystem.FindConditionByType(redhatcopv1alpha1.QuayEcosystemReady)
+
+	if readyCondition.Status != corev1.ConditionFalse || readyCondition.Message != "Components not ready: clairNotifier" {
+		t.Errorf("Unexpected Ready condition: %#v", readyCondition)
+	}
+}
+
+func TestIsClairRolledOut(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Status: redhatcopv1alpha1.QuayEcosystemStatus{
+			SecurityScannerKey: &redhatcopv1alpha1.SecurityScannerKeyStatus{
+				ID:          "kid-2",
+				PreviousIDs: []string{"kid-1"},
+			},
+		},
+	}
+
+	replicas := int32(2)
+
+	clairDeployment := func(keyID string, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       resources.GetClairResourcesName(quayEcosystem),
+				Namespace:  quayEcosystem.Namespace,
+				Generation: 2,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{constants.SecurityScannerKeyIDAnnotation: keyID},
+					},
+				},
+			},
+			Status: status,
+		}
+	}
+
+	cases := []struct {
+		objects  []runtime.Object
+		expected bool
+	}{
+		{
+			objects:  []runtime.Object{},
+			expected: false,
+		},
+		{
+			// Deployment not yet updated with the new key
+			objects:  []runtime.Object{clairDeployment("kid-1", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2})},
+			expected: false,
+		},
+		{
+			// Update not yet observed by the Deployment controller
+			objects:  []runtime.Object{clairDeployment("kid-2", appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2})},
+			expected: false,
+		},
+		{
+			// Pods using the previous key are still running
+			objects:  []runtime.Object{clairDeployment("kid-2", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 3})},
+			expected: false,
+		},
+		{
+			objects:  []runtime.Object{clairDeployment("kid-2", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1})},
+			expected: false,
+		},
+		{
+			objects:  []runtime.Object{clairDeployment("kid-2", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2})},
+			expected: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		r := New(util.NewReconcilerBase(fake.NewFakeClient(c.objects...), scheme.Scheme, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem})
+
+		result, err := r.IsClairRolledOut()
+
+		if err != nil {
+			t.Fatalf("Test case %d returned an error: %v", i, err)
+		}
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestUpdateComponentStatusOfDeletedQuayEcosystem(t *testing.T) {
+
+	deletionTimestamp := metav1.Now()
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: redhatcopv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "QuayEcosystem",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "quay",
+			Namespace:         "quay-enterprise",
+			DeletionTimestamp: &deletionTimestamp,
+			Finalizers:        []string{constants.QuayEcosystemFinalizer},
+		},
+	}
+
+	quayDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.GetQuayResourcesName(quayEcosystem),
+			Namespace: quayEcosystem.Namespace,
+		},
+	}
+
+	cases := []struct {
+		objects []runtime.Object
+	}{
+		{
+			// Deletion started while the QuayEcosystem was being reconciled
+			objects: []runtime.Object{quayEcosystem.DeepCopy(), quayDeployment},
+		},
+		{
+			// Finalizer removed and the QuayEcosystem is gone
+			objects: []runtime.Object{quayDeployment},
+		},
+	}
+
+	s := runtime.NewScheme()
+	scheme.AddToScheme(s)
+	redhatcopv1alpha1.SchemeBuilder.AddToScheme(s)
+
+	for i, c := range cases {
+
+		k8sclient := fake.NewFakeClientWithScheme(s, c.objects...)
+
+		inMemoryQuayEcosystem := quayEcosystem.DeepCopy()
+		inMemoryQuayEcosystem.DeletionTimestamp = nil
+
+		r := New(util.NewReconcilerBase(k8sclient, s, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: inMemoryQuayEcosystem})
+
+		if err := r.UpdateComponentStatus(); err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		if inMemoryQuayEcosystem.Status.Components != nil {
+			t.Errorf("Test case %d updated the status of a deleted QuayEcosystem: %#v", i, inMemoryQuayEcosystem.Status.Components)
+		}
+	}
+}
+
+func TestCleanupResources(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+			UID:       types.UID("2b4c1b3e-7c3a-4c4b-a0a5-5e2b0f5d8e61"),
+		},
+		Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+			Quay: redhatcopv1alpha1.Quay{
+				Database: redhatcopv1alpha1.Database{
+					RetentionPolicy: redhatcopv1alpha1.RetainPersistentVolumeClaimRetentionPolicy,
+				},
+				RegistryBackends: []redhatcopv1alpha1.RegistryBackend{
+					{
+						Name: "local",
+						RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+							Local: &redhatcopv1alpha1.LocalRegistryBackendSource{},
+						},
+					},
+				},
+				RegistryStorage: redhatcopv1alpha1.RegistryStorage{
+					PersistentVolumeSize:            "10Gi",
+					PersistentVolumeRetentionPolicy: redhatcopv1alpha1.SnapshotPersistentVolumeClaimRetentionPolicy,
+				},
+			},
+		},
+	}
+
+	ownerReferences := []metav1.OwnerReference{
+		{
+			APIVersion: redhatcopv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "QuayEcosystem",
+			Name:       quayEcosystem.Name,
+			UID:        quayEcosystem.UID,
+		},
+	}
+
+	databasePVCName := resources.GetQuayDatabaseName(quayEcosystem)
+	registryPVCName := resources.GetRegistryStorageVolumeName(quayEcosystem, "local")
+	sccUser := getSCCUser(quayEcosystem.Namespace, constants.QuayServiceAccount)
+
+	s := runtime.NewScheme()
+	scheme.AddToScheme(s)
+	ossecurityv1.AddToScheme(s)
+	redhatcopv1alpha1.SchemeBuilder.AddToScheme(s)
+
+	k8sclient := fake.NewFakeClientWithScheme(s,
+		&ossecurityv1.SecurityContextConstraints{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.AnyUIDSCC},
+			Users:      []string{"system:serviceaccount:other:default", sccUser},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: databasePVCName, Namespace: quayEcosystem.Namespace, OwnerReferences: ownerReferences},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: registryPVCName, Namespace: quayEcosystem.Namespace, OwnerReferences: ownerReferences},
+		},
+	)
+
+	r := New(util.NewReconcilerBase(k8sclient, s, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, IsOpenShift: true})
+	meta := resources.NewResourceObjectMeta(quayEcosystem)
+
+	// Snapshot is created but not yet ready
+	result, err := r.CleanupResources(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to clean up resources: %v", err)
+	}
+
+	if result == nil || !result.Requeue {
+		t.Errorf("Expected a requeue while the VolumeSnapshot is pending\nActual: %#v", result)
+	}
+
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetAPIVersion(constants.VolumeSnapshotAPIVersion)
+	volumeSnapshot.SetKind(constants.VolumeSnapshotKind)
+
+	if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: resources.GetVolumeSnapshotName(quayEcosystem, registryPVCName), Namespace: quayEcosystem.Namespace}, volumeSnapshot); err != nil {
+		t.Fatalf("Failed to get VolumeSnapshot: %v", err)
+	}
+
+	unstructured.SetNestedField(volumeSnapshot.Object, true, "status", "readyToUse")
+
+	if err := k8sclient.Update(context.TODO(), volumeSnapshot); err != nil {
+		t.Fatalf("Failed to update VolumeSnapshot: %v", err)
+	}
+
+	// Snapshot is ready
+	result, err = r.CleanupResources(meta)
+
+	if err != nil {
+		t.Fatalf("Failed to clean up resources: %v", err)
+	}
+
+	if result != nil {
+		t.Errorf("Expected cleanup to complete once the VolumeSnapshot is ready\nActual: %#v", result)
+	}
+
+	anyUIDSCC := &ossecurityv1.SecurityContextConstraints{}
+	k8sclient.Get(context.TODO(), types.NamespacedName{Name: constants.AnyUIDSCC}, anyUIDSCC)
+
+	if expected := []string{"system:serviceaccount:other:default"}; !reflect.DeepEqual(expected, anyUIDSCC.Users) {
+		t.Errorf("SCC users did not match\nExpected: %#v\nActual: %#v", expected, anyUIDSCC.Users)
+	}
+
+	databasePVC := &corev1.PersistentVolumeClaim{}
+	k8sclient.Get(context.TODO(), types.NamespacedName{Name: databasePVCName, Namespace: quayEcosystem.Namespace}, databasePVC)
+
+	if len(databasePVC.OwnerReferences) != 0 {
+		t.Errorf("Expected retained PersistentVolumeClaim to be released\nActual: %#v", databasePVC.OwnerReferences)
+	}
+}
+
+func TestRemoveAnyUIDSCCUsers(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+			UID:       types.UID("2b4c1b3e-7c3a-4c4b-a0a5-5e2b0f5d8e61"),
+		},
+	}
+
+	deletionTimestamp := metav1.Now()
+	sccUser := getSCCUser(quayEcosystem.Namespace, constants.QuayServiceAccount)
+
+	otherQuayEcosystem := func(namespace string, deletionTimestamp *metav1.Time) *redhatcopv1alpha1.QuayEcosystem {
+		return &redhatcopv1alpha1.QuayEcosystem{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: redhatcopv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "QuayEcosystem",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "other",
+				Namespace:         namespace,
+				UID:               types.UID("8f0d3c6a-1b6e-4d2a-9a57-0c2f5b6e9d14"),
+				DeletionTimestamp: deletionTimestamp,
+			},
+		}
+	}
+
+	cases := []struct {
+		quayEcosystems []runtime.Object
+		expected       []string
+	}{
+		{
+			quayEcosystems: []runtime.Object{},
+			expected:       []string{"system:serviceaccount:other:default"},
+		},
+		{
+			// Another QuayEcosystem in the namespace still runs with the shared service account
+			quayEcosystems: []runtime.Object{otherQuayEcosystem(quayEcosystem.Namespace, nil)},
+			expected:       []string{"system:serviceaccount:other:default", sccUser},
+		},
+		{
+			quayEcosystems: []runtime.Object{otherQuayEcosystem(quayEcosystem.Namespace, &deletionTimestamp)},
+			expected:       []string{"system:serviceaccount:other:default"},
+		},
+		{
+			quayEcosystems: []runtime.Object{otherQuayEcosystem("other", nil)},
+			expected:       []string{"system:serviceaccount:other:default"},
+		},
+	}
+
+	for i, c := range cases {
+
+		s := runtime.NewScheme()
+		scheme.AddToScheme(s)
+		ossecurityv1.AddToScheme(s)
+		redhatcopv1alpha1.SchemeBuilder.AddToScheme(s)
+
+		objects := append([]runtime.Object{
+			&ossecurityv1.SecurityContextConstraints{
+				ObjectMeta: metav1.ObjectMeta{Name: constants.AnyUIDSCC},
+				Users:      []string{"system:serviceaccount:other:default", sccUser},
+			},
+		}, c.quayEcosystems...)
+
+		k8sclient := fake.NewFakeClientWithScheme(s, objects...)
+
+		r := New(util.NewReconcilerBase(k8sclient, s, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, IsOpenShift: true})
+
+		if err := r.removeAnyUIDSCCUsers(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		anyUIDSCC := &ossecurityv1.SecurityContextConstraints{}
+		k8sclient.Get(context.TODO(), types.NamespacedName{Name: constants.AnyUIDSCC}, anyUIDSCC)
+
+		if !reflect.DeepEqual(c.expected, anyUIDSCC.Users) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, anyUIDSCC.Users)
+		}
+	}
+}
+
+func TestRemoveClairResources(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+	}
+
+	clairName := resources.GetClairResourcesName(quayEcosystem)
+	clairConfigSecretName := resources.GetClairConfigSecretName(quayEcosystem)
+	clairDatabaseName := resources.GetClairDatabaseName(quayEcosystem)
+
+	k8sclient := fake.NewFakeClient(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: clairName, Namespace: quayEcosystem.Namespace},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: clairConfigSecretName, Namespace: quayEcosystem.Namespace},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: clairDatabaseName, Namespace: quayEcosystem.Namespace},
+		},
+	)
+
+	r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem})
+
+	if err := r.RemoveClairResources(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+		t.Fatalf("Failed to remove Clair resources: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		object   runtime.Object
+		expected bool
+	}{
+		{
+			name:     clairName,
+			object:   &appsv1.Deployment{},
+			expected: false,
+		},
+		{
+			name:     clairConfigSecretName,
+			object:   &corev1.Secret{},
+			expected: false,
+		},
+		{
+			name:     clairDatabaseName,
+			object:   &corev1.PersistentVolumeClaim{},
+			expected: true,
+		},
+	}
+
+	for i, c := range cases {
+		result := k8sclient.Get(context.TODO(), types.NamespacedName{Name: c.name, Namespace: quayEcosystem.Namespace}, c.object) == nil
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestManageClairPaginationKey(t *testing.T) {
+
+	existingKey := "existing"
+
+	clairConfigSecret := func(paginationKey string, rotation string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{constants.ClairPaginationKeyRotationAnnotation: rotation},
+			},
+			Data: map[string][]byte{constants.ClairPaginationKeySecretKey: []byte(paginationKey)},
+		}
+	}
+
+	cases := []struct {
+		secret   *corev1.Secret
+		rotation string
+		expected bool
+	}{
+		{
+			secret:   clairConfigSecret("", ""),
+			rotation: "",
+			expected: false,
+		},
+		{
+			secret:   clairConfigSecret(existingKey, ""),
+			rotation: "",
+			expected: true,
+		},
+		{
+			secret:   clairConfigSecret(existingKey, "1"),
+			rotation: "1",
+			expected: true,
+		},
+		{
+			secret:   clairConfigSecret(existingKey, "1"),
+			rotation: "2",
+			expected: false,
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{constants.ClairPaginationKeyRotationAnnotation: c.rotation},
+			},
+		}
+
+		r := New(util.NewReconcilerBase(fake.NewFakeClient(), scheme.Scheme, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem})
+
+		paginationKey, err := r.manageClairPaginationKey(c.secret)
+
+		if err != nil {
+			t.Fatalf("Failed to manage Clair pagination key: %v", err)
+		}
+
+		if len(paginationKey) == 0 || string(c.secret.Data[constants.ClairPaginationKeySecretKey]) != paginationKey || c.secret.Annotations[constants.ClairPaginationKeyRotationAnnotation] != c.rotation {
+			t.Errorf("Test case %d stored an unexpected pagination key %s", i, paginationKey)
+		}
+
+		result := paginationKey == existingKey
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestManageSecurityScannerConfig(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+	}
+
+	configSecretName := resources.GetConfigMapSecretName(quayEcosystem)
+
+	k8sclient := fake.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: configSecretName, Namespace: quayEcosystem.Namespace},
+		Data: map[string][]byte{
+			constants.QuayConfigKey: []byte("FEATURE_SECURITY_SCANNER: true\nSECURITY_SCANNER_ENDPOINT: http://quay-clair:6060\nSECURITY_SCANNER_ISSUER_NAME: security_scanner\nSERVER_HOSTNAME: quay.example.com\n"),
+		},
+	})
+
+	enabled := true
+	disabled := false
+
+	cases := []struct {
+		enabled  *bool
+		expected map[string]interface{}
+	}{
+		{
+			enabled: &disabled,
+			expected: map[string]interface{}{
+				"FEATURE_SECURITY_SCANNER": false,
+				"SERVER_HOSTNAME":          "quay.example.com",
+			},
+		},
+		{
+			enabled: &enabled,
+			expected: map[string]interface{}{
+				"FEATURE_SECURITY_SCANNER":     true,
+				"SECURITY_SCANNER_ENDPOINT":    "http://quay-clair:6060",
+				"SECURITY_SCANNER_ISSUER_NAME": "security_scanner",
+				"SERVER_HOSTNAME":              "quay.example.com",
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem.Spec.Clair.Enabled = c.enabled
+
+		r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, ClairHostname: "quay-clair:6060"})
+
+		if err := r.ManageSecurityScannerConfig(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+			t.Fatalf("Test case %d returned an error: %v", i, err)
+		}
+
+		configSecret := &corev1.Secret{}
+		k8sclient.Get(context.TODO(), types.NamespacedName{Name: configSecretName, Namespace: quayEcosystem.Namespace}, configSecret)
+
+		quayConfig := map[string]interface{}{}
+
+		if err := yaml.Unmarshal(configSecret.Data[constants.QuayConfigKey], &quayConfig); err != nil {
+			t.Fatalf("Test case %d stored an invalid configuration: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.expected, quayConfig) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, quayConfig)
+		}
+	}
+}
+
+func TestManageStorageReplicationConfig(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+		Status: redhatcopv1alpha1.QuayEcosystemStatus{
+			SetupComplete: true,
+		},
+	}
+
+	configSecretName := resources.GetConfigMapSecretName(quayEcosystem)
+
+	// Configuration written during setup with a single preferred backend and no replication
+	k8sclient := fake.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: configSecretName, Namespace: quayEcosystem.Namespace},
+		Data: map[string][]byte{
+			constants.QuayConfigKey: []byte("DISTRIBUTED_STORAGE_DEFAULT_LOCATIONS: []\nDISTRIBUTED_STORAGE_PREFERENCE:\n- us-east\n- eu-west\nFEATURE_STORAGE_REPLICATION: false\nSERVER_HOSTNAME: quay.example.com\n"),
+		},
+	})
+
+	cases := []struct {
+		registryBackends []redhatcopv1alpha1.RegistryBackend
+		expected         map[string]interface{}
+	}{
+		{
+			registryBackends: []redhatcopv1alpha1.RegistryBackend{
+				{Name: "us-east", Preferred: true},
+				{Name: "eu-west", ReplicateByDefault: true},
+			},
+			expected: map[string]interface{}{
+				"DISTRIBUTED_STORAGE_DEFAULT_LOCATIONS": []interface{}{"eu-west"},
+				"DISTRIBUTED_STORAGE_PREFERENCE":        []interface{}{"us-east", "eu-west"},
+				"FEATURE_STORAGE_REPLICATION":           true,
+				"SERVER_HOSTNAME":                       "quay.example.com",
+			},
+		},
+		{
+			registryBackends: []redhatcopv1alpha1.RegistryBackend{
+				{Name: "us-east"},
+				{Name: "eu-west", Preferred: true},
+			},
+			expected: map[string]interface{}{
+				"DISTRIBUTED_STORAGE_DEFAULT_LOCATIONS": []interface{}{},
+				"DISTRIBUTED_STORAGE_PREFERENCE":        []interface{}{"eu-west", "us-east"},
+				"FEATURE_STORAGE_REPLICATION":           false,
+				"SERVER_HOSTNAME":                       "quay.example.com",
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem.Spec.Quay.RegistryBackends = c.registryBackends
+
+		r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem})
+
+		if err := r.ManageStorageReplicationConfig(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+			t.Fatalf("Test case %d returned an error: %v", i, err)
+		}
+
+		configSecret := &corev1.Secret{}
+		k8sclient.Get(context.TODO(), types.NamespacedName{Name: configSecretName, Namespace: quayEcosystem.Namespace}, configSecret)
+
+		quayConfig := map[string]interface{}{}
+
+		if err := yaml.Unmarshal(configSecret.Data[constants.QuayConfigKey], &quayConfig); err != nil {
+			t.Fatalf("Test case %d stored an invalid configuration: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.expected, quayConfig) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, quayConfig)
+		}
+
+		// The replication worker follows the same registry backends as the stored configuration
+		if replication := resources.IsStorageReplicationEnabled(quayEcosystem); replication != quayConfig["FEATURE_STORAGE_REPLICATION"] {
+			t.Errorf("Test case %d replication worker did not match the configuration\nExpected: %#v\nActual: %#v", i, quayConfig["FEATURE_STORAGE_REPLICATION"], replication)
+		}
+	}
+}
+
+func TestManageClairConfigPaginationKeyRotation(t *testing.T) {
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "quay",
+			Namespace:   "quay-enterprise",
+			Annotations: map[string]string{constants.ClairPaginationKeyRotationAnnotation: "1"},
+		},
+	}
+
+	clairConfigSecretName := resources.GetClairConfigSecretName(quayEcosystem)
+
+	k8sclient := fake.NewFakeClient(&corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        clairConfigSecretName,
+			Namespace:   quayEcosystem.Namespace,
+			Annotations: map[string]string{constants.ClairPaginationKeyRotationAnnotation: "1"},
+		},
+		Data: map[string][]byte{constants.ClairPaginationKeySecretKey: []byte("existing")},
+	})
+
+	renderedPaginationKey := func() (string, string) {
+
+		clairConfigSecret := &corev1.Secret{}
+
+		if err := k8sclient.Get(context.TODO(), types.NamespacedName{Name: clairConfigSecretName, Namespace: quayEcosystem.Namespace}, clairConfigSecret); err != nil {
+			t.Fatalf("Failed to locate clair config secret: %v", err)
+		}
+
+		clairConfig := resources.ClairConfigFile{}
+
+		if err := yaml.Unmarshal(clairConfigSecret.Data[constants.ClairConfigKey], &clairConfig); err != nil {
+			t.Fatalf("Failed to read clair config: %v", err)
+		}
+
+		return clairConfig.Clair.API.PaginationKey, string(clairConfigSecret.Data[constants.ClairPaginationKeySecretKey])
+	}
+
+	cases := []struct {
+		rotation string
+		changed  bool
+	}{
+		{
+			rotation: "1",
+			changed:  false,
+		},
+		{
+			rotation: "1",
+			changed:  false,
+		},
+		{
+			rotation: "2",
+			changed:  true,
+		},
+		{
+			rotation: "2",
+			changed:  false,
+		},
+	}
+
+	previousKey := "existing"
+
+	for i, c := range cases {
+
+		quayEcosystem.ObjectMeta.Annotations[constants.ClairPaginationKeyRotationAnnotation] = c.rotation
+
+		r := New(util.NewReconcilerBase(k8sclient, scheme.Scheme, nil, nil), nil, &resources.QuayConfiguration{QuayEcosystem: quayEcosystem, SecurityScannerKeyKid: "kid"})
+
+		if _, err := r.ManageClairConfig(resources.NewResourceObjectMeta(quayEcosystem)); err != nil {
+			t.Fatalf("Test case %d returned an error: %v", i, err)
+		}
+
+		paginationKey, storedKey := renderedPaginationKey()
+
+		if len(paginationKey) == 0 || paginationKey != storedKey {
+			t.Errorf("Test case %d rendered pagination key %q which does not match the stored key %q", i, paginationKey, storedKey)
+		}
+
+		result := paginationKey != previousKey
+
+		if c.changed != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.changed, result)
+		}
+
+		previousKey = paginationKey
+	}
+}
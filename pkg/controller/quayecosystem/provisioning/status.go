@@ -0,0 +1,192 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redhat-cop/operator-utils/pkg/util"
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// UpdateComponentStatus records the state of each Deployment owned by the QuayEcosystem along with the aggregate Ready condition
+func (r *ReconcileQuayEcosystemConfiguration) UpdateComponentStatus() error {
+
+	quayEcosystem := r.quayConfiguration.QuayEcosystem
+
+	// The QuayEcosystem may have been deleted while it was being reconciled
+	latestQuayEcosystem := &redhatcopv1alpha1.QuayEcosystem{}
+
+	if err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: quayEcosystem.Name, Namespace: quayEcosystem.Namespace}, latestQuayEcosystem); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if util.IsBeingDeleted(quayEcosystem) || util.IsBeingDeleted(latestQuayEcosystem) {
+		return nil
+	}
+
+	componentDeployments := map[redhatcopv1alpha1.QuayEcosystemComponent]string{
+		redhatcopv1alpha1.QuayEcosystemComponentQuay:          resources.GetQuayResourcesName(quayEcosystem),
+		redhatcopv1alpha1.QuayEcosystemComponentQuayConfig:    resources.GetQuayConfigResourcesName(quayEcosystem),
+		redhatcopv1alpha1.QuayEcosystemComponentClair:         resources.GetClairResourcesName(quayEcosystem),
+		redhatcopv1alpha1.QuayEcosystemComponentClairIndexer:  resources.GetClairComponentResourcesName(quayEcosystem, constants.LabelComponentClairIndexerValue),
+		redhatcopv1alpha1.QuayEcosystemComponentClairMatcher:  resources.GetClairComponentResourcesName(quayEcosystem, constants.LabelComponentClairMatcherValue),
+		redhatcopv1alpha1.QuayEcosystemComponentClairNotifier: resources.GetClairComponentResourcesName(quayEcosystem, constants.LabelComponentClairNotifierValue),
+		redhatcopv1alpha1.QuayEcosystemComponentRedis:         resources.GetRedisResourcesName(quayEcosystem),
+		redhatcopv1alpha1.QuayEcosystemComponentDatabase:      resources.GetQuayDatabaseName(quayEcosystem),
+		redhatcopv1alpha1.QuayEcosystemComponentClairDatabase: resources.GetClairDatabaseName(quayEcosystem),
+	}
+
+	components := map[redhatcopv1alpha1.QuayEcosystemComponent]redhatcopv1alpha1.ComponentStatus{}
+
+	for component, deploymentName := range componentDeployments {
+
+		deployment := &appsv1.Deployment{}
+
+		err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: deploymentName, Namespace: quayEcosystem.Namespace}, deployment)
+
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		components[component] = GetComponentStatus(deployment)
+	}
+
+	quayEcosystem.Status.Components = components
+	quayEcosystem.SetCondition(GetReadyCondition(components))
+
+	return r.reconcilerBase.GetClient().Status().Update(context.TODO(), quayEcosystem)
+}
+
+// GetComponentStatus returns the status of a component from the Deployment backing it
+func GetComponentStatus(deployment *appsv1.Deployment) redhatcopv1alpha1.ComponentStatus {
+
+	componentStatus := redhatcopv1alpha1.ComponentStatus{
+		DesiredReplicas: 1,
+		ReadyReplicas:   deployment.Status.ReadyReplicas,
+	}
+
+	if deployment.Spec.Replicas != nil {
+		componentStatus.DesiredReplicas = *deployment.Spec.Replicas
+	}
+
+	if len(deployment.Spec.Template.Spec.Containers) > 0 {
+		componentStatus.Image = deployment.Spec.Template.Spec.Containers[0].Image
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+
+		failed := (condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue) ||
+			(condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse)
+
+		if failed {
+			componentStatus.LastError = condition.Message
+			break
+		}
+	}
+
+	return componentStatus
+}
+
+// IsClairRolledOut returns whether every Clair deployment runs the pod template referencing the security scanner key in
+// the status on all of its replicas
+func (r *ReconcileQuayEcosystemConfiguration) IsClairRolledOut() (bool, error) {
+
+	quayEcosystem := r.quayConfiguration.QuayEcosystem
+
+	var securityScannerKeyID string
+
+	if quayEcosystem.Status.SecurityScannerKey != nil {
+		securityScannerKeyID = quayEcosystem.Status.SecurityScannerKey.ID
+	}
+
+	for _, clairComponent := range resources.GetClairComponents(quayEcosystem) {
+
+		deployment := &appsv1.Deployment{}
+
+		err := r.reconcilerBase.GetClient().Get(context.TODO(), types.NamespacedName{Name: resources.GetClairComponentResourcesName(quayEcosystem, clairComponent), Namespace: quayEcosystem.Namespace}, deployment)
+
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		// The Deployment may not have been updated with the current key yet
+		if deployment.Spec.Template.Annotations[constants.SecurityScannerKeyIDAnnotation] != securityScannerKeyID || !IsDeploymentRolledOut(deployment) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// IsDeploymentRolledOut returns whether the latest pod template of a Deployment has been rolled out to all of its replicas
+// and no replicas of a previous pod template remain
+func IsDeploymentRolledOut(deployment *appsv1.Deployment) bool {
+
+	replicas := int32(1)
+
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.AvailableReplicas >= replicas &&
+		deployment.Status.Replicas == deployment.Status.UpdatedReplicas
+}
+
+// IsComponentReady returns whether all desired replicas of a component are ready
+func IsComponentReady(componentStatus redhatcopv1alpha1.ComponentStatus) bool {
+	return componentStatus.ReadyReplicas >= componentStatus.DesiredReplicas
+}
+
+// GetReadyCondition returns the aggregate Ready condition for the given components
+func GetReadyCondition(components map[redhatcopv1alpha1.QuayEcosystemComponent]redhatcopv1alpha1.ComponentStatus) redhatcopv1alpha1.QuayEcosystemCondition {
+
+	notReady := []string{}
+
+	if _, found := components[redhatcopv1alpha1.QuayEcosystemComponentQuay]; !found {
+		notReady = append(notReady, string(redhatcopv1alpha1.QuayEcosystemComponentQuay))
+	}
+
+	for component, componentStatus := range components {
+		if !IsComponentReady(componentStatus) {
+			notReady = append(notReady, string(component))
+		}
+	}
+
+	if len(notReady) > 0 {
+
+		sort.Strings(notReady)
+
+		return redhatcopv1alpha1.QuayEcosystemCondition{
+			Type:    redhatcopv1alpha1.QuayEcosystemReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  "ComponentsNotReady",
+			Message: fmt.Sprintf("Components not ready: %s", strings.Join(notReady, ", ")),
+		}
+	}
+
+	return redhatcopv1alpha1.QuayEcosystemCondition{
+		Type:    redhatcopv1alpha1.QuayEcosystemReady,
+		Status:  corev1.ConditionTrue,
+		Reason:  "ComponentsReady",
+		Message: "All components are ready",
+	}
+}
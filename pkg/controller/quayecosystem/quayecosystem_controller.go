@@ -2,12 +2,14 @@ package quayecosystem
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"time"
 
 	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
 
 	"github.com/redhat-cop/operator-utils/pkg/util"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/provisioning"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
@@ -16,21 +18,26 @@ import (
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/validation"
 	"github.com/theodor2311/quay-operator/pkg/k8sutils"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // Add creates a new QuayEcosystem Controller and adds it to the Manager. The Manager will set fields on the Controller
-// and Start it when the Manager is Started.
-func Add(mgr manager.Manager) error {
+// and Start it when the Manager is Started. isOpenShift reports whether the cluster serves the OpenShift APIs.
+func Add(mgr manager.Manager, isOpenShift bool) error {
 
 	k8sclient, err := k8sutils.GetK8sClient(mgr.GetConfig())
 
@@ -38,15 +45,15 @@ func Add(mgr manager.Manager) error {
 		return err
 	}
 
-	return add(mgr, newReconciler(mgr, k8sclient))
+	return add(mgr, newReconciler(mgr, k8sclient, isOpenShift))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, k8sclient kubernetes.Interface) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, k8sclient kubernetes.Interface, isOpenShift bool) reconcile.Reconciler {
 
 	reconcilerBase := util.NewReconcilerBase(mgr.GetClient(), mgr.GetScheme(), mgr.GetConfig(), mgr.GetRecorder("quayecosystem-controller"))
 
-	return &ReconcileQuayEcosystem{reconcilerBase: reconcilerBase, k8sclient: k8sclient, quaySetupManager: setup.NewQuaySetupManager(reconcilerBase, k8sclient)}
+	return &ReconcileQuayEcosystem{reconcilerBase: reconcilerBase, k8sclient: k8sclient, isOpenShift: isOpenShift, quaySetupManager: setup.NewQuaySetupManager(reconcilerBase, k8sclient)}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -58,7 +65,24 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	}
 
 	// Watch for changes to primary resource QuayEcosystem
-	err = c.Watch(&source.Kind{Type: &redhatcopv1alpha1.QuayEcosystem{}}, &handler.EnqueueRequestForObject{}, util.ResourceGenerationOrFinalizerChangedPredicate{})
+	err = c.Watch(&source.Kind{Type: &redhatcopv1alpha1.QuayEcosystem{}}, &handler.EnqueueRequestForObject{}, quayEcosystemChangedPredicate{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Deployments owned by a QuayEcosystem to keep the component status current
+	err = c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &redhatcopv1alpha1.QuayEcosystem{},
+	}, deploymentReadinessChangedPredicate{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Secrets populated by cert-manager so that issued and renewed certificates are picked up as soon as they are stored
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: issuedCertificateSecretMapper{client: mgr.GetClient()},
+	}, issuedCertificateSecretChangedPredicate{})
 	if err != nil {
 		return err
 	}
@@ -73,6 +97,7 @@ var _ reconcile.Reconciler = &ReconcileQuayEcosystem{}
 type ReconcileQuayEcosystem struct {
 	reconcilerBase   util.ReconcilerBase
 	k8sclient        kubernetes.Interface
+	isOpenShift      bool
 	quaySetupManager *setup.QuaySetupManager
 }
 
@@ -94,34 +119,55 @@ func (r *ReconcileQuayEcosystem) Reconcile(request reconcile.Request) (reconcile
 	// Initialize a new Quay Configuration Resource
 	quayConfiguration := resources.QuayConfiguration{
 		QuayEcosystem: quayEcosystem,
+		IsOpenShift:   r.isOpenShift,
 	}
 
 	// Initialize Configuration
 	configuration := provisioning.New(r.reconcilerBase, r.k8sclient, &quayConfiguration)
 	metaObject := resources.NewResourceObjectMeta(quayConfiguration.QuayEcosystem)
 
-	// Set default values
-	changed := validation.SetDefaults(r.reconcilerBase.GetClient(), &quayConfiguration)
-
-	if changed {
+	// Clean up resources that are not garbage collected once the QuayEcosystem is deleted
+	if util.IsBeingDeleted(quayEcosystem) {
 
-		err := r.reconcilerBase.GetClient().Update(context.TODO(), quayConfiguration.QuayEcosystem)
+		if !util.HasFinalizer(quayEcosystem, constants.QuayEcosystemFinalizer) {
+			return reconcile.Result{}, nil
+		}
 
+		result, err := configuration.CleanupResources(metaObject)
 		if err != nil {
-			logging.Log.Error(err, "Failed to update QuayEcosystem after setting defaults")
-			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+			return r.manageError(quayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+		}
 
+		if result != nil {
+			return *result, nil
 		}
 
-		_, err = r.manageSuccess(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemUpdateDefaultConfigurationConditionSuccess, "", "Configuration Updated Successfully")
+		util.RemoveFinalizer(quayEcosystem, constants.QuayEcosystemFinalizer)
 
-		if err != nil {
-			logging.Log.Error(err, "Failed to update QuayEcosystem status after setting defaults")
-			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemUpdateDefaultConfigurationConditionFailure, err)
+		return reconcile.Result{}, r.reconcilerBase.GetClient().Update(context.TODO(), quayEcosystem)
+	}
+
+	if !util.HasFinalizer(quayEcosystem, constants.QuayEcosystemFinalizer) {
+
+		util.AddFinalizer(quayEcosystem, constants.QuayEcosystemFinalizer)
+
+		if err := r.reconcilerBase.GetClient().Update(context.TODO(), quayEcosystem); err != nil {
+			logging.Log.Error(err, "Failed to add finalizer", "Namespace", quayEcosystem.Namespace, "Name", quayEcosystem.Name)
+			return reconcile.Result{}, err
 		}
+	}
 
-		return reconcile.Result{}, nil
+	// Report the state of each component regardless of how far reconciliation progresses
+	defer func() {
+		if err := configuration.UpdateComponentStatus(); err != nil {
+			logging.Log.Error(err, "Failed to update QuayEcosystem component status", "Namespace", quayConfiguration.QuayEcosystem.Namespace, "Name", quayConfiguration.QuayEcosystem.Name)
+		}
+	}()
 
+	// Set default values. Defaults are normally applied by the mutating webhook at admission time, so any
+	// applied here are only kept in memory to avoid conflicting with tools that manage the spec
+	if validation.SetDefaults(r.reconcilerBase.GetClient(), &quayConfiguration) {
+		logging.Log.Info("Applied defaults not set at admission time", "Namespace", quayConfiguration.QuayEcosystem.Namespace, "Name", quayConfiguration.QuayEcosystem.Name)
 	}
 
 	// Validate Configuration
@@ -152,12 +198,53 @@ func (r *ReconcileQuayEcosystem) Reconcile(request reconcile.Request) (reconcile
 		return *result, nil
 	}
 
-	result, err = configuration.ManageClairTrustCA(metaObject)
+	result, err = configuration.ManageExtraCACerts(metaObject)
+
+	if err != nil {
+		return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+	}
 
 	if result != nil {
 		return *result, nil
 	}
 
+	if resources.IsClairEnabled(quayConfiguration.QuayEcosystem) {
+
+		result, err = configuration.ManageClairTrustCA(metaObject)
+
+		if result != nil {
+			return *result, nil
+		}
+
+		if resources.IsClairV4(quayConfiguration.QuayEcosystem) {
+
+			result, err = configuration.ManageClairPSK(metaObject)
+
+			if err != nil {
+				return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+			}
+
+			if result != nil {
+				return *result, nil
+			}
+		}
+	}
+
+	// Security scanner keys are managed through the Quay config app, which is therefore deployed while a key is rotated,
+	// created because Clair v2 was enabled after setup or revoked because it no longer is in use
+	deployQuayConfiguration := quayConfiguration.DeployQuayConfiguration
+	securityScannerKeyInUse := resources.IsClairEnabled(quayConfiguration.QuayEcosystem) && !resources.IsClairV4(quayConfiguration.QuayEcosystem)
+	manageSecurityScannerKey := quayConfiguration.QuayEcosystem.Status.SetupComplete && securityScannerKeyInUse && quayConfiguration.QuayEcosystem.Status.SecurityScannerKey != nil &&
+		(setup.IsSecurityScannerKeyRotationDue(quayConfiguration.QuayEcosystem, time.Now()) || setup.HasPreviousSecurityScannerKeys(quayConfiguration.QuayEcosystem))
+	createSecurityScannerKey := quayConfiguration.QuayEcosystem.Status.SetupComplete && securityScannerKeyInUse && quayConfiguration.QuayEcosystem.Status.SecurityScannerKey == nil
+	revokeSecurityScannerKey := quayConfiguration.QuayEcosystem.Status.SetupComplete && !securityScannerKeyInUse && quayConfiguration.QuayEcosystem.Status.SecurityScannerKey != nil
+
+	if manageSecurityScannerKey || createSecurityScannerKey || revokeSecurityScannerKey {
+		quayConfiguration.DeployQuayConfiguration = true
+	}
+
+	clairRolloutPending := false
+
 	if quayConfiguration.DeployQuayConfiguration {
 
 		deployQuayConfigResult, err := configuration.DeployQuayConfiguration(metaObject)
@@ -197,28 +284,26 @@ func (r *ReconcileQuayEcosystem) Reconcile(request reconcile.Request) (reconcile
 			logging.Log.Error(err, "Failed to Setup Quay")
 			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemQuaySetupFailure, err)
 		}
-		//Setup security scanner key
-		err = r.quaySetupManager.SetupSecurityScannerKey(quaySetupInstance, &quayConfiguration)
+		// Clair v4 authenticates Quay with a pre-shared key instead of a security scanner key
+		if resources.IsClairEnabled(quayConfiguration.QuayEcosystem) && !resources.IsClairV4(quayConfiguration.QuayEcosystem) {
 
-		if err != nil {
-			logging.Log.Error(err, "Failed to Setup security scanner key")
-			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemQuaySetupFailure, err)
-		}
+			//Setup security scanner key
+			err = r.quaySetupManager.SetupSecurityScannerKey(quaySetupInstance, &quayConfiguration)
 
-		//Add security scanner key to secret
-		_, err = configuration.ManageSecurityScannerKey(metaObject)
+			if err != nil {
+				logging.Log.Error(err, "Failed to Setup security scanner key")
+				return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemQuaySetupFailure, err)
+			}
 
-		if err != nil {
-			logging.Log.Error(err, "Failed to add security scanner key to secret")
-			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemQuaySetupFailure, err)
-		}
+			//Add security scanner key to secret
+			_, err = configuration.ManageSecurityScannerKey(metaObject)
 
-		//Generate clair config to secret
-		_, err = configuration.ManageClairConfig(metaObject)
+			if err != nil {
+				logging.Log.Error(err, "Failed to add security scanner key to secret")
+				return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemQuaySetupFailure, err)
+			}
 
-		if err != nil {
-			logging.Log.Error(err, "Failed to create clair config")
-			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemQuaySetupFailure, err)
+			setup.RecordSecurityScannerKey(&quayConfiguration)
 		}
 
 		// Update flags when setup is completed
@@ -237,15 +322,211 @@ func (r *ReconcileQuayEcosystem) Reconcile(request reconcile.Request) (reconcile
 
 	}
 
-	deployClairResult, err := configuration.DeployClair(metaObject)
-	if err != nil {
-		r.reconcilerBase.GetRecorder().Event(quayConfiguration.QuayEcosystem, "Warning", "Failed to Deploy Clair", err.Error())
-		return reconcile.Result{}, err
+	if quayConfiguration.QuayEcosystem.Status.SetupComplete {
+
+		// Resolve the database servers and hostnames referenced by the Quay and Clair configuration
+		err = r.quaySetupManager.PrepareForSetup(r.reconcilerBase.GetClient(), &quayConfiguration)
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to prepare Quay and Clair configuration")
+			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+		}
+
+		// Enable or disable security scanning when Clair has been enabled or disabled after setup
+		err = configuration.ManageSecurityScannerConfig(metaObject)
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to update security scanner configuration")
+			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+		}
+
+		// Follow changes to the preferred and replicated registry backends after setup
+		err = configuration.ManageStorageReplicationConfig(metaObject)
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to update storage replication configuration")
+			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+		}
+
+		if revokeSecurityScannerKey {
+
+			quaySetupInstance, err := r.quaySetupManager.NewQuaySetupInstance(&quayConfiguration)
+
+			if err != nil {
+				logging.Log.Error(err, "Failed to obtain QuaySetupInstance")
+				return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+			}
+
+			err = r.quaySetupManager.RevokeSecurityScannerKeys(quaySetupInstance, &quayConfiguration)
+
+			if err != nil {
+				logging.Log.Error(err, "Failed to revoke security scanner keys")
+				return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+			}
+
+			if err := r.reconcilerBase.GetClient().Status().Update(context.TODO(), quayConfiguration.QuayEcosystem); err != nil {
+				logging.Log.Error(err, "Failed to update QuayEcosystem status after revoking security scanner keys")
+				return reconcile.Result{}, err
+			}
+
+			r.reconcilerBase.GetRecorder().Event(quayConfiguration.QuayEcosystem, "Normal", "SecurityScannerKeyRevoked", "Revoked security scanner keys no longer in use")
+
+			// The config app is only kept for as long as the revocation requires it
+			quayConfiguration.DeployQuayConfiguration = deployQuayConfiguration
+		}
 	}
 
-	if deployClairResult != nil {
-		r.reconcilerBase.GetRecorder().Event(quayConfiguration.QuayEcosystem, "Warning", "Failed to Deploy Clair", "Failed to Deploy Clair")
-		return *deployClairResult, nil
+	if resources.IsClairEnabled(quayConfiguration.QuayEcosystem) {
+
+		// The Clair configuration references the Quay setup and is therefore generated once setup has completed
+		if quayConfiguration.QuayEcosystem.Status.SetupComplete {
+
+			// Clair v2 enabled after setup requires a new security scanner key. The key is recorded in the status only
+			// once its private key and the Clair configuration referencing it have been stored
+			if createSecurityScannerKey {
+
+				quaySetupInstance, err := r.quaySetupManager.NewQuaySetupInstance(&quayConfiguration)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to obtain QuaySetupInstance")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				err = r.quaySetupManager.SetupSecurityScannerKey(quaySetupInstance, &quayConfiguration)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to create security scanner key")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				_, err = configuration.ManageSecurityScannerKey(metaObject)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to add security scanner key to secret")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+			}
+
+			//Generate clair config to secret
+			_, err = configuration.ManageClairConfig(metaObject)
+
+			if err != nil {
+				logging.Log.Error(err, "Failed to create clair config")
+				return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+			}
+
+			if createSecurityScannerKey {
+
+				setup.RecordSecurityScannerKey(&quayConfiguration)
+
+				if err := r.reconcilerBase.GetClient().Status().Update(context.TODO(), quayConfiguration.QuayEcosystem); err != nil {
+					logging.Log.Error(err, "Failed to update QuayEcosystem status after creating security scanner key")
+					return reconcile.Result{}, err
+				}
+
+				r.reconcilerBase.GetRecorder().Event(quayConfiguration.QuayEcosystem, "Normal", "SecurityScannerKeyCreated", fmt.Sprintf("Created security scanner key %s", quayConfiguration.SecurityScannerKeyKid))
+
+				// The config app is only kept for as long as the key creation requires it
+				quayConfiguration.DeployQuayConfiguration = deployQuayConfiguration
+			}
+
+			// Replace the security scanner key before it expires. As with a created key, the rotation is recorded in the status
+			// only once the new private key and the Clair configuration referencing it have been stored
+			if manageSecurityScannerKey && setup.IsSecurityScannerKeyRotationDue(quayConfiguration.QuayEcosystem, time.Now()) {
+
+				quaySetupInstance, err := r.quaySetupManager.NewQuaySetupInstance(&quayConfiguration)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to obtain QuaySetupInstance")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				err = r.quaySetupManager.RotateSecurityScannerKey(quaySetupInstance, &quayConfiguration)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to rotate security scanner key")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				_, err = configuration.ManageSecurityScannerKey(metaObject)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to add security scanner key to secret")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				_, err = configuration.ManageClairConfig(metaObject)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to create clair config")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				setup.RecordSecurityScannerKey(&quayConfiguration)
+
+				if err := r.reconcilerBase.GetClient().Status().Update(context.TODO(), quayConfiguration.QuayEcosystem); err != nil {
+					logging.Log.Error(err, "Failed to update QuayEcosystem status after rotating security scanner key")
+					return reconcile.Result{}, err
+				}
+
+				r.reconcilerBase.GetRecorder().Event(quayConfiguration.QuayEcosystem, "Normal", "SecurityScannerKeyRotated", fmt.Sprintf("Rotated security scanner key to %s", quayConfiguration.SecurityScannerKeyKid))
+			}
+		}
+
+		deployClairResult, err := configuration.DeployClair(metaObject)
+		if err != nil {
+			r.reconcilerBase.GetRecorder().Event(quayConfiguration.QuayEcosystem, "Warning", "Failed to Deploy Clair", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		if deployClairResult != nil {
+			r.reconcilerBase.GetRecorder().Event(quayConfiguration.QuayEcosystem, "Warning", "Failed to Deploy Clair", "Failed to Deploy Clair")
+			return *deployClairResult, nil
+		}
+
+		// Keys replaced by a rotation are deleted once Clair has been rolled out with the new key
+		if manageSecurityScannerKey && setup.HasPreviousSecurityScannerKeys(quayConfiguration.QuayEcosystem) {
+
+			clairRolledOut, err := configuration.IsClairRolledOut()
+
+			if err != nil {
+				logging.Log.Error(err, "Failed to determine the Clair rollout status")
+				return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+			}
+
+			// Rolling updates keep Clair ready, so the rollout is polled rather than awaited through the Deployment watch
+			clairRolloutPending = !clairRolledOut
+
+			if clairRolledOut {
+
+				quaySetupInstance, err := r.quaySetupManager.NewQuaySetupInstance(&quayConfiguration)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to obtain QuaySetupInstance")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				err = r.quaySetupManager.DeletePreviousSecurityScannerKeys(quaySetupInstance, &quayConfiguration)
+
+				if err != nil {
+					logging.Log.Error(err, "Failed to delete previous security scanner keys")
+					return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+				}
+
+				if err := r.reconcilerBase.GetClient().Status().Update(context.TODO(), quayConfiguration.QuayEcosystem); err != nil {
+					logging.Log.Error(err, "Failed to update QuayEcosystem status after deleting previous security scanner keys")
+					return reconcile.Result{}, err
+				}
+
+				// The config app is only kept for as long as the rotation requires it
+				quayConfiguration.DeployQuayConfiguration = deployQuayConfiguration
+			}
+		}
+	} else {
+
+		if err := configuration.RemoveClairResources(metaObject); err != nil {
+			return r.manageError(quayConfiguration.QuayEcosystem, redhatcopv1alpha1.QuayEcosystemProvisioningFailure, err)
+		}
 	}
 
 	deployQuayResult, err := configuration.DeployQuay(metaObject)
@@ -274,8 +555,40 @@ func (r *ReconcileQuayEcosystem) Reconcile(request reconcile.Request) (reconcile
 
 	}
 
-	return reconcile.Result{}, nil
+	// Requeue in time to replace credentials before they expire
+	var renewalTimes []time.Time
+
+	if resources.IsClairEnabled(quayConfiguration.QuayEcosystem) && !resources.IsClairV4(quayConfiguration.QuayEcosystem) {
+		if rotationTime, expires := setup.GetSecurityScannerKeyRotationTime(quayConfiguration.QuayEcosystem); expires {
+			renewalTimes = append(renewalTimes, rotationTime)
+		}
+	}
+
+	if clairRolloutPending {
+		renewalTimes = append(renewalTimes, time.Now().Add(constants.ClairRolloutPollInterval))
+	}
+
+	// Certificates renewed by an issuer are picked up through the watch on their Secret
+	if certificate := quayConfiguration.QuayEcosystem.Status.Certificate; certificate != nil && certificate.SelfSigned {
+		renewalTimes = append(renewalTimes, certificate.NotAfter.Add(-constants.QuaySslCertificateRenewBefore))
+	}
+
+	return getRenewalResult(renewalTimes), nil
+
+}
+
+// getRenewalResult returns a result requeueing the reconciliation at the earliest upcoming renewal
+func getRenewalResult(renewalTimes []time.Time) reconcile.Result {
+
+	var requeueAfter time.Duration
+
+	for _, renewalTime := range renewalTimes {
+		if until := time.Until(renewalTime); until > 0 && (requeueAfter == 0 || until < requeueAfter) {
+			requeueAfter = until
+		}
+	}
 
+	return reconcile.Result{RequeueAfter: requeueAfter}
 }
 
 func (r *ReconcileQuayEcosystem) manageSuccess(instance *redhatcopv1alpha1.QuayEcosystem, conditionType redhatcopv1alpha1.QuayEcosystemConditionType, reason string, message string) (reconcile.Result, error) {
@@ -345,3 +658,123 @@ func (r *ReconcileQuayEcosystem) manageError(instance *redhatcopv1alpha1.QuayEco
 		Requeue:      true,
 	}, nil
 }
+
+// quayEcosystemChangedPredicate fires an update event when the spec or finalizers of a QuayEcosystem change, or when one of the annotations used to trigger operations changes
+type quayEcosystemChangedPredicate struct {
+	util.ResourceGenerationOrFinalizerChangedPredicate
+}
+
+// Update implements the UpdateEvent filter for QuayEcosystem resources
+func (p quayEcosystemChangedPredicate) Update(e event.UpdateEvent) bool {
+
+	if p.ResourceGenerationOrFinalizerChangedPredicate.Update(e) {
+		return true
+	}
+
+	if e.MetaOld == nil || e.MetaNew == nil {
+		return false
+	}
+
+	for _, annotation := range constants.OperationAnnotations {
+		if e.MetaOld.GetAnnotations()[annotation] != e.MetaNew.GetAnnotations()[annotation] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deploymentReadinessChangedPredicate fires an update event only when a Deployment becomes ready or unready, or reports a
+// different failure, so that rollouts do not trigger a reconciliation for every status update
+type deploymentReadinessChangedPredicate struct {
+	predicate.Funcs
+}
+
+// Update implements the UpdateEvent filter for Deployments owned by a QuayEcosystem
+func (p deploymentReadinessChangedPredicate) Update(e event.UpdateEvent) bool {
+
+	oldDeployment, ok := e.ObjectOld.(*appsv1.Deployment)
+	if !ok {
+		return false
+	}
+
+	newDeployment, ok := e.ObjectNew.(*appsv1.Deployment)
+	if !ok {
+		return false
+	}
+
+	oldComponentStatus := provisioning.GetComponentStatus(oldDeployment)
+	newComponentStatus := provisioning.GetComponentStatus(newDeployment)
+
+	return provisioning.IsComponentReady(oldComponentStatus) != provisioning.IsComponentReady(newComponentStatus) || oldComponentStatus.LastError != newComponentStatus.LastError
+}
+
+// issuedCertificateSecretChangedPredicate fires an event for Secrets populated by cert-manager when they are created,
+// deleted or updated. Resyncs, which do not change the resource version, are ignored
+type issuedCertificateSecretChangedPredicate struct {
+	predicate.Funcs
+}
+
+// Create implements the CreateEvent filter for Secrets populated by cert-manager
+func (p issuedCertificateSecretChangedPredicate) Create(e event.CreateEvent) bool {
+	return isIssuedCertificateSecret(e.Meta)
+}
+
+// Update implements the UpdateEvent filter for Secrets populated by cert-manager
+func (p issuedCertificateSecretChangedPredicate) Update(e event.UpdateEvent) bool {
+
+	if e.MetaOld == nil || e.MetaNew == nil {
+		return false
+	}
+
+	return isIssuedCertificateSecret(e.MetaNew) && e.MetaOld.GetResourceVersion() != e.MetaNew.GetResourceVersion()
+}
+
+// Delete implements the DeleteEvent filter for Secrets populated by cert-manager
+func (p issuedCertificateSecretChangedPredicate) Delete(e event.DeleteEvent) bool {
+	return isIssuedCertificateSecret(e.Meta)
+}
+
+// Generic implements the GenericEvent filter for Secrets populated by cert-manager
+func (p issuedCertificateSecretChangedPredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
+
+func isIssuedCertificateSecret(meta metav1.Object) bool {
+
+	if meta == nil {
+		return false
+	}
+
+	_, found := meta.GetAnnotations()[constants.CertificateNameAnnotation]
+
+	return found
+}
+
+// issuedCertificateSecretMapper maps a Secret populated by cert-manager to the QuayEcosystems that requested its Certificate
+type issuedCertificateSecretMapper struct {
+	client client.Client
+}
+
+// Map implements the Mapper for Secrets populated by cert-manager
+func (m issuedCertificateSecretMapper) Map(obj handler.MapObject) []reconcile.Request {
+
+	requests := []reconcile.Request{}
+
+	quayEcosystems := &redhatcopv1alpha1.QuayEcosystemList{}
+
+	if err := m.client.List(context.TODO(), &client.ListOptions{Namespace: obj.Meta.GetNamespace()}, quayEcosystems); err != nil {
+		logging.Log.Error(err, "Failed to list QuayEcosystems", "Namespace", obj.Meta.GetNamespace())
+		return requests
+	}
+
+	certificateName := obj.Meta.GetAnnotations()[constants.CertificateNameAnnotation]
+
+	for _, quayEcosystem := range quayEcosystems.Items {
+		if quayEcosystem.Spec.Quay.CertificateIssuer != nil && resources.GetQuayCertificateName(&quayEcosystem) == certificateName {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: quayEcosystem.Name, Namespace: quayEcosystem.Namespace}})
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,186 @@
+package quayecosystem
+
+import (
+	"reflect"
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDeploymentReadinessChangedPredicate(t *testing.T) {
+
+	replicas := int32(2)
+
+	deployment := func(readyReplicas int32, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas: readyReplicas,
+				Conditions:    conditions,
+			},
+		}
+	}
+
+	replicaFailure := appsv1.DeploymentCondition{
+		Type:    appsv1.DeploymentReplicaFailure,
+		Status:  corev1.ConditionTrue,
+		Message: "exceeded quota",
+	}
+
+	cases := []struct {
+		oldDeployment *appsv1.Deployment
+		newDeployment *appsv1.Deployment
+		expected      bool
+	}{
+		{
+			// Rolling update progressing without a change in readiness
+			oldDeployment: deployment(0),
+			newDeployment: deployment(1),
+			expected:      false,
+		},
+		{
+			oldDeployment: deployment(1),
+			newDeployment: deployment(2),
+			expected:      true,
+		},
+		{
+			oldDeployment: deployment(2),
+			newDeployment: deployment(1),
+			expected:      true,
+		},
+		{
+			oldDeployment: deployment(1),
+			newDeployment: deployment(1, replicaFailure),
+			expected:      true,
+		},
+	}
+
+	for i, c := range cases {
+		result := deploymentReadinessChangedPredicate{}.Update(event.UpdateEvent{ObjectOld: c.oldDeployment, ObjectNew: c.newDeployment})
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestIssuedCertificateSecretChangedPredicate(t *testing.T) {
+
+	secret := func(resourceVersion string, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:     annotations,
+				ResourceVersion: resourceVersion,
+			},
+		}
+	}
+
+	issued := map[string]string{constants.CertificateNameAnnotation: "quay-quay-tls"}
+
+	cases := []struct {
+		oldSecret *corev1.Secret
+		newSecret *corev1.Secret
+		expected  bool
+	}{
+		{
+			oldSecret: secret("1", nil),
+			newSecret: secret("2", nil),
+			expected:  false,
+		},
+		{
+			// Resync of an issued secret
+			oldSecret: secret("1", issued),
+			newSecret: secret("1", issued),
+			expected:  false,
+		},
+		{
+			oldSecret: secret("1", issued),
+			newSecret: secret("2", issued),
+			expected:  true,
+		},
+	}
+
+	for i, c := range cases {
+		result := issuedCertificateSecretChangedPredicate{}.Update(event.UpdateEvent{MetaOld: c.oldSecret, ObjectOld: c.oldSecret, MetaNew: c.newSecret, ObjectNew: c.newSecret})
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestIssuedCertificateSecretMapper(t *testing.T) {
+
+	quayEcosystem := func(name string, certificateIssuer *redhatcopv1alpha1.CertificateIssuer) *redhatcopv1alpha1.QuayEcosystem {
+		return &redhatcopv1alpha1.QuayEcosystem{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "quay-enterprise",
+			},
+			Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+				Quay: redhatcopv1alpha1.Quay{
+					CertificateIssuer: certificateIssuer,
+				},
+			},
+		}
+	}
+
+	s := runtime.NewScheme()
+	scheme.AddToScheme(s)
+	redhatcopv1alpha1.SchemeBuilder.AddToScheme(s)
+
+	k8sclient := fake.NewFakeClientWithScheme(s,
+		quayEcosystem("quay", &redhatcopv1alpha1.CertificateIssuer{Name: "letsencrypt"}),
+		quayEcosystem("registry", &redhatcopv1alpha1.CertificateIssuer{Name: "letsencrypt"}),
+		quayEcosystem("self-signed", nil),
+	)
+
+	cases := []struct {
+		certificateName string
+		expected        []reconcile.Request
+	}{
+		{
+			certificateName: "quay-quay-tls",
+			expected:        []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "quay", Namespace: "quay-enterprise"}}},
+		},
+		{
+			// QuayEcosystems without an issuer do not use the secret
+			certificateName: "self-signed-quay-tls",
+			expected:        []reconcile.Request{},
+		},
+		{
+			certificateName: "unrelated",
+			expected:        []reconcile.Request{},
+		},
+	}
+
+	for i, c := range cases {
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{constants.CertificateNameAnnotation: c.certificateName},
+				Name:        c.certificateName,
+				Namespace:   "quay-enterprise",
+			},
+		}
+
+		result := issuedCertificateSecretMapper{client: k8sclient}.Map(handler.MapObject{Meta: secret, Object: secret})
+
+		if !reflect.DeepEqual(c.expected, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package resources
+
+import (
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GetCertificateDefinition returns a cert-manager Certificate for the provided hostnames whose key pair is stored in a Secret of the same name
+func GetCertificateDefinition(meta metav1.ObjectMeta, certificateIssuer redhatcopv1alpha1.CertificateIssuer, dnsNames []string) *unstructured.Unstructured {
+
+	certificateDNSNames := []interface{}{}
+
+	for _, dnsName := range dnsNames {
+		certificateDNSNames = append(certificateDNSNames, dnsName)
+	}
+
+	certificate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"secretName":  meta.Name,
+				"dnsNames":    certificateDNSNames,
+				"renewBefore": constants.QuaySslCertificateRenewBefore.String(),
+				"issuerRef": map[string]interface{}{
+					"name":  certificateIssuer.Name,
+					"kind":  string(utils.CheckValue(certificateIssuer.Kind, redhatcopv1alpha1.IssuerCertificateIssuerKind).(redhatcopv1alpha1.CertificateIssuerKind)),
+					"group": constants.CertificateIssuerGroup,
+				},
+			},
+		},
+	}
+
+	certificate.SetAPIVersion(constants.CertificateAPIVersion)
+	certificate.SetKind(constants.CertificateKind)
+	certificate.SetName(meta.Name)
+	certificate.SetNamespace(meta.Namespace)
+	certificate.SetLabels(meta.Labels)
+
+	return certificate
+
+}
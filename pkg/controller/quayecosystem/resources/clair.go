@@ -0,0 +1,346 @@
+package resources
+
+import (
+	"fmt"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+)
+
+// ClairConfigFile represents the configuration file consumed by Clair and the embedded JWT proxy
+type ClairConfigFile struct {
+	Clair    ClairConfig    `json:"clair"`
+	JWTProxy JWTProxyConfig `json:"jwtproxy"`
+}
+
+// ClairConfig represents the Clair section of the configuration file
+type ClairConfig struct {
+	API      ClairAPIConfig      `json:"api"`
+	Database ClairDatabaseConfig `json:"database"`
+	Notifier ClairNotifierConfig `json:"notifier"`
+	Updater  ClairUpdaterConfig  `json:"updater"`
+}
+
+// ClairAPIConfig represents the configuration of the Clair API
+type ClairAPIConfig struct {
+	HealthPort    int32  `json:"healthport"`
+	PaginationKey string `json:"paginationkey"`
+	Port          int32  `json:"port"`
+	Timeout       string `json:"timeout"`
+}
+
+// ClairDatabaseConfig represents the configuration of the Clair database
+type ClairDatabaseConfig struct {
+	Options ClairDatabaseOptions `json:"options"`
+	Type    string               `json:"type"`
+}
+
+// ClairDatabaseOptions represents the connection options of the Clair database
+type ClairDatabaseOptions struct {
+	CacheSize int32  `json:"cachesize"`
+	Source    string `json:"source"`
+}
+
+// ClairNotifierConfig represents the configuration of the Clair notifier
+type ClairNotifierConfig struct {
+	Attempts         int32                   `json:"attempts"`
+	HTTP             ClairNotifierHTTPConfig `json:"http"`
+	RenotifyInterval string                  `json:"renotifyinterval"`
+}
+
+// ClairNotifierHTTPConfig represents the HTTP endpoint notifications are delivered to
+type ClairNotifierHTTPConfig struct {
+	Endpoint string `json:"endpoint"`
+	Proxy    string `json:"proxy"`
+}
+
+// ClairUpdaterConfig represents the configuration of the Clair vulnerability updater
+type ClairUpdaterConfig struct {
+	EnabledUpdaters []string `json:"enabledupdaters,omitempty"`
+	Interval        string   `json:"interval"`
+}
+
+// JWTProxyConfig represents the JWT proxy section of the configuration file
+type JWTProxyConfig struct {
+	SignerProxy     JWTProxySignerProxyConfig     `json:"signer_proxy"`
+	VerifierProxies []JWTProxyVerifierProxyConfig `json:"verifier_proxies"`
+}
+
+// JWTProxySignerProxyConfig represents the proxy signing requests made by Clair to Quay
+type JWTProxySignerProxyConfig struct {
+	CACrtFile  string               `json:"ca_crt_file"`
+	CAKeyFile  string               `json:"ca_key_file"`
+	Enabled    bool                 `json:"enabled"`
+	ListenAddr string               `json:"listen_addr"`
+	Signer     JWTProxySignerConfig `json:"signer"`
+}
+
+// JWTProxySignerConfig represents the signer used by the JWT proxy
+type JWTProxySignerConfig struct {
+	ExpirationTime string                   `json:"expiration_time"`
+	Issuer         string                   `json:"issuer"`
+	MaxSkew        string                   `json:"max_skew"`
+	NonceLength    int32                    `json:"nonce_length"`
+	PrivateKey     JWTProxyPrivateKeyConfig `json:"private_key"`
+}
+
+// JWTProxyPrivateKeyConfig represents the private key used to sign requests
+type JWTProxyPrivateKeyConfig struct {
+	Options JWTProxyPrivateKeyOptions `json:"options"`
+	Type    string                    `json:"type"`
+}
+
+// JWTProxyPrivateKeyOptions represents the location and identifier of the private key
+type JWTProxyPrivateKeyOptions struct {
+	KeyID          string `json:"key_id"`
+	PrivateKeyPath string `json:"private_key_path"`
+}
+
+// JWTProxyVerifierProxyConfig represents the proxy verifying requests made by Quay to Clair
+type JWTProxyVerifierProxyConfig struct {
+	Enabled    bool                   `json:"enabled"`
+	ListenAddr string                 `json:"listen_addr"`
+	Verifier   JWTProxyVerifierConfig `json:"verifier"`
+}
+
+// JWTProxyVerifierConfig represents the verifier used by the JWT proxy
+type JWTProxyVerifierConfig struct {
+	Audience  string                  `json:"audience"`
+	KeyServer JWTProxyKeyServerConfig `json:"key_server"`
+	Upstream  string                  `json:"upstream"`
+}
+
+// JWTProxyKeyServerConfig represents the key server used to validate signed requests
+type JWTProxyKeyServerConfig struct {
+	Options JWTProxyKeyServerOptions `json:"options"`
+	Type    string                   `json:"type"`
+}
+
+// JWTProxyKeyServerOptions represents the location of the key server
+type JWTProxyKeyServerOptions struct {
+	Registry string `json:"registry"`
+}
+
+// GetClairConfigFile builds the Clair configuration file from the QuayEcosystem
+func GetClairConfigFile(quayConfiguration *QuayConfiguration, clairDatabase DatabaseConfig) ClairConfigFile {
+
+	clair := quayConfiguration.QuayEcosystem.Spec.Clair
+
+	return ClairConfigFile{
+		Clair: ClairConfig{
+			API: ClairAPIConfig{
+				HealthPort:    6061,
+				PaginationKey: quayConfiguration.ClairPaginationKey,
+				Port:          6062,
+				Timeout:       utils.CheckValue(clair.APITimeout, constants.ClairAPITimeout).(string),
+			},
+			Database: ClairDatabaseConfig{
+				Options: ClairDatabaseOptions{
+					CacheSize: *utils.CheckValue(clair.DatabaseCacheSize, &constants.ClairDatabaseCacheSize).(*int32),
+					Source:    fmt.Sprintf("postgresql://%s:%s@%s:5432/%s?sslmode=disable", clairDatabase.Username, clairDatabase.Password, clairDatabase.Server, clairDatabase.Database),
+				},
+				Type: "pgsql",
+			},
+			Notifier: ClairNotifierConfig{
+				Attempts: *utils.CheckValue(clair.NotifierAttempts, &constants.ClairNotifierAttempts).(*int32),
+				HTTP: ClairNotifierHTTPConfig{
+					Endpoint: fmt.Sprintf("https://%s/secscan/notify", quayConfiguration.QuayHostname),
+					Proxy:    "http://localhost:6063",
+				},
+				RenotifyInterval: utils.CheckValue(clair.NotifierRenotifyInterval, constants.ClairNotifierRenotifyInterval).(string),
+			},
+			Updater: ClairUpdaterConfig{
+				EnabledUpdaters: clair.EnabledUpdaters,
+				Interval:        utils.CheckValue(clair.UpdateInterval, constants.ClairUpdateInterval).(string),
+			},
+		},
+		JWTProxy: JWTProxyConfig{
+			SignerProxy: JWTProxySignerProxyConfig{
+				// Generated internally, do not change.
+				CACrtFile:  "/certificates/mitm.crt",
+				CAKeyFile:  "/certificates/mitm.key",
+				Enabled:    true,
+				ListenAddr: ":6063",
+				Signer: JWTProxySignerConfig{
+					ExpirationTime: "5m",
+					Issuer:         "security_scanner",
+					MaxSkew:        "1m",
+					NonceLength:    32,
+					PrivateKey: JWTProxyPrivateKeyConfig{
+						Options: JWTProxyPrivateKeyOptions{
+							KeyID:          quayConfiguration.SecurityScannerKeyKid,
+							PrivateKeyPath: "/clair/config/security_scanner.pem",
+						},
+						Type: "preshared",
+					},
+				},
+			},
+			VerifierProxies: []JWTProxyVerifierProxyConfig{
+				{
+					Enabled:    true,
+					ListenAddr: ":6060",
+					Verifier: JWTProxyVerifierConfig{
+						Audience: fmt.Sprintf("http://%s", quayConfiguration.ClairHostname),
+						KeyServer: JWTProxyKeyServerConfig{
+							Options: JWTProxyKeyServerOptions{
+								Registry: fmt.Sprintf("https://%s/keys/", quayConfiguration.QuayHostname),
+							},
+							Type: "keyregistry",
+						},
+						Upstream: "http://localhost:6062",
+					},
+				},
+			},
+		},
+	}
+}
+
+// ClairV4ConfigFile represents the configuration file consumed by Clair v4
+type ClairV4ConfigFile struct {
+	Auth              ClairV4AuthConfig      `json:"auth"`
+	HTTPListenAddr    string                 `json:"http_listen_addr"`
+	Indexer           ClairV4IndexerConfig   `json:"indexer"`
+	IntrospectionAddr string                 `json:"introspection_addr"`
+	LogLevel          string                 `json:"log_level"`
+	Matcher           ClairV4MatcherConfig   `json:"matcher"`
+	Notifier          ClairV4NotifierConfig  `json:"notifier"`
+	Updaters          *ClairV4UpdatersConfig `json:"updaters,omitempty"`
+}
+
+// ClairV4AuthConfig represents how requests made to Clair v4 are authenticated
+type ClairV4AuthConfig struct {
+	PSK ClairV4PSKConfig `json:"psk"`
+}
+
+// ClairV4PSKConfig represents the pre-shared key used to sign requests made to Clair v4
+type ClairV4PSKConfig struct {
+	Issuer []string `json:"iss"`
+	Key    string   `json:"key"`
+}
+
+// ClairV4IndexerConfig represents the configuration of the Clair v4 indexer
+type ClairV4IndexerConfig struct {
+	ConnString string `json:"connstring"`
+	Migrations bool   `json:"migrations"`
+}
+
+// ClairV4MatcherConfig represents the configuration of the Clair v4 matcher
+type ClairV4MatcherConfig struct {
+	ConnString  string `json:"connstring"`
+	IndexerAddr string `json:"indexer_addr,omitempty"`
+	Migrations  bool   `json:"migrations"`
+	Period      string `json:"period"`
+}
+
+// ClairV4NotifierConfig represents the configuration of the Clair v4 notifier
+type ClairV4NotifierConfig struct {
+	ConnString  string               `json:"connstring"`
+	IndexerAddr string               `json:"indexer_addr,omitempty"`
+	MatcherAddr string               `json:"matcher_addr,omitempty"`
+	Migrations  bool                 `json:"migrations"`
+	Webhook     ClairV4WebhookConfig `json:"webhook"`
+}
+
+// ClairV4WebhookConfig represents the webhook Clair v4 delivers notifications to
+type ClairV4WebhookConfig struct {
+	Callback string `json:"callback"`
+	Target   string `json:"target"`
+}
+
+// ClairV4UpdatersConfig represents the sets of vulnerability updaters run by Clair v4
+type ClairV4UpdatersConfig struct {
+	Sets []string `json:"sets"`
+}
+
+// GetClairV4ConfigFile builds the Clair v4 configuration file from the QuayEcosystem
+func GetClairV4ConfigFile(quayConfiguration *QuayConfiguration, clairDatabase DatabaseConfig) ClairV4ConfigFile {
+
+	clair := quayConfiguration.QuayEcosystem.Spec.Clair
+
+	connString := fmt.Sprintf("host=%s port=5432 dbname=%s user=%s password=%s sslmode=disable", clairDatabase.Server, clairDatabase.Database, clairDatabase.Username, clairDatabase.Password)
+
+	clairV4ConfigFile := ClairV4ConfigFile{
+		Auth: ClairV4AuthConfig{
+			PSK: ClairV4PSKConfig{
+				Issuer: []string{"quay", "clairctl"},
+				Key:    quayConfiguration.ClairPSK,
+			},
+		},
+		HTTPListenAddr: ":6060",
+		Indexer: ClairV4IndexerConfig{
+			ConnString: connString,
+			Migrations: true,
+		},
+		IntrospectionAddr: ":6061",
+		LogLevel:          "info",
+		Matcher: ClairV4MatcherConfig{
+			ConnString: connString,
+			Migrations: true,
+			Period:     utils.CheckValue(clair.UpdateInterval, constants.ClairUpdateInterval).(string),
+		},
+		Notifier: ClairV4NotifierConfig{
+			ConnString: connString,
+			Migrations: true,
+			Webhook: ClairV4WebhookConfig{
+				Callback: fmt.Sprintf("http://%s/notifier/api/v1/notifications", quayConfiguration.ClairHostname),
+				Target:   fmt.Sprintf("https://%s/secscan/notification", quayConfiguration.QuayHostname),
+			},
+		},
+	}
+
+	if len(clair.EnabledUpdaters) > 0 {
+		clairV4ConfigFile.Updaters = &ClairV4UpdatersConfig{
+			Sets: clair.EnabledUpdaters,
+		}
+	}
+
+	// In split mode the matcher and notifier reach the other components through their services
+	if len(GetClairComponents(quayConfiguration.QuayEcosystem)) > 1 {
+
+		indexerAddr := fmt.Sprintf("http://%s:6060", GetClairComponentResourcesName(quayConfiguration.QuayEcosystem, constants.LabelComponentClairIndexerValue))
+		matcherAddr := fmt.Sprintf("http://%s:6060", GetClairComponentResourcesName(quayConfiguration.QuayEcosystem, constants.LabelComponentClairMatcherValue))
+
+		clairV4ConfigFile.Matcher.IndexerAddr = indexerAddr
+		clairV4ConfigFile.Notifier.IndexerAddr = indexerAddr
+		clairV4ConfigFile.Notifier.MatcherAddr = matcherAddr
+	}
+
+	return clairV4ConfigFile
+}
+
+// SetSecurityScannerConfig sets the security scanner settings of the Quay configuration according to the deployed
+// version of Clair, or disables security scanning when Clair is disabled
+func SetSecurityScannerConfig(config map[string]interface{}, quayConfiguration *QuayConfiguration) {
+
+	if IsClairEnabled(quayConfiguration.QuayEcosystem) && IsClairV4(quayConfiguration.QuayEcosystem) {
+		delete(config, "SECURITY_SCANNER_ENDPOINT")
+		delete(config, "SECURITY_SCANNER_ISSUER_NAME")
+		config["SECURITY_SCANNER_V4_ENDPOINT"] = fmt.Sprintf("http://%s", quayConfiguration.ClairHostname)
+		config["SECURITY_SCANNER_V4_PSK"] = quayConfiguration.ClairPSK
+		config["FEATURE_SECURITY_SCANNER"] = true
+	} else if IsClairEnabled(quayConfiguration.QuayEcosystem) {
+		//TODO Change to the correct clair endpoint
+		config["SECURITY_SCANNER_ENDPOINT"] = fmt.Sprintf("http://%s", quayConfiguration.ClairHostname)
+		config["SECURITY_SCANNER_ISSUER_NAME"] = constants.SecurityScannerKeyService
+		delete(config, "SECURITY_SCANNER_V4_ENDPOINT")
+		delete(config, "SECURITY_SCANNER_V4_PSK")
+		config["FEATURE_SECURITY_SCANNER"] = true
+	} else {
+		delete(config, "SECURITY_SCANNER_ENDPOINT")
+		delete(config, "SECURITY_SCANNER_ISSUER_NAME")
+		delete(config, "SECURITY_SCANNER_V4_ENDPOINT")
+		delete(config, "SECURITY_SCANNER_V4_PSK")
+		config["FEATURE_SECURITY_SCANNER"] = false
+	}
+}
+
+// GetSecurityScannerMode returns how security scanning is configured in Quay
+func GetSecurityScannerMode(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
+
+	if !IsClairEnabled(quayEcosystem) {
+		return "disabled"
+	}
+
+	return string(utils.CheckValue(quayEcosystem.Spec.Clair.Version, redhatcopv1alpha1.V2ClairVersion).(redhatcopv1alpha1.ClairVersion))
+}
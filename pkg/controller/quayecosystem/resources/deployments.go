@@ -1,6 +1,13 @@
 package resources
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
 
@@ -33,6 +40,8 @@ func GetRedisDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Qua
 		}
 	}
 
+	applyComponentPodSettings(&redisDeploymentPodSpec, quayConfiguration.QuayEcosystem.Spec.Redis.PodSettings)
+
 	redisReplicas := utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Redis.Replicas, &constants.RedisReplicas)
 
 	redisDeployment := &appsv1.Deployment{
@@ -134,6 +143,9 @@ func GetQuayConfigDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration
 			}}},
 	}
 
+	// Extra CA certificates are loaded from the Quay configuration directory
+	quayDeploymentPodSpec.Volumes[0].Projected.Sources = append(quayDeploymentPodSpec.Volumes[0].Projected.Sources, getQuayExtraCACertsProjections(quayConfiguration)...)
+
 	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.ImagePullSecretName) {
 		quayDeploymentPodSpec.ImagePullSecrets = []corev1.LocalObjectReference{corev1.LocalObjectReference{
 			Name: quayConfiguration.QuayEcosystem.Spec.Quay.ImagePullSecretName,
@@ -141,6 +153,8 @@ func GetQuayConfigDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration
 		}
 	}
 
+	applyComponentPodSettings(&quayDeploymentPodSpec, quayConfiguration.QuayEcosystem.Spec.Quay.ConfigPodSettings)
+
 	quayDeployment := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: appsv1.SchemeGroupVersion.String(),
@@ -153,7 +167,8 @@ func GetQuayConfigDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: meta.Labels,
+					Annotations: getCACertificatePodAnnotations(quayConfiguration, quayConfiguration.QuayExtraCACerts),
+					Labels:      meta.Labels,
 				},
 				Spec: quayDeploymentPodSpec,
 			},
@@ -226,6 +241,9 @@ func GetQuayDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Quay
 		}},
 	}
 
+	// Extra CA certificates are loaded from the Quay configuration directory
+	quayDeploymentPodSpec.Volumes[0].Projected.Sources = append(quayDeploymentPodSpec.Volumes[0].Projected.Sources, getQuayExtraCACertsProjections(quayConfiguration)...)
+
 	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.ImagePullSecretName) {
 		quayDeploymentPodSpec.ImagePullSecrets = []corev1.LocalObjectReference{corev1.LocalObjectReference{
 			Name: quayConfiguration.QuayEcosystem.Spec.Quay.ImagePullSecretName,
@@ -267,7 +285,19 @@ func GetQuayDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Quay
 
 	}
 
-	quayReplicas := utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Quay.Replicas, &constants.OneInt)
+	applyComponentPodSettings(&quayDeploymentPodSpec, quayConfiguration.QuayEcosystem.Spec.Quay.PodSettings)
+	applyHighAvailability(&quayDeploymentPodSpec, meta.Labels, quayConfiguration.QuayEcosystem.Spec.HighAvailability)
+
+	quayReplicas := utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Quay.Replicas, &constants.OneInt).(*int32)
+
+	// Replicas are managed by the HorizontalPodAutoscaler
+	if quayConfiguration.QuayEcosystem.Spec.Quay.Autoscaling != nil {
+		quayReplicas = nil
+	}
+
+	// Restart Quay so that enabling or disabling Clair after setup is picked up
+	quayPodAnnotations := getCACertificatePodAnnotations(quayConfiguration, quayConfiguration.QuayExtraCACerts)
+	quayPodAnnotations[constants.SecurityScannerModeAnnotation] = GetSecurityScannerMode(quayConfiguration.QuayEcosystem)
 
 	quayDeployment := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -276,13 +306,14 @@ func GetQuayDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Quay
 		},
 		ObjectMeta: meta,
 		Spec: appsv1.DeploymentSpec{
-			Replicas: quayReplicas.(*int32),
+			Replicas: quayReplicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: meta.Labels,
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: meta.Labels,
+					Annotations: quayPodAnnotations,
+					Labels:      meta.Labels,
 				},
 				Spec: quayDeploymentPodSpec,
 			},
@@ -292,14 +323,62 @@ func GetQuayDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Quay
 	return quayDeployment
 }
 
-func GetClairDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *QuayConfiguration) *appsv1.Deployment {
+// GetQuayStorageReplicationDeploymentDefinition returns a deployment running only the Quay storage replication worker
+func GetQuayStorageReplicationDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *QuayConfiguration) *appsv1.Deployment {
+
+	quayDeployment := GetQuayDeploymentDefinition(meta, quayConfiguration)
+
+	labels := map[string]string{}
+
+	for key, value := range quayDeployment.ObjectMeta.Labels {
+		labels[key] = value
+	}
+
+	BuildQuayStorageReplicationResourceLabels(labels)
+
+	quayDeployment.ObjectMeta.Name = GetQuayStorageReplicationResourcesName(quayConfiguration.QuayEcosystem)
+	quayDeployment.ObjectMeta.Labels = labels
+	quayDeployment.Spec.Replicas = &constants.OneInt
+	quayDeployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: labels,
+	}
+	quayDeployment.Spec.Template.ObjectMeta.Labels = labels
 
-	meta.Name = GetClairResourcesName(quayConfiguration.QuayEcosystem)
-	BuildClairResourceLabels(meta.Labels)
+	// The storage replication worker runs a single replica and is not spread alongside the Quay pods
+	quayDeployment.Spec.Template.Spec.Affinity = quayConfiguration.QuayEcosystem.Spec.Quay.PodSettings.Affinity.DeepCopy()
+
+	storageReplicationContainer := &quayDeployment.Spec.Template.Spec.Containers[0]
+	storageReplicationContainer.Name = constants.QuayContainerStorageReplicationName
+	storageReplicationContainer.Ports = nil
+	storageReplicationContainer.ReadinessProbe = nil
+	storageReplicationContainer.Env = append(storageReplicationContainer.Env, corev1.EnvVar{
+		Name:  constants.QuayServicesEnvironmentVariable,
+		Value: constants.QuayStorageReplicationServiceName,
+	})
+
+	return quayDeployment
+}
+
+// clairV4Modes maps each Clair component to the mode Clair v4 runs in
+var clairV4Modes = map[string]string{
+	constants.LabelComponentClairValue:         "combo",
+	constants.LabelComponentClairIndexerValue:  "indexer",
+	constants.LabelComponentClairMatcherValue:  "matcher",
+	constants.LabelComponentClairNotifierValue: "notifier",
+}
+
+func GetClairDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *QuayConfiguration, clairComponent string) *appsv1.Deployment {
+
+	meta.Name = GetClairComponentResourcesName(quayConfiguration.QuayEcosystem, clairComponent)
+	BuildClairComponentResourceLabels(meta.Labels, clairComponent)
+
+	if IsClairV4(quayConfiguration.QuayEcosystem) {
+		return getClairDeploymentDefinition(meta, quayConfiguration, getClairV4PodSpec(meta.Name, quayConfiguration, clairComponent))
+	}
 
 	clairDeploymentPodSpec := corev1.PodSpec{
 		Containers: []corev1.Container{{
-			Image: constants.ClairImage,
+			Image: quayConfiguration.QuayEcosystem.Spec.Clair.Image,
 			Name:  meta.Name,
 			Ports: []corev1.ContainerPort{{
 				ContainerPort: 6060,
@@ -314,10 +393,6 @@ func GetClairDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Qua
 				Name:      "security-scanner",
 				MountPath: "/clair/config/security_scanner.pem",
 				SubPath:   "security_scanner.pem",
-			}, {
-				Name:      "clair-trust-ca",
-				MountPath: "/etc/pki/ca-trust/source/anchors/ca.crt",
-				SubPath:   "ca.crt",
 			}},
 		}},
 		ServiceAccountName: constants.QuayServiceAccount,
@@ -369,14 +444,199 @@ func GetClairDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Qua
 		}},
 	}
 
-	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.ImagePullSecretName) {
+	clairDeploymentPodSpec.Containers[0].VolumeMounts = append(clairDeploymentPodSpec.Containers[0].VolumeMounts, getClairTrustCAVolumeMounts(quayConfiguration)...)
+
+	return getClairDeploymentDefinition(meta, quayConfiguration, clairDeploymentPodSpec)
+}
+
+func getClairV4PodSpec(name string, quayConfiguration *QuayConfiguration, clairComponent string) corev1.PodSpec {
+
+	clairPodSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{
+				{
+					Name:  "CLAIR_CONF",
+					Value: "/clair/config/config.yaml",
+				},
+				{
+					Name:  "CLAIR_MODE",
+					Value: clairV4Modes[clairComponent],
+				},
+			},
+			Image: quayConfiguration.QuayEcosystem.Spec.Clair.Image,
+			Name:  name,
+			Ports: []corev1.ContainerPort{{
+				ContainerPort: 6060,
+			}, {
+				ContainerPort: 6061,
+			}},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "clair-config",
+				MountPath: "/clair/config/config.yaml",
+				SubPath:   "config.yaml",
+			}},
+		}},
+		ServiceAccountName: constants.QuayServiceAccount,
+		Volumes: []corev1.Volume{
+			getProjectedSecretVolume("clair-config", constants.ClairConfigSecretName),
+			getProjectedSecretVolume("clair-trust-ca", constants.ClairTrustCASecretName),
+		},
+	}
+
+	clairPodSpec.Containers[0].VolumeMounts = append(clairPodSpec.Containers[0].VolumeMounts, getClairTrustCAVolumeMounts(quayConfiguration)...)
+
+	return clairPodSpec
+}
+
+func getProjectedSecretVolume(name string, secretName string) corev1.Volume {
+
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: secretName,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getClairTrustCAVolumeMounts returns the mounts adding the Quay certificate and the extra CA certificates to the Clair trust anchors
+func getClairTrustCAVolumeMounts(quayConfiguration *QuayConfiguration) []corev1.VolumeMount {
+
+	trustCAFileNames := []string{constants.ClairTrustCASecretKey}
+
+	for _, fileName := range getSortedCertificateFileNames(quayConfiguration.ClairExtraCACerts) {
+		trustCAFileNames = append(trustCAFileNames, constants.ClairTrustCAExtraCertPrefix+fileName)
+	}
+
+	volumeMounts := []corev1.VolumeMount{}
+
+	for _, fileName := range trustCAFileNames {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "clair-trust-ca",
+			MountPath: fmt.Sprintf("%s/%s", constants.ClairTrustAnchorsDirectory, fileName),
+			SubPath:   fileName,
+		})
+	}
+
+	return volumeMounts
+}
+
+// getQuayExtraCACertsProjections returns the projections placing the extra CA certificates in the directory of the Quay configuration they are loaded from.
+// Unlike files uploaded through the config app during setup, projected certificates follow changes made after setup
+func getQuayExtraCACertsProjections(quayConfiguration *QuayConfiguration) []corev1.VolumeProjection {
+
+	if len(quayConfiguration.QuayExtraCACerts) == 0 {
+		return nil
+	}
+
+	items := []corev1.KeyToPath{}
+
+	for _, fileName := range getSortedCertificateFileNames(quayConfiguration.QuayExtraCACerts) {
+		items = append(items, corev1.KeyToPath{
+			Key:  fileName,
+			Path: fmt.Sprintf("%s/%s", constants.QuayExtraCACertsDirectory, fileName),
+		})
+	}
+
+	return []corev1.VolumeProjection{
+		{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: GetQuayExtraCertsSecretName(quayConfiguration.QuayEcosystem),
+				},
+				Items: items,
+			},
+		},
+	}
+}
+
+// getSortedCertificateFileNames returns the file names of a set of certificates in a stable order
+func getSortedCertificateFileNames(certificates map[string][]byte) []string {
+
+	fileNames := []string{}
+
+	for fileName := range certificates {
+		fileNames = append(fileNames, fileName)
+	}
+
+	sort.Strings(fileNames)
+
+	return fileNames
+}
+
+// getCertificatePodAnnotations returns the pod annotations that restart pods using the Quay certificate once it changes
+func getCertificatePodAnnotations(quayConfiguration *QuayConfiguration) map[string]string {
+
+	podAnnotations := map[string]string{}
+
+	if certificate := quayConfiguration.QuayEcosystem.Status.Certificate; certificate != nil {
+		podAnnotations[constants.SslCertificateNotAfterAnnotation] = certificate.NotAfter.UTC().Format(time.RFC3339)
+	}
+
+	return podAnnotations
+}
+
+// getCACertificatePodAnnotations returns the pod annotations that restart pods once the Quay certificate or the extra CA
+// certificates they trust change. Certificates mounted with a subPath or loaded at startup are not reloaded otherwise
+func getCACertificatePodAnnotations(quayConfiguration *QuayConfiguration, extraCACerts map[string][]byte) map[string]string {
+
+	podAnnotations := getCertificatePodAnnotations(quayConfiguration)
+
+	if len(extraCACerts) == 0 {
+		return podAnnotations
+	}
+
+	hash := sha256.New()
+
+	for _, fileName := range getSortedCertificateFileNames(extraCACerts) {
+		hash.Write([]byte(fileName))
+		hash.Write(extraCACerts[fileName])
+	}
+
+	podAnnotations[constants.ExtraCACertsHashAnnotation] = hex.EncodeToString(hash.Sum(nil))
+
+	return podAnnotations
+}
+
+func getClairDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *QuayConfiguration, clairDeploymentPodSpec corev1.PodSpec) *appsv1.Deployment {
+
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.ImagePullSecretName) {
 		clairDeploymentPodSpec.ImagePullSecrets = []corev1.LocalObjectReference{corev1.LocalObjectReference{
-			Name: quayConfiguration.QuayEcosystem.Spec.Quay.ImagePullSecretName,
+			Name: quayConfiguration.QuayEcosystem.Spec.Clair.ImagePullSecretName,
 		},
 		}
 	}
 
-	clairReplicas := utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Clair.Replicas, &constants.ClairReplicas)
+	applyComponentPodSettings(&clairDeploymentPodSpec, quayConfiguration.QuayEcosystem.Spec.Clair.PodSettings)
+	applyHighAvailability(&clairDeploymentPodSpec, meta.Labels, quayConfiguration.QuayEcosystem.Spec.HighAvailability)
+
+	clairReplicas := utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Clair.Replicas, &constants.ClairReplicas).(*int32)
+
+	// Replicas are managed by the HorizontalPodAutoscaler
+	if quayConfiguration.QuayEcosystem.Spec.Clair.Autoscaling != nil {
+		clairReplicas = nil
+	}
+
+	// Restart Clair so that a renewed certificate, changed CA certificates or a rotated pagination key are picked up
+	clairPodAnnotations := getCACertificatePodAnnotations(quayConfiguration, quayConfiguration.ClairExtraCACerts)
+
+	if rotation, ok := quayConfiguration.QuayEcosystem.ObjectMeta.Annotations[constants.ClairPaginationKeyRotationAnnotation]; ok {
+		clairPodAnnotations[constants.ClairPaginationKeyRotationAnnotation] = rotation
+	}
+
+	// Restart Clair so that a rotated security scanner key is picked up
+	if securityScannerKey := quayConfiguration.QuayEcosystem.Status.SecurityScannerKey; securityScannerKey != nil && !IsClairV4(quayConfiguration.QuayEcosystem) {
+		clairPodAnnotations[constants.SecurityScannerKeyIDAnnotation] = securityScannerKey.ID
+	}
 
 	clairDeployment := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -385,13 +645,14 @@ func GetClairDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Qua
 		},
 		ObjectMeta: meta,
 		Spec: appsv1.DeploymentSpec{
-			Replicas: clairReplicas.(*int32),
+			Replicas: clairReplicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: meta.Labels,
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: meta.Labels,
+					Annotations: clairPodAnnotations,
+					Labels:      meta.Labels,
 				},
 				Spec: clairDeploymentPodSpec,
 			},
@@ -401,11 +662,11 @@ func GetClairDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *Qua
 	return clairDeployment
 }
 
-func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *QuayConfiguration) *appsv1.Deployment {
+func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, database redhatcopv1alpha1.Database) *appsv1.Deployment {
 
 	databaseDeploymentPodSpec := corev1.PodSpec{
 		Containers: []corev1.Container{{
-			Image: quayConfiguration.QuayEcosystem.Spec.Quay.Database.Image,
+			Image: database.Image,
 			Name:  meta.Name,
 			Env: []corev1.EnvVar{
 				{
@@ -413,7 +674,7 @@ func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *
 					ValueFrom: &corev1.EnvVarSource{
 						SecretKeyRef: &corev1.SecretKeySelector{
 							LocalObjectReference: corev1.LocalObjectReference{
-								Name: utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Quay.Database.CredentialsSecretName, GetQuayDatabaseName(quayConfiguration.QuayEcosystem)).(string),
+								Name: utils.CheckValue(database.CredentialsSecretName, meta.Name).(string),
 							},
 							Key: constants.DatabaseCredentialsUsernameKey,
 						},
@@ -424,7 +685,7 @@ func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *
 					ValueFrom: &corev1.EnvVarSource{
 						SecretKeyRef: &corev1.SecretKeySelector{
 							LocalObjectReference: corev1.LocalObjectReference{
-								Name: utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Quay.Database.CredentialsSecretName, GetQuayDatabaseName(quayConfiguration.QuayEcosystem)).(string),
+								Name: utils.CheckValue(database.CredentialsSecretName, meta.Name).(string),
 							},
 							Key: constants.DatabaseCredentialsPasswordKey,
 						},
@@ -435,7 +696,7 @@ func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *
 					ValueFrom: &corev1.EnvVarSource{
 						SecretKeyRef: &corev1.SecretKeySelector{
 							LocalObjectReference: corev1.LocalObjectReference{
-								Name: utils.CheckValue(quayConfiguration.QuayEcosystem.Spec.Quay.Database.CredentialsSecretName, GetQuayDatabaseName(quayConfiguration.QuayEcosystem)).(string),
+								Name: utils.CheckValue(database.CredentialsSecretName, meta.Name).(string),
 							},
 							Key: constants.DatabaseCredentialsDatabaseKey,
 						},
@@ -469,14 +730,14 @@ func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *
 		Volumes: []corev1.Volume{},
 	}
 
-	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.ImagePullSecretName) {
+	if !utils.IsZeroOfUnderlyingType(database.ImagePullSecretName) {
 		databaseDeploymentPodSpec.ImagePullSecrets = []corev1.LocalObjectReference{corev1.LocalObjectReference{
-			Name: quayConfiguration.QuayEcosystem.Spec.Quay.Database.ImagePullSecretName,
+			Name: database.ImagePullSecretName,
 		},
 		}
 	}
 
-	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.VolumeSize) {
+	if !utils.IsZeroOfUnderlyingType(database.VolumeSize) {
 		databaseDeploymentPodSpec.Containers[0].VolumeMounts = append(databaseDeploymentPodSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
 			Name:      "data",
 			MountPath: "/var/lib/pgsql/data",
@@ -486,26 +747,37 @@ func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *
 			Name: "data",
 			VolumeSource: corev1.VolumeSource{
 				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: GetQuayDatabaseName(quayConfiguration.QuayEcosystem),
+					ClaimName: meta.Name,
 				},
 			},
 		})
 
 	}
 
-	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.Memory) || !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.CPU) {
-		databaseResourceRequirements := corev1.ResourceRequirements{}
+	applyComponentPodSettings(&databaseDeploymentPodSpec, database.PodSettings)
+
+	// CPU and Memory take precedence over the resources in the pod settings
+	if !utils.IsZeroOfUnderlyingType(database.Memory) || !utils.IsZeroOfUnderlyingType(database.CPU) {
+		databaseResourceRequirements := databaseDeploymentPodSpec.Containers[0].Resources
 		databaseResourceLimits := corev1.ResourceList{}
 		databaseResourceRequests := corev1.ResourceList{}
 
-		if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.Memory) {
-			databaseResourceLimits[corev1.ResourceMemory] = resource.MustParse(quayConfiguration.QuayEcosystem.Spec.Quay.Database.Memory)
-			databaseResourceRequests[corev1.ResourceMemory] = resource.MustParse(quayConfiguration.QuayEcosystem.Spec.Quay.Database.Memory)
+		for name, quantity := range databaseResourceRequirements.Limits {
+			databaseResourceLimits[name] = quantity
 		}
 
-		if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.CPU) {
-			databaseResourceLimits[corev1.ResourceCPU] = resource.MustParse(quayConfiguration.QuayEcosystem.Spec.Quay.Database.CPU)
-			databaseResourceRequests[corev1.ResourceCPU] = resource.MustParse(quayConfiguration.QuayEcosystem.Spec.Quay.Database.CPU)
+		for name, quantity := range databaseResourceRequirements.Requests {
+			databaseResourceRequests[name] = quantity
+		}
+
+		if !utils.IsZeroOfUnderlyingType(database.Memory) {
+			databaseResourceLimits[corev1.ResourceMemory] = resource.MustParse(database.Memory)
+			databaseResourceRequests[corev1.ResourceMemory] = resource.MustParse(database.Memory)
+		}
+
+		if !utils.IsZeroOfUnderlyingType(database.CPU) {
+			databaseResourceLimits[corev1.ResourceCPU] = resource.MustParse(database.CPU)
+			databaseResourceRequests[corev1.ResourceCPU] = resource.MustParse(database.CPU)
 		}
 
 		databaseResourceRequirements.Requests = databaseResourceRequests
@@ -520,7 +792,7 @@ func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *
 		},
 		ObjectMeta: meta,
 		Spec: appsv1.DeploymentSpec{
-			Replicas: quayConfiguration.QuayEcosystem.Spec.Quay.Database.Replicas,
+			Replicas: database.Replicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: meta.Labels,
 			},
@@ -536,3 +808,59 @@ func GetDatabaseDeploymentDefinition(meta metav1.ObjectMeta, quayConfiguration *
 	return databaseDeployment
 
 }
+
+// applyComponentPodSettings applies the resources and scheduling constraints of a component to its pod
+func applyComponentPodSettings(podSpec *corev1.PodSpec, podSettings redhatcopv1alpha1.ComponentPodSettings) {
+
+	podSettings = *podSettings.DeepCopy()
+
+	podSpec.Affinity = podSettings.Affinity
+	podSpec.NodeSelector = podSettings.NodeSelector
+	podSpec.PriorityClassName = podSettings.PriorityClassName
+	podSpec.Tolerations = podSettings.Tolerations
+
+	if len(podSpec.Containers) > 0 {
+		podSpec.Containers[0].Resources = podSettings.Resources
+	}
+}
+
+// applyHighAvailability spreads the replicas of a component across the topology domains of the cluster
+func applyHighAvailability(podSpec *corev1.PodSpec, labels map[string]string, highAvailability redhatcopv1alpha1.HighAvailability) {
+
+	if !highAvailability.Enabled {
+		return
+	}
+
+	selectorLabels := map[string]string{}
+
+	for key, value := range labels {
+		selectorLabels[key] = value
+	}
+
+	podAffinityTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: selectorLabels,
+		},
+		TopologyKey: utils.CheckValue(highAvailability.TopologyKey, constants.HighAvailabilityTopologyKey).(string),
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+
+	if podSpec.Affinity.PodAntiAffinity == nil {
+		podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+
+	podAntiAffinity := podSpec.Affinity.PodAntiAffinity
+
+	if highAvailability.Mode == redhatcopv1alpha1.RequiredAntiAffinityHighAvailabilityMode {
+		podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, podAffinityTerm)
+		return
+	}
+
+	podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.WeightedPodAffinityTerm{
+		Weight:          100,
+		PodAffinityTerm: podAffinityTerm,
+	})
+}
@@ -0,0 +1,361 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestQuayEcosystem() *redhatcopv1alpha1.QuayEcosystem {
+	return &redhatcopv1alpha1.QuayEcosystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "quay-enterprise",
+		},
+	}
+}
+
+func TestComponentPodSettings(t *testing.T) {
+
+	podSettings := redhatcopv1alpha1.ComponentPodSettings{
+		Affinity: &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      "node-role.kubernetes.io/infra",
+							Operator: corev1.NodeSelectorOpExists,
+						}},
+					}},
+				},
+			},
+		},
+		NodeSelector:      map[string]string{"disktype": "ssd"},
+		PriorityClassName: "quay-critical",
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+		Tolerations: []corev1.Toleration{{
+			Key:      "node-role.kubernetes.io/infra",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		}},
+	}
+
+	cases := []struct {
+		deployment func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *appsv1.Deployment
+	}{
+		{
+			deployment: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *appsv1.Deployment {
+				quayEcosystem.Spec.Quay.PodSettings = podSettings
+				return GetQuayDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), &QuayConfiguration{QuayEcosystem: quayEcosystem})
+			},
+		},
+		{
+			deployment: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *appsv1.Deployment {
+				quayEcosystem.Spec.Quay.ConfigPodSettings = podSettings
+				return GetQuayConfigDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), &QuayConfiguration{QuayEcosystem: quayEcosystem})
+			},
+		},
+		{
+			deployment: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *appsv1.Deployment {
+				quayEcosystem.Spec.Redis.PodSettings = podSettings
+				return GetRedisDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), &QuayConfiguration{QuayEcosystem: quayEcosystem})
+			},
+		},
+		{
+			deployment: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *appsv1.Deployment {
+				quayEcosystem.Spec.Clair.PodSettings = podSettings
+				return GetClairDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), &QuayConfiguration{QuayEcosystem: quayEcosystem}, constants.LabelComponentClairValue)
+			},
+		},
+		{
+			deployment: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *appsv1.Deployment {
+				return GetDatabaseDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), redhatcopv1alpha1.Database{PodSettings: podSettings})
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		podSpec := c.deployment(newTestQuayEcosystem()).Spec.Template.Spec
+
+		actual := redhatcopv1alpha1.ComponentPodSettings{
+			Affinity:          podSpec.Affinity,
+			NodeSelector:      podSpec.NodeSelector,
+			PriorityClassName: podSpec.PriorityClassName,
+			Resources:         podSpec.Containers[0].Resources,
+			Tolerations:       podSpec.Tolerations,
+		}
+
+		if !reflect.DeepEqual(podSettings, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, podSettings, actual)
+		}
+	}
+}
+
+func TestDatabaseResources(t *testing.T) {
+
+	podSettings := redhatcopv1alpha1.ComponentPodSettings{
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("250m"),
+			},
+		},
+	}
+
+	cases := []struct {
+		database redhatcopv1alpha1.Database
+		expected corev1.ResourceRequirements
+	}{
+		{
+			database: redhatcopv1alpha1.Database{
+				PodSettings: podSettings,
+			},
+			expected: podSettings.Resources,
+		},
+		{
+			// Memory and CPU take precedence over the resources in the pod settings
+			database: redhatcopv1alpha1.Database{
+				Memory:      "2Gi",
+				PodSettings: podSettings,
+			},
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+		},
+		{
+			database: redhatcopv1alpha1.Database{
+				CPU:    "500m",
+				Memory: "512Mi",
+			},
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		actual := GetDatabaseDeploymentDefinition(NewResourceObjectMeta(newTestQuayEcosystem()), c.database).Spec.Template.Spec.Containers[0].Resources
+
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestHighAvailabilityAffinity(t *testing.T) {
+
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "node-role.kubernetes.io/infra",
+					Operator: corev1.NodeSelectorOpExists,
+				}},
+			}},
+		},
+	}
+
+	quayPodAffinityTerm := func(topologyKey string) corev1.PodAffinityTerm {
+		return corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					constants.LabelAppKey:      constants.LabelAppValue,
+					constants.LabelQuayCRKey:   "quay",
+					constants.LabelCompoentKey: constants.LabelComponentAppValue,
+				},
+			},
+			TopologyKey: topologyKey,
+		}
+	}
+
+	cases := []struct {
+		highAvailability redhatcopv1alpha1.HighAvailability
+		affinity         *corev1.Affinity
+		expected         *corev1.Affinity
+	}{
+		{
+			highAvailability: redhatcopv1alpha1.HighAvailability{},
+			expected:         nil,
+		},
+		{
+			highAvailability: redhatcopv1alpha1.HighAvailability{Enabled: true},
+			expected: &corev1.Affinity{
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+						Weight:          100,
+						PodAffinityTerm: quayPodAffinityTerm(constants.HighAvailabilityTopologyKey),
+					}},
+				},
+			},
+		},
+		{
+			highAvailability: redhatcopv1alpha1.HighAvailability{
+				Enabled:     true,
+				Mode:        redhatcopv1alpha1.RequiredAntiAffinityHighAvailabilityMode,
+				TopologyKey: "topology.kubernetes.io/zone",
+			},
+			expected: &corev1.Affinity{
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{quayPodAffinityTerm("topology.kubernetes.io/zone")},
+				},
+			},
+		},
+		{
+			// The anti-affinity is merged with the affinity from the pod settings
+			highAvailability: redhatcopv1alpha1.HighAvailability{Enabled: true, Mode: redhatcopv1alpha1.RequiredAntiAffinityHighAvailabilityMode},
+			affinity:         &corev1.Affinity{NodeAffinity: nodeAffinity},
+			expected: &corev1.Affinity{
+				NodeAffinity: nodeAffinity,
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{quayPodAffinityTerm(constants.HighAvailabilityTopologyKey)},
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem := newTestQuayEcosystem()
+		quayEcosystem.Spec.HighAvailability = c.highAvailability
+		quayEcosystem.Spec.Quay.PodSettings.Affinity = c.affinity
+
+		actual := GetQuayDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), &QuayConfiguration{QuayEcosystem: quayEcosystem}).Spec.Template.Spec.Affinity
+
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+
+		if c.affinity != nil && c.affinity.PodAntiAffinity != nil {
+			t.Errorf("Test case %d modified the affinity of the pod settings", i)
+		}
+	}
+}
+
+func TestQuaySecurityScannerModeAnnotation(t *testing.T) {
+
+	disabled := false
+
+	cases := []struct {
+		clair    redhatcopv1alpha1.Clair
+		expected string
+	}{
+		{
+			clair:    redhatcopv1alpha1.Clair{},
+			expected: "v2",
+		},
+		{
+			clair:    redhatcopv1alpha1.Clair{Version: redhatcopv1alpha1.V4ClairVersion},
+			expected: "v4",
+		},
+		{
+			clair:    redhatcopv1alpha1.Clair{Enabled: &disabled, Version: redhatcopv1alpha1.V4ClairVersion},
+			expected: "disabled",
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem := newTestQuayEcosystem()
+		quayEcosystem.Spec.Clair = c.clair
+
+		actual := GetQuayDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), &QuayConfiguration{QuayEcosystem: quayEcosystem}).Spec.Template.Annotations[constants.SecurityScannerModeAnnotation]
+
+		if c.expected != actual {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestExtraCACertsHashAnnotation(t *testing.T) {
+
+	cases := []struct {
+		quayExtraCACerts  map[string][]byte
+		clairExtraCACerts map[string][]byte
+		quayChanged       bool
+		clairChanged      bool
+	}{
+		{
+			quayExtraCACerts:  map[string][]byte{"corporate.crt": []byte("corporate")},
+			clairExtraCACerts: map[string][]byte{"corporate.crt": []byte("corporate")},
+			quayChanged:       true,
+			clairChanged:      true,
+		},
+		{
+			quayExtraCACerts:  map[string][]byte{"corporate.crt": []byte("corporate"), "proxy.crt": []byte("proxy")},
+			clairExtraCACerts: map[string][]byte{"corporate.crt": []byte("corporate")},
+			quayChanged:       true,
+			clairChanged:      false,
+		},
+		{
+			quayExtraCACerts:  map[string][]byte{"corporate.crt": []byte("renewed"), "proxy.crt": []byte("proxy")},
+			clairExtraCACerts: map[string][]byte{"corporate.crt": []byte("renewed")},
+			quayChanged:       true,
+			clairChanged:      true,
+		},
+		{
+			// Removing the certificates removes the annotation
+			quayChanged:  true,
+			clairChanged: true,
+		},
+	}
+
+	var previousQuayHash, previousClairHash string
+
+	for i, c := range cases {
+
+		quayEcosystem := newTestQuayEcosystem()
+		quayConfiguration := &QuayConfiguration{QuayEcosystem: quayEcosystem, QuayExtraCACerts: c.quayExtraCACerts, ClairExtraCACerts: c.clairExtraCACerts}
+
+		quayHash := GetQuayDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), quayConfiguration).Spec.Template.Annotations[constants.ExtraCACertsHashAnnotation]
+		configHash := GetQuayConfigDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), quayConfiguration).Spec.Template.Annotations[constants.ExtraCACertsHashAnnotation]
+		clairHash := GetClairDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), quayConfiguration, constants.LabelComponentClairValue).Spec.Template.Annotations[constants.ExtraCACertsHashAnnotation]
+
+		if configHash != quayHash {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, quayHash, configHash)
+		}
+
+		if result := quayHash != previousQuayHash; c.quayChanged != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.quayChanged, result)
+		}
+
+		if result := clairHash != previousClairHash; c.clairChanged != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.clairChanged, result)
+		}
+
+		previousQuayHash = quayHash
+		previousClairHash = clairHash
+	}
+}
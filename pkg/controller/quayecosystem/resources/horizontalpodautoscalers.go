@@ -0,0 +1,72 @@
+package resources
+
+import (
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetQuayHorizontalPodAutoscalerDefinition returns a HorizontalPodAutoscaler scaling the Quay deployment
+func GetQuayHorizontalPodAutoscalerDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *autoscalingv2beta1.HorizontalPodAutoscaler {
+
+	meta.Name = GetQuayResourcesName(quayEcosystem)
+	BuildQuayResourceLabels(meta.Labels)
+
+	return getHorizontalPodAutoscalerDefinition(meta, quayEcosystem.Spec.Quay.Autoscaling)
+}
+
+// GetClairHorizontalPodAutoscalerDefinition returns a HorizontalPodAutoscaler scaling the deployment of a Clair component
+func GetClairHorizontalPodAutoscalerDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem, clairComponent string) *autoscalingv2beta1.HorizontalPodAutoscaler {
+
+	meta.Name = GetClairComponentResourcesName(quayEcosystem, clairComponent)
+	BuildClairComponentResourceLabels(meta.Labels, clairComponent)
+
+	return getHorizontalPodAutoscalerDefinition(meta, quayEcosystem.Spec.Clair.Autoscaling)
+}
+
+func getHorizontalPodAutoscalerDefinition(meta metav1.ObjectMeta, autoscaling *redhatcopv1alpha1.Autoscaling) *autoscalingv2beta1.HorizontalPodAutoscaler {
+
+	horizontalPodAutoscaler := &autoscalingv2beta1.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: autoscalingv2beta1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: autoscalingv2beta1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta1.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       meta.Name,
+			},
+		},
+	}
+
+	if autoscaling == nil {
+		return horizontalPodAutoscaler
+	}
+
+	horizontalPodAutoscaler.Spec.MinReplicas = autoscaling.MinReplicas
+	horizontalPodAutoscaler.Spec.MaxReplicas = autoscaling.MaxReplicas
+
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		horizontalPodAutoscaler.Spec.Metrics = append(horizontalPodAutoscaler.Spec.Metrics, getResourceMetricSpec(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		horizontalPodAutoscaler.Spec.Metrics = append(horizontalPodAutoscaler.Spec.Metrics, getResourceMetricSpec(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+
+	return horizontalPodAutoscaler
+}
+
+func getResourceMetricSpec(resourceName corev1.ResourceName, targetAverageUtilization int32) autoscalingv2beta1.MetricSpec {
+	return autoscalingv2beta1.MetricSpec{
+		Type: autoscalingv2beta1.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta1.ResourceMetricSource{
+			Name:                     resourceName,
+			TargetAverageUtilization: &targetAverageUtilization,
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestHorizontalPodAutoscalerDefinition(t *testing.T) {
+
+	minReplicas := int32(2)
+	targetCPUUtilizationPercentage := int32(80)
+	targetMemoryUtilizationPercentage := int32(70)
+
+	cases := []struct {
+		horizontalPodAutoscaler func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *autoscalingv2beta1.HorizontalPodAutoscaler
+		expected                autoscalingv2beta1.HorizontalPodAutoscalerSpec
+	}{
+		{
+			horizontalPodAutoscaler: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *autoscalingv2beta1.HorizontalPodAutoscaler {
+				quayEcosystem.Spec.Quay.Autoscaling = &redhatcopv1alpha1.Autoscaling{
+					MaxReplicas:                    5,
+					MinReplicas:                    &minReplicas,
+					TargetCPUUtilizationPercentage: &targetCPUUtilizationPercentage,
+				}
+				return GetQuayHorizontalPodAutoscalerDefinition(NewResourceObjectMeta(quayEcosystem), quayEcosystem)
+			},
+			expected: autoscalingv2beta1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta1.CrossVersionObjectReference{
+					APIVersion: appsv1.SchemeGroupVersion.String(),
+					Kind:       "Deployment",
+					Name:       "quay-quay",
+				},
+				MinReplicas: &minReplicas,
+				MaxReplicas: 5,
+				Metrics: []autoscalingv2beta1.MetricSpec{
+					getResourceMetricSpec(corev1.ResourceCPU, targetCPUUtilizationPercentage),
+				},
+			},
+		},
+		{
+			horizontalPodAutoscaler: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *autoscalingv2beta1.HorizontalPodAutoscaler {
+				quayEcosystem.Spec.Clair.Autoscaling = &redhatcopv1alpha1.Autoscaling{
+					MaxReplicas:                       3,
+					TargetCPUUtilizationPercentage:    &targetCPUUtilizationPercentage,
+					TargetMemoryUtilizationPercentage: &targetMemoryUtilizationPercentage,
+				}
+				return GetClairHorizontalPodAutoscalerDefinition(NewResourceObjectMeta(quayEcosystem), quayEcosystem, constants.LabelComponentClairValue)
+			},
+			expected: autoscalingv2beta1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta1.CrossVersionObjectReference{
+					APIVersion: appsv1.SchemeGroupVersion.String(),
+					Kind:       "Deployment",
+					Name:       GetClairComponentResourcesName(newTestQuayEcosystem(), constants.LabelComponentClairValue),
+				},
+				MaxReplicas: 3,
+				Metrics: []autoscalingv2beta1.MetricSpec{
+					getResourceMetricSpec(corev1.ResourceCPU, targetCPUUtilizationPercentage),
+					getResourceMetricSpec(corev1.ResourceMemory, targetMemoryUtilizationPercentage),
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		actual := c.horizontalPodAutoscaler(newTestQuayEcosystem()).Spec
+
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestAutoscaledDeploymentReplicas(t *testing.T) {
+
+	replicas := int32(3)
+
+	cases := []struct {
+		autoscaling *redhatcopv1alpha1.Autoscaling
+		expected    *int32
+	}{
+		{
+			autoscaling: nil,
+			expected:    &replicas,
+		},
+		{
+			// Replicas are left to the HorizontalPodAutoscaler
+			autoscaling: &redhatcopv1alpha1.Autoscaling{MaxReplicas: 5},
+			expected:    nil,
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem := newTestQuayEcosystem()
+		quayEcosystem.Spec.Quay.Replicas = &replicas
+		quayEcosystem.Spec.Quay.Autoscaling = c.autoscaling
+		quayEcosystem.Spec.Clair.Replicas = &replicas
+		quayEcosystem.Spec.Clair.Autoscaling = c.autoscaling
+
+		quayConfiguration := &QuayConfiguration{QuayEcosystem: quayEcosystem}
+
+		actual := []*int32{
+			GetQuayDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), quayConfiguration).Spec.Replicas,
+			GetClairDeploymentDefinition(NewResourceObjectMeta(quayEcosystem), quayConfiguration, constants.LabelComponentClairValue).Spec.Replicas,
+		}
+
+		if !reflect.DeepEqual([]*int32{c.expected, c.expected}, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
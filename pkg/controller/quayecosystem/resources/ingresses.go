@@ -0,0 +1,105 @@
+package resources
+
+import (
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// GetQuayIngressDefinition returns an Ingress exposing Quay on its Route Host
+func GetQuayIngressDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *extensionsv1beta1.Ingress {
+
+	meta.Name = GetQuayResourcesName(quayEcosystem)
+	meta.Labels = BuildQuayResourceLabels(meta.Labels)
+
+	return getIngressDefinition(meta, quayEcosystem, quayEcosystem.Spec.Quay.RouteHost)
+}
+
+// GetQuayConfigIngressDefinition returns an Ingress exposing the Quay config app on its Config Route Host
+func GetQuayConfigIngressDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *extensionsv1beta1.Ingress {
+
+	meta.Name = GetQuayConfigResourcesName(quayEcosystem)
+	meta.Labels = BuildQuayConfigResourceLabels(meta.Labels)
+
+	return getIngressDefinition(meta, quayEcosystem, quayEcosystem.Spec.Quay.ConfigRouteHost)
+}
+
+// GetQuayIngressTLSSecretDefinition returns the Secret holding the Quay certificate in the format expected by Ingresses
+func GetQuayIngressTLSSecretDefinition(meta metav1.ObjectMeta, quayConfiguration *QuayConfiguration) *corev1.Secret {
+
+	meta.Name = GetQuayIngressTLSSecretName(quayConfiguration.QuayEcosystem)
+
+	secret := GetSecretDefinition(meta)
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       quayConfiguration.QuaySslCertificate,
+		corev1.TLSPrivateKeyKey: quayConfiguration.QuaySslPrivateKey,
+	}
+
+	return secret
+}
+
+// getIngressDefinition returns an Ingress routing a host to the service of the same name. The Quay services only serve
+// HTTPS, so the NGINX ingress controller is configured to connect to its backend using HTTPS by default. Other ingress
+// controllers can be configured through the External Access annotations, which take precedence over the default
+func getIngressDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem, host string) *extensionsv1beta1.Ingress {
+
+	annotations := map[string]string{
+		constants.IngressBackendProtocolAnnotation: "HTTPS",
+	}
+
+	for key, value := range meta.Annotations {
+		annotations[key] = value
+	}
+
+	if externalAccess := quayEcosystem.Spec.Quay.ExternalAccess; externalAccess != nil {
+
+		for key, value := range externalAccess.Annotations {
+			annotations[key] = value
+		}
+
+		if !utils.IsZeroOfUnderlyingType(externalAccess.IngressClass) {
+			annotations[constants.IngressClassAnnotation] = externalAccess.IngressClass
+		}
+	}
+
+	meta.Annotations = annotations
+
+	return &extensionsv1beta1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: extensionsv1beta1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: extensionsv1beta1.IngressSpec{
+			TLS: []extensionsv1beta1.IngressTLS{
+				{
+					Hosts:      []string{host},
+					SecretName: GetQuayIngressTLSSecretName(quayEcosystem),
+				},
+			},
+			Rules: []extensionsv1beta1.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+						HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+							Paths: []extensionsv1beta1.HTTPIngressPath{
+								{
+									Path: "/",
+									Backend: extensionsv1beta1.IngressBackend{
+										ServiceName: meta.Name,
+										ServicePort: intstr.FromInt(443),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
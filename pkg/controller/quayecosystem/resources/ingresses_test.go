@@ -0,0 +1,113 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestQuayIngressDefinition(t *testing.T) {
+
+	cases := []struct {
+		externalAccess      *redhatcopv1alpha1.ExternalAccess
+		expectedAnnotations map[string]string
+	}{
+		{
+			externalAccess: nil,
+			expectedAnnotations: map[string]string{
+				constants.IngressBackendProtocolAnnotation: "HTTPS",
+			},
+		},
+		{
+			externalAccess: &redhatcopv1alpha1.ExternalAccess{
+				Annotations: map[string]string{
+					"nginx.ingress.kubernetes.io/proxy-body-size": "0",
+				},
+				IngressClass: "nginx",
+				Type:         redhatcopv1alpha1.IngressExternalAccessType,
+			},
+			expectedAnnotations: map[string]string{
+				constants.IngressBackendProtocolAnnotation:    "HTTPS",
+				constants.IngressClassAnnotation:              "nginx",
+				"nginx.ingress.kubernetes.io/proxy-body-size": "0",
+			},
+		},
+		{
+			// Annotations of the External Access override the default backend protocol
+			externalAccess: &redhatcopv1alpha1.ExternalAccess{
+				Annotations: map[string]string{
+					constants.IngressBackendProtocolAnnotation: "GRPCS",
+				},
+				Type: redhatcopv1alpha1.IngressExternalAccessType,
+			},
+			expectedAnnotations: map[string]string{
+				constants.IngressBackendProtocolAnnotation: "GRPCS",
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem := newTestQuayEcosystem()
+		quayEcosystem.Spec.Quay.RouteHost = "quay.example.com"
+		quayEcosystem.Spec.Quay.ExternalAccess = c.externalAccess
+
+		ingress := GetQuayIngressDefinition(NewResourceObjectMeta(quayEcosystem), quayEcosystem)
+
+		if !reflect.DeepEqual(c.expectedAnnotations, ingress.Annotations) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expectedAnnotations, ingress.Annotations)
+		}
+
+		expectedTLS := []extensionsv1beta1.IngressTLS{
+			{
+				Hosts:      []string{"quay.example.com"},
+				SecretName: "quay-quay-ingress-tls",
+			},
+		}
+
+		if !reflect.DeepEqual(expectedTLS, ingress.Spec.TLS) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, expectedTLS, ingress.Spec.TLS)
+		}
+
+		expectedBackend := extensionsv1beta1.IngressBackend{
+			ServiceName: "quay-quay",
+			ServicePort: intstr.FromInt(443),
+		}
+
+		if host := ingress.Spec.Rules[0].Host; host != "quay.example.com" {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, "quay.example.com", host)
+		}
+
+		if backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend; !reflect.DeepEqual(expectedBackend, backend) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, expectedBackend, backend)
+		}
+	}
+}
+
+func TestQuayConfigIngressDefinition(t *testing.T) {
+
+	quayEcosystem := newTestQuayEcosystem()
+	quayEcosystem.Spec.Quay.ConfigRouteHost = "quay-config.example.com"
+
+	ingress := GetQuayConfigIngressDefinition(NewResourceObjectMeta(quayEcosystem), quayEcosystem)
+
+	if ingress.Name != "quay-quay-config" {
+		t.Errorf("Name did not match\nExpected: %#v\nActual: %#v", "quay-quay-config", ingress.Name)
+	}
+
+	if host := ingress.Spec.Rules[0].Host; host != "quay-config.example.com" {
+		t.Errorf("Host did not match\nExpected: %#v\nActual: %#v", "quay-config.example.com", host)
+	}
+
+	if serviceName := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.ServiceName; serviceName != ingress.Name {
+		t.Errorf("Backend did not match\nExpected: %#v\nActual: %#v", ingress.Name, serviceName)
+	}
+
+	if protocol := ingress.Annotations[constants.IngressBackendProtocolAnnotation]; protocol != "HTTPS" {
+		t.Errorf("Backend protocol did not match\nExpected: %#v\nActual: %#v", "HTTPS", protocol)
+	}
+}
@@ -0,0 +1,55 @@
+package resources
+
+import (
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetQuayPodDisruptionBudgetDefinition returns a PodDisruptionBudget keeping the configured number of Quay pods available
+func GetQuayPodDisruptionBudgetDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *policyv1beta1.PodDisruptionBudget {
+
+	meta.Name = GetQuayResourcesName(quayEcosystem)
+	BuildQuayResourceLabels(meta.Labels)
+
+	return getPodDisruptionBudgetDefinition(meta, quayEcosystem)
+}
+
+// GetClairPodDisruptionBudgetDefinition returns a PodDisruptionBudget keeping the configured number of pods of a Clair component available
+func GetClairPodDisruptionBudgetDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem, clairComponent string) *policyv1beta1.PodDisruptionBudget {
+
+	meta.Name = GetClairComponentResourcesName(quayEcosystem, clairComponent)
+	BuildClairComponentResourceLabels(meta.Labels, clairComponent)
+
+	return getPodDisruptionBudgetDefinition(meta, quayEcosystem)
+}
+
+func getPodDisruptionBudgetDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *policyv1beta1.PodDisruptionBudget {
+
+	minAvailable := constants.HighAvailabilityMinAvailable
+
+	if quayEcosystem.Spec.HighAvailability.MinAvailable != nil {
+		minAvailable = *quayEcosystem.Spec.HighAvailability.MinAvailable
+	}
+
+	selectorLabels := map[string]string{}
+
+	for key, value := range meta.Labels {
+		selectorLabels[key] = value
+	}
+
+	return &policyv1beta1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: policyv1beta1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			},
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestPodDisruptionBudgetDefinition(t *testing.T) {
+
+	minAvailable := intstr.FromString("50%")
+
+	cases := []struct {
+		highAvailability    redhatcopv1alpha1.HighAvailability
+		podDisruptionBudget func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *policyv1beta1.PodDisruptionBudget
+		expected            policyv1beta1.PodDisruptionBudgetSpec
+	}{
+		{
+			highAvailability: redhatcopv1alpha1.HighAvailability{Enabled: true},
+			podDisruptionBudget: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *policyv1beta1.PodDisruptionBudget {
+				return GetQuayPodDisruptionBudgetDefinition(NewResourceObjectMeta(quayEcosystem), quayEcosystem)
+			},
+			expected: policyv1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: &constants.HighAvailabilityMinAvailable,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						constants.LabelAppKey:      constants.LabelAppValue,
+						constants.LabelQuayCRKey:   "quay",
+						constants.LabelCompoentKey: constants.LabelComponentAppValue,
+					},
+				},
+			},
+		},
+		{
+			highAvailability: redhatcopv1alpha1.HighAvailability{Enabled: true, MinAvailable: &minAvailable},
+			podDisruptionBudget: func(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *policyv1beta1.PodDisruptionBudget {
+				return GetClairPodDisruptionBudgetDefinition(NewResourceObjectMeta(quayEcosystem), quayEcosystem, constants.LabelComponentClairValue)
+			},
+			expected: policyv1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						constants.LabelAppKey:      constants.LabelAppValue,
+						constants.LabelQuayCRKey:   "quay",
+						constants.LabelCompoentKey: constants.LabelComponentClairValue,
+					},
+				},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayEcosystem := newTestQuayEcosystem()
+		quayEcosystem.Spec.HighAvailability = c.highAvailability
+
+		actual := c.podDisruptionBudget(quayEcosystem).Spec
+
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
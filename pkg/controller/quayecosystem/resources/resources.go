@@ -5,6 +5,7 @@ import (
 
 	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -42,6 +43,12 @@ func BuildClairResourceLabels(resourceMap map[string]string) map[string]string {
 	return resourceMap
 }
 
+// BuildClairComponentResourceLabels builds labels for the resources of a single Clair component
+func BuildClairComponentResourceLabels(resourceMap map[string]string, clairComponent string) map[string]string {
+	resourceMap[constants.LabelCompoentKey] = clairComponent
+	return resourceMap
+}
+
 // BuildQuayConfigResourceLabels builds labels for the Quay config resources
 func BuildQuayConfigResourceLabels(resourceMap map[string]string) map[string]string {
 	resourceMap[constants.LabelCompoentKey] = constants.LabelComponentConfigValue
@@ -54,6 +61,18 @@ func BuildQuayDatabaseResourceLabels(resourceMap map[string]string) map[string]s
 	return resourceMap
 }
 
+// BuildClairDatabaseResourceLabels builds labels for the Clair database resources
+func BuildClairDatabaseResourceLabels(resourceMap map[string]string) map[string]string {
+	resourceMap[constants.LabelCompoentKey] = constants.LabelComponentClairDatabaseValue
+	return resourceMap
+}
+
+// BuildQuayStorageReplicationResourceLabels builds labels for the Quay storage replication worker resources
+func BuildQuayStorageReplicationResourceLabels(resourceMap map[string]string) map[string]string {
+	resourceMap[constants.LabelCompoentKey] = constants.LabelComponentStorageReplicationValue
+	return resourceMap
+}
+
 // BuildRedisResourceLabels builds labels for the Redis app resources
 func BuildRedisResourceLabels(resourceMap map[string]string) map[string]string {
 	resourceMap[constants.LabelCompoentKey] = constants.LabelComponentRedisValue
@@ -65,11 +84,21 @@ func GetQuayResourcesName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string
 	return fmt.Sprintf("%s-quay", GetGenericResourcesName(quayEcosystem))
 }
 
+// GetQuayStorageReplicationResourcesName returns name of Kubernetes resource name for the Quay storage replication worker
+func GetQuayStorageReplicationResourcesName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
+	return fmt.Sprintf("%s-quay-storage-replication", GetGenericResourcesName(quayEcosystem))
+}
+
 // GetClairResourcesName returns name of Kubernetes resource name
 func GetClairResourcesName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
 	return fmt.Sprintf("%s-clair", GetGenericResourcesName(quayEcosystem))
 }
 
+// GetClairComponentResourcesName returns name of Kubernetes resource name for a single Clair component
+func GetClairComponentResourcesName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem, clairComponent string) string {
+	return fmt.Sprintf("%s-%s", GetGenericResourcesName(quayEcosystem), clairComponent)
+}
+
 // GetQuayConfigResourcesName returns name of Kubernetes resource name
 func GetQuayConfigResourcesName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
 	return fmt.Sprintf("%s-quay-config", GetGenericResourcesName(quayEcosystem))
@@ -107,12 +136,27 @@ func GetQuayExtraCertsSecretName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem)
 	return "quay-enterprise-cert-secret"
 }
 
+// GetQuayCertificateName returns the name of the cert-manager Certificate and the Secret it populates
+func GetQuayCertificateName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
+	return fmt.Sprintf("%s-quay-tls", GetGenericResourcesName(quayEcosystem))
+}
+
+// GetQuayIngressTLSSecretName returns the name of the Secret holding the Quay certificate served by Ingresses
+func GetQuayIngressTLSSecretName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
+	return fmt.Sprintf("%s-quay-ingress-tls", GetGenericResourcesName(quayEcosystem))
+}
+
+// GetTrustedCABundleConfigMapName returns the name of the ConfigMap into which the cluster trusted CA bundle is injected
+func GetTrustedCABundleConfigMapName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
+	return fmt.Sprintf("%s-trusted-ca-bundle", GetGenericResourcesName(quayEcosystem))
+}
+
 // GetQuayDatabaseName returns the name of the Quay database
 func GetQuayDatabaseName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
 	return fmt.Sprintf("%s-quay-%s", GetGenericResourcesName(quayEcosystem), constants.PostgresqlName)
 }
 
-// GetClairDatabaseName returns the name of the Quay database
+// GetClairDatabaseName returns the name of the Clair database
 func GetClairDatabaseName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) string {
 	return fmt.Sprintf("%s-clair-%s", GetGenericResourcesName(quayEcosystem), constants.PostgresqlName)
 }
@@ -127,6 +171,116 @@ func GetRegistryStorageVolumeName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem
 	return fmt.Sprintf("%s-%s", GetGenericResourcesName(quayEcosystem), registryBackendName)
 }
 
+// GetRegistryObjectBucketClaimName returns the name of the ObjectBucketClaim for the storage backend
+func GetRegistryObjectBucketClaimName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem, registryBackend redhatcopv1alpha1.RegistryBackend) string {
+	return utils.CheckValue(registryBackend.RegistryBackendSource.ObjectBucketClaim.Name, GetRegistryStorageVolumeName(quayEcosystem, registryBackend.Name)).(string)
+}
+
+// GetVolumeSnapshotName returns the name of the VolumeSnapshot taken of a PersistentVolumeClaim when the QuayEcosystem is deleted
+func GetVolumeSnapshotName(quayEcosystem *redhatcopv1alpha1.QuayEcosystem, persistentVolumeClaimName string) string {
+
+	uid := string(quayEcosystem.UID)
+
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+
+	return fmt.Sprintf("%s-%s", persistentVolumeClaimName, uid)
+}
+
+// IsClairEnabled returns whether Clair should be deployed to provide security scanning. Clair is enabled unless explicitly disabled
+func IsClairEnabled(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) bool {
+	return quayEcosystem.Spec.Clair.Enabled == nil || *quayEcosystem.Spec.Clair.Enabled
+}
+
+// IsClairDatabaseDedicated returns whether Clair uses its own database instead of a database within the Quay database
+// server. Only the connection settings select a dedicated database, the remaining settings merely tune it
+func IsClairDatabaseDedicated(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) bool {
+	return !utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.Database.Server) || !utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.Database.CredentialsSecretName)
+}
+
+// IsClairV4 returns whether Clair v4 is deployed instead of Clair v2
+func IsClairV4(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) bool {
+	return quayEcosystem.Spec.Clair.Version == redhatcopv1alpha1.V4ClairVersion
+}
+
+// GetExternalAccessType returns how Quay is exposed outside of the cluster. Routes are used on OpenShift and Ingresses
+// on other Kubernetes distributions unless a type is specified
+func GetExternalAccessType(quayConfiguration *QuayConfiguration) redhatcopv1alpha1.ExternalAccessType {
+
+	externalAccess := quayConfiguration.QuayEcosystem.Spec.Quay.ExternalAccess
+
+	if externalAccess != nil && !utils.IsZeroOfUnderlyingType(externalAccess.Type) {
+		return externalAccess.Type
+	}
+
+	if quayConfiguration.IsOpenShift {
+		return redhatcopv1alpha1.RouteExternalAccessType
+	}
+
+	return redhatcopv1alpha1.IngressExternalAccessType
+}
+
+// GetClairComponents returns the Clair components that are deployed separately. Clair v2 and the combined Clair v4 mode run as a single component
+func GetClairComponents(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) []string {
+
+	if IsClairV4(quayEcosystem) && quayEcosystem.Spec.Clair.DeploymentMode == redhatcopv1alpha1.SplitClairDeploymentMode {
+		return []string{constants.LabelComponentClairIndexerValue, constants.LabelComponentClairMatcherValue, constants.LabelComponentClairNotifierValue}
+	}
+
+	return []string{constants.LabelComponentClairValue}
+}
+
+// IsStorageReplicationEnabled returns whether any registry backend is replicated to by default
+func IsStorageReplicationEnabled(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) bool {
+	for _, registryBackend := range quayEcosystem.Spec.Quay.RegistryBackends {
+		if registryBackend.ReplicateByDefault {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetStorageReplicationConfig sets the storage preference and replication settings of the Quay configuration according
+// to the registry backends
+func SetStorageReplicationConfig(config map[string]interface{}, quayEcosystem *redhatcopv1alpha1.QuayEcosystem) {
+	config["DISTRIBUTED_STORAGE_PREFERENCE"] = getDistributedStoragePreference(quayEcosystem.Spec.Quay.RegistryBackends)
+	config["DISTRIBUTED_STORAGE_DEFAULT_LOCATIONS"] = getDistributedStorageDefaultLocations(quayEcosystem.Spec.Quay.RegistryBackends)
+	config["FEATURE_STORAGE_REPLICATION"] = IsStorageReplicationEnabled(quayEcosystem)
+}
+
+// getDistributedStoragePreference returns the registry backend names ordered with the preferred backends first
+func getDistributedStoragePreference(registryBackends []redhatcopv1alpha1.RegistryBackend) []string {
+
+	preferred := []string{}
+	remaining := []string{}
+
+	for _, registryBackend := range registryBackends {
+		if registryBackend.Preferred {
+			preferred = append(preferred, registryBackend.Name)
+		} else {
+			remaining = append(remaining, registryBackend.Name)
+		}
+	}
+
+	return append(preferred, remaining...)
+}
+
+// getDistributedStorageDefaultLocations returns the registry backend names that blobs are replicated to by default
+func getDistributedStorageDefaultLocations(registryBackends []redhatcopv1alpha1.RegistryBackend) []string {
+
+	defaultLocations := []string{}
+
+	for _, registryBackend := range registryBackends {
+		if registryBackend.ReplicateByDefault {
+			defaultLocations = append(defaultLocations, registryBackend.Name)
+		}
+	}
+
+	return defaultLocations
+}
+
 // UpdateMetaWithName updates the name of the resource
 func UpdateMetaWithName(meta metav1.ObjectMeta, name string) metav1.ObjectMeta {
 	meta.Name = name
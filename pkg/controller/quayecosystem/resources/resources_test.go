@@ -0,0 +1,37 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+)
+
+func TestDistributedStorageLocations(t *testing.T) {
+
+	registryBackends := []redhatcopv1alpha1.RegistryBackend{
+		{Name: "us-east", ReplicateByDefault: true},
+		{Name: "eu-west", Preferred: true, ReplicateByDefault: true},
+		{Name: "archive"},
+	}
+
+	cases := []struct {
+		result   []string
+		expected []string
+	}{
+		{
+			result:   getDistributedStoragePreference(registryBackends),
+			expected: []string{"eu-west", "us-east", "archive"},
+		},
+		{
+			result:   getDistributedStorageDefaultLocations(registryBackends),
+			expected: []string{"us-east", "eu-west"},
+		},
+	}
+
+	for i, c := range cases {
+		if !reflect.DeepEqual(c.expected, c.result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, c.result)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package resources
 import (
 	routev1 "github.com/openshift/api/route/v1"
 	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -79,6 +80,13 @@ func GetClairRouteDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1a
 
 	meta.Name = GetClairResourcesName(quayEcosystem)
 
+	// In split mode, requests that are not routed to the indexer or notifier are served by the matcher
+	serviceName := meta.Name
+
+	if len(GetClairComponents(quayEcosystem)) > 1 {
+		serviceName = GetClairComponentResourcesName(quayEcosystem, constants.LabelComponentClairMatcherValue)
+	}
+
 	route := &routev1.Route{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Route",
@@ -88,7 +96,7 @@ func GetClairRouteDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1a
 		Spec: routev1.RouteSpec{
 			To: routev1.RouteTargetReference{
 				Kind: "Service",
-				Name: meta.Name,
+				Name: serviceName,
 			},
 			Port: &routev1.RoutePort{
 				TargetPort: intstr.FromInt(6060),
@@ -100,3 +108,29 @@ func GetClairRouteDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1a
 
 	return route
 }
+
+// GetClairComponentRouteDefinition returns a route exposing a single Clair component under a path of the Clair route host
+func GetClairComponentRouteDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem, clairComponent string, host string, path string) *routev1.Route {
+
+	meta.Name = GetClairComponentResourcesName(quayEcosystem, clairComponent)
+	BuildClairComponentResourceLabels(meta.Labels, clairComponent)
+
+	return &routev1.Route{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Route",
+			APIVersion: routev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: routev1.RouteSpec{
+			Host: host,
+			Path: path,
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: meta.Name,
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromInt(6060),
+			},
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,3 +30,24 @@ func GetSecretDefinitionFromCredentialsMap(name string, meta metav1.ObjectMeta,
 		StringData: secretMap,
 	}
 }
+
+// GetTrustedCABundleConfigMapDefinition returns a ConfigMap into which OpenShift injects the cluster trusted CA bundle
+func GetTrustedCABundleConfigMapDefinition(meta metav1.ObjectMeta) *corev1.ConfigMap {
+
+	labels := map[string]string{}
+
+	for key, value := range meta.Labels {
+		labels[key] = value
+	}
+
+	labels[constants.TrustedCABundleInjectLabel] = "true"
+	meta.Labels = labels
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+	}
+}
@@ -2,6 +2,7 @@ package resources
 
 import (
 	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -60,11 +61,7 @@ func GetQuayServiceDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1
 
 	service.ObjectMeta.Labels = BuildQuayResourceLabels(meta.Labels)
 
-	if quayEcosystem.Spec.Quay.EnableNodePortService {
-		service.Spec.Type = corev1.ServiceTypeNodePort
-	} else {
-		service.Spec.Type = corev1.ServiceTypeClusterIP
-	}
+	applyServiceExposure(service, quayEcosystem.Spec.Quay.ServiceExposure, quayEcosystem.Spec.Quay.EnableNodePortService)
 
 	return service
 
@@ -94,19 +91,83 @@ func GetQuayConfigServiceDefinition(meta metav1.ObjectMeta, quayEcosystem *redha
 
 	service.ObjectMeta.Labels = BuildQuayConfigResourceLabels(meta.Labels)
 
-	if quayEcosystem.Spec.Quay.EnableNodePortService {
+	applyServiceExposure(service, quayEcosystem.Spec.Quay.ConfigServiceExposure, quayEcosystem.Spec.Quay.EnableNodePortService)
+
+	return service
+
+}
+
+// applyServiceExposure sets the type of a Service exposing a single port along with the settings of its exposure
+func applyServiceExposure(service *corev1.Service, serviceExposure *redhatcopv1alpha1.ServiceExposure, enableNodePortService bool) {
+
+	if enableNodePortService {
 		service.Spec.Type = corev1.ServiceTypeNodePort
 	} else {
 		service.Spec.Type = corev1.ServiceTypeClusterIP
 	}
 
-	return service
+	if serviceExposure == nil {
+		return
+	}
+
+	if !utils.IsZeroOfUnderlyingType(serviceExposure.Type) {
+		service.Spec.Type = serviceExposure.Type
+	}
+
+	if len(serviceExposure.Annotations) > 0 {
+
+		annotations := map[string]string{}
 
+		for key, value := range service.Annotations {
+			annotations[key] = value
+		}
+
+		for key, value := range serviceExposure.Annotations {
+			annotations[key] = value
+		}
+
+		service.Annotations = annotations
+	}
+
+	// The remaining settings are rejected by the API server for ClusterIP services
+	if service.Spec.Type == corev1.ServiceTypeClusterIP {
+		return
+	}
+
+	service.Spec.Ports[0].NodePort = serviceExposure.NodePort
+	service.Spec.ExternalTrafficPolicy = serviceExposure.ExternalTrafficPolicy
+
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		service.Spec.LoadBalancerSourceRanges = serviceExposure.LoadBalancerSourceRanges
+	}
+}
+
+// IsLoadBalancerServiceExposure returns whether a Service is exposed through a cloud load balancer
+func IsLoadBalancerServiceExposure(serviceExposure *redhatcopv1alpha1.ServiceExposure) bool {
+	return serviceExposure != nil && serviceExposure.Type == corev1.ServiceTypeLoadBalancer
 }
 
-func GetClairServiceDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem) *corev1.Service {
+// GetLoadBalancerAddress returns the external address assigned to the load balancer of a Service
+func GetLoadBalancerAddress(service *corev1.Service) string {
+
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
 
-	meta.Name = GetClairResourcesName(quayEcosystem)
+		if !utils.IsZeroOfUnderlyingType(ingress.Hostname) {
+			return ingress.Hostname
+		}
+
+		if !utils.IsZeroOfUnderlyingType(ingress.IP) {
+			return ingress.IP
+		}
+	}
+
+	return ""
+}
+
+func GetClairServiceDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv1alpha1.QuayEcosystem, clairComponent string) *corev1.Service {
+
+	meta.Name = GetClairComponentResourcesName(quayEcosystem, clairComponent)
+	BuildClairComponentResourceLabels(meta.Labels, clairComponent)
 
 	service := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
@@ -133,8 +194,6 @@ func GetClairServiceDefinition(meta metav1.ObjectMeta, quayEcosystem *redhatcopv
 		},
 	}
 
-	service.ObjectMeta.Labels = BuildClairResourceLabels(meta.Labels)
-
 	if quayEcosystem.Spec.Quay.EnableNodePortService {
 		service.Spec.Type = corev1.ServiceTypeNodePort
 	} else {
@@ -1,9 +1,11 @@
 package resources
 
 import (
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -53,3 +55,49 @@ func GetDatabasePVCDefinition(meta metav1.ObjectMeta, volumeSize string) *corev1
 		},
 	}
 }
+
+// GetObjectBucketClaimDefinition returns an ObjectBucketClaim requesting a bucket from the provided storage class
+func GetObjectBucketClaimDefinition(meta metav1.ObjectMeta, storageClassName string) *unstructured.Unstructured {
+
+	objectBucketClaim := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"generateBucketName": meta.Name,
+				"storageClassName":   storageClassName,
+			},
+		},
+	}
+
+	objectBucketClaim.SetAPIVersion(constants.ObjectBucketClaimAPIVersion)
+	objectBucketClaim.SetKind(constants.ObjectBucketClaimKind)
+	objectBucketClaim.SetName(meta.Name)
+	objectBucketClaim.SetNamespace(meta.Namespace)
+	objectBucketClaim.SetLabels(meta.Labels)
+
+	return objectBucketClaim
+
+}
+
+// GetVolumeSnapshotDefinition returns a VolumeSnapshot of the provided PersistentVolumeClaim
+func GetVolumeSnapshotDefinition(meta metav1.ObjectMeta, persistentVolumeClaimName string) *unstructured.Unstructured {
+
+	volumeSnapshot := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"source": map[string]interface{}{
+					"kind": "PersistentVolumeClaim",
+					"name": persistentVolumeClaimName,
+				},
+			},
+		},
+	}
+
+	volumeSnapshot.SetAPIVersion(constants.VolumeSnapshotAPIVersion)
+	volumeSnapshot.SetKind(constants.VolumeSnapshotKind)
+	volumeSnapshot.SetName(meta.Name)
+	volumeSnapshot.SetNamespace(meta.Namespace)
+	volumeSnapshot.SetLabels(meta.Labels)
+
+	return volumeSnapshot
+
+}
@@ -22,6 +22,12 @@ type QuayConfiguration struct {
 	QuayDatabase                    DatabaseConfig
 	ProvisionQuayDatabase           bool
 
+	ValidProvidedClairDatabaseSecret bool
+	ClairDatabase                    DatabaseConfig
+
+	// Registry Backends
+	RegistryBackendCredentials map[string]map[string]string
+
 	// Redis
 	RedisHostname string
 	RedisPort     *int32
@@ -34,6 +40,8 @@ type QuayConfiguration struct {
 	// SecurityScannerKeyNotes			string
 	SecurityScannerKeyKid        string
 	SecurityScannerKeyPrivateKey string
+	// SecurityScannerKey is the key created through the config app which has yet to be recorded in the status
+	SecurityScannerKey *redhatcopv1alpha1.SecurityScannerKeyStatus
 
 	// Quay
 	QuayHostname                          string
@@ -47,9 +55,13 @@ type QuayConfiguration struct {
 	DeployQuayConfiguration               bool
 	QuaySslCertificate                    []byte
 	QuaySslPrivateKey                     []byte
+	QuayExtraCACerts                      map[string][]byte
 
 	//Clair
-	ClairHostname string
+	ClairHostname      string
+	ClairPaginationKey string
+	ClairPSK           string
+	ClairExtraCACerts  map[string][]byte
 }
 
 // DatabaseConfig is an internal structure representing a database
@@ -0,0 +1,165 @@
+package setup
+
+import (
+	"fmt"
+	"time"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+)
+
+// GetSecurityScannerKeyRotationTime returns the time at which the security scanner key is to be rotated and whether the key expires at all
+func GetSecurityScannerKeyRotationTime(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) (time.Time, bool) {
+
+	if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.SecurityScannerKeyExpiration) {
+		return time.Time{}, false
+	}
+
+	keyLifetime, err := time.ParseDuration(quayEcosystem.Spec.Clair.SecurityScannerKeyExpiration)
+
+	if err != nil || keyLifetime <= 0 {
+		return time.Time{}, false
+	}
+
+	keyStatus := quayEcosystem.Status.SecurityScannerKey
+
+	// Keys created without an expiration are replaced immediately
+	if keyStatus == nil || keyStatus.ExpiresAt == nil {
+		return time.Time{}, true
+	}
+
+	// A shortened expiration applies to the current key as well
+	if currentLifetime := keyStatus.ExpiresAt.Sub(keyStatus.CreatedAt.Time); currentLifetime < keyLifetime {
+		keyLifetime = currentLifetime
+	}
+
+	return keyStatus.CreatedAt.Add(time.Duration(float64(keyLifetime) * constants.SecurityScannerKeyRotationThreshold)), true
+}
+
+// IsSecurityScannerKeyRotationDue returns whether the security scanner key is to be rotated at the given time
+func IsSecurityScannerKeyRotationDue(quayEcosystem *redhatcopv1alpha1.QuayEcosystem, now time.Time) bool {
+
+	rotationTime, expires := GetSecurityScannerKeyRotationTime(quayEcosystem)
+
+	return expires && !now.Before(rotationTime)
+}
+
+// HasPreviousSecurityScannerKeys returns whether security scanner keys replaced by a rotation remain to be deleted
+func HasPreviousSecurityScannerKeys(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) bool {
+	return quayEcosystem.Status.SecurityScannerKey != nil && len(quayEcosystem.Status.SecurityScannerKey.PreviousIDs) > 0
+}
+
+// RotateSecurityScannerKey creates the key replacing the security scanner key in use. The replaced key is kept until Clair
+// has been rolled out with the new key
+func (qm *QuaySetupManager) RotateSecurityScannerKey(quaySetupInstance *QuaySetupInstance, quayConfiguration *resources.QuayConfiguration) error {
+
+	var previousID string
+
+	if quayConfiguration.QuayEcosystem.Status.SecurityScannerKey != nil {
+		previousID = quayConfiguration.QuayEcosystem.Status.SecurityScannerKey.ID
+	}
+
+	if err := qm.SetupSecurityScannerKey(quaySetupInstance, quayConfiguration); err != nil {
+		return err
+	}
+
+	if !utils.IsZeroOfUnderlyingType(previousID) && previousID != quayConfiguration.SecurityScannerKeyKid {
+		quayConfiguration.SecurityScannerKey.PreviousIDs = append(quayConfiguration.SecurityScannerKey.PreviousIDs, previousID)
+	}
+
+	return nil
+}
+
+// RecordSecurityScannerKey records the security scanner key created by SetupSecurityScannerKey or RotateSecurityScannerKey
+// in the QuayEcosystem status. It is called once the private key and the Clair configuration referencing the key have
+// been stored, so that the status never references a key Clair cannot use
+func RecordSecurityScannerKey(quayConfiguration *resources.QuayConfiguration) {
+
+	if quayConfiguration.SecurityScannerKey == nil {
+		return
+	}
+
+	quayConfiguration.QuayEcosystem.Status.SecurityScannerKey = quayConfiguration.SecurityScannerKey
+	quayConfiguration.SecurityScannerKey = nil
+}
+
+// DeletePreviousSecurityScannerKeys deletes the security scanner keys replaced by a rotation
+func (*QuaySetupManager) DeletePreviousSecurityScannerKeys(quaySetupInstance *QuaySetupInstance, quayConfiguration *resources.QuayConfiguration) error {
+
+	keyStatus := quayConfiguration.QuayEcosystem.Status.SecurityScannerKey
+
+	if keyStatus == nil || len(keyStatus.PreviousIDs) == 0 {
+		return nil
+	}
+
+	if err := deleteSecurityScannerKeys(quaySetupInstance, keyStatus.PreviousIDs); err != nil {
+		return err
+	}
+
+	keyStatus.PreviousIDs = nil
+
+	return nil
+}
+
+// RevokeSecurityScannerKeys deletes the security scanner key in use along with the keys replaced by a rotation once Clair
+// no longer authenticates against Quay with them
+func (*QuaySetupManager) RevokeSecurityScannerKeys(quaySetupInstance *QuaySetupInstance, quayConfiguration *resources.QuayConfiguration) error {
+
+	keyStatus := quayConfiguration.QuayEcosystem.Status.SecurityScannerKey
+
+	if keyStatus == nil {
+		return nil
+	}
+
+	if err := deleteSecurityScannerKeys(quaySetupInstance, append([]string{keyStatus.ID}, keyStatus.PreviousIDs...)); err != nil {
+		return err
+	}
+
+	quayConfiguration.SecurityScannerKeyKid = ""
+	quayConfiguration.SecurityScannerKeyPrivateKey = ""
+	quayConfiguration.SecurityScannerKey = nil
+	quayConfiguration.QuayEcosystem.Status.SecurityScannerKey = nil
+
+	return nil
+}
+
+// deleteSecurityScannerKeys deletes the given security scanner keys from Quay
+func deleteSecurityScannerKeys(quaySetupInstance *QuaySetupInstance, keyIDs []string) error {
+
+	_, quayKeys, err := quaySetupInstance.setupClient.GetQuayKeys()
+
+	if err != nil {
+		logging.Log.Error(err, "Failed to list service keys")
+		return fmt.Errorf("Failed to list service keys: %s", err.Error())
+	}
+
+	existingKeys := map[string]bool{}
+
+	for _, quayKey := range quayKeys.Keys {
+		existingKeys[quayKey.Kid] = true
+	}
+
+	for _, keyID := range keyIDs {
+
+		// Keys that have already expired or were removed manually are skipped
+		if !existingKeys[keyID] {
+			continue
+		}
+
+		resp, _, err := quaySetupInstance.setupClient.DeleteQuayKey(keyID)
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to delete security scanner key", "Kid", keyID)
+			return fmt.Errorf("Failed to delete security scanner key %s: %s", keyID, err.Error())
+		}
+
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("Failed to delete security scanner key %s: Received status code %d", keyID, resp.StatusCode)
+		}
+	}
+
+	return nil
+}
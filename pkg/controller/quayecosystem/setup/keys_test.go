@@ -0,0 +1,349 @@
+package setup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/client"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsSecurityScannerKeyRotationDue(t *testing.T) {
+
+	createdAt := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := metav1.NewTime(createdAt.Add(100 * time.Hour))
+
+	quayEcosystem := func(expiration string, keyStatus *redhatcopv1alpha1.SecurityScannerKeyStatus) *redhatcopv1alpha1.QuayEcosystem {
+		return &redhatcopv1alpha1.QuayEcosystem{
+			Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+				Clair: redhatcopv1alpha1.Clair{
+					SecurityScannerKeyExpiration: expiration,
+				},
+			},
+			Status: redhatcopv1alpha1.QuayEcosystemStatus{
+				SecurityScannerKey: keyStatus,
+			},
+		}
+	}
+
+	keyStatus := &redhatcopv1alpha1.SecurityScannerKeyStatus{
+		CreatedAt: metav1.NewTime(createdAt),
+		ExpiresAt: &expiresAt,
+		ID:        "kid",
+	}
+
+	cases := []struct {
+		quayEcosystem *redhatcopv1alpha1.QuayEcosystem
+		now           time.Time
+		expected      bool
+	}{
+		{
+			quayEcosystem: quayEcosystem("", keyStatus),
+			now:           createdAt.Add(1000 * time.Hour),
+			expected:      false,
+		},
+		{
+			quayEcosystem: quayEcosystem("100h", nil),
+			now:           createdAt,
+			expected:      true,
+		},
+		{
+			quayEcosystem: quayEcosystem("100h", &redhatcopv1alpha1.SecurityScannerKeyStatus{CreatedAt: metav1.NewTime(createdAt), ID: "kid"}),
+			now:           createdAt,
+			expected:      true,
+		},
+		{
+			quayEcosystem: quayEcosystem("100h", keyStatus),
+			now:           createdAt.Add(79 * time.Hour),
+			expected:      false,
+		},
+		{
+			quayEcosystem: quayEcosystem("100h", keyStatus),
+			now:           createdAt.Add(80 * time.Hour),
+			expected:      true,
+		},
+		{
+			quayEcosystem: quayEcosystem("10h", keyStatus),
+			now:           createdAt.Add(8 * time.Hour),
+			expected:      true,
+		},
+		{
+			quayEcosystem: quayEcosystem("1000h", keyStatus),
+			now:           createdAt.Add(79 * time.Hour),
+			expected:      false,
+		},
+	}
+
+	for i, c := range cases {
+		result := IsSecurityScannerKeyRotationDue(c.quayEcosystem, c.now)
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+// newServiceKeysServer returns a server emulating the service key API of the Quay config app along with the IDs of the keys it holds
+func newServiceKeysServer() (*httptest.Server, func() []string) {
+
+	var lock sync.Mutex
+	keys := map[string]bool{}
+	created := 0
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/v1/superuser/keys":
+			created++
+			kid := fmt.Sprintf("kid-%d", created)
+			keys[kid] = true
+			json.NewEncoder(w).Encode(client.SecurityScannerKey{Kid: kid, PrivateKey: fmt.Sprintf("private-key-%d", created)})
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v1/superuser/keys":
+			quayKeys := client.QuayKeys{Keys: []client.QuayKey{}}
+			for kid := range keys {
+				quayKeys.Keys = append(quayKeys.Keys, client.QuayKey{Kid: kid})
+			}
+			json.NewEncoder(w).Encode(quayKeys)
+		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/api/v1/superuser/keys/"):
+			delete(keys, strings.TrimPrefix(req.URL.Path, "/api/v1/superuser/keys/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server, func() []string {
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		kids := []string{}
+		for kid := range keys {
+			kids = append(kids, kid)
+		}
+		sort.Strings(kids)
+
+		return kids
+	}
+}
+
+func TestDisableAndReenableSecurityScannerKey(t *testing.T) {
+
+	server, getKeys := newServiceKeysServer()
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	quayConfiguration := &resources.QuayConfiguration{
+		QuayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "quay",
+				Namespace: "quay-enterprise",
+			},
+		},
+		QuayConfigHostname: serverURL.Host,
+	}
+
+	quaySetupManager := &QuaySetupManager{}
+
+	quaySetupInstance, err := quaySetupManager.NewQuaySetupInstance(quayConfiguration)
+
+	if err != nil {
+		t.Fatalf("Failed to obtain QuaySetupInstance: %v", err)
+	}
+
+	// Setup creates the initial key
+	if err := quaySetupManager.SetupSecurityScannerKey(quaySetupInstance, quayConfiguration); err != nil {
+		t.Fatalf("Failed to create security scanner key: %v", err)
+	}
+
+	RecordSecurityScannerKey(quayConfiguration)
+
+	// The initial key is replaced by a rotation and kept until Clair has picked up the new key
+	if err := quaySetupManager.RotateSecurityScannerKey(quaySetupInstance, quayConfiguration); err != nil {
+		t.Fatalf("Failed to rotate security scanner key: %v", err)
+	}
+
+	RecordSecurityScannerKey(quayConfiguration)
+
+	cases := []struct {
+		operation    func() error
+		expectedKeys []string
+		expectedID   string
+	}{
+		{
+			// Disabling Clair revokes the key in use along with the rotated key
+			operation: func() error {
+				return quaySetupManager.RevokeSecurityScannerKeys(quaySetupInstance, quayConfiguration)
+			},
+			expectedKeys: []string{},
+			expectedID:   "",
+		},
+		{
+			// Revoking keys that are no longer recorded is a no-op
+			operation: func() error {
+				return quaySetupManager.RevokeSecurityScannerKeys(quaySetupInstance, quayConfiguration)
+			},
+			expectedKeys: []string{},
+			expectedID:   "",
+		},
+		{
+			// Enabling Clair again creates a new key
+			operation: func() error {
+				if err := quaySetupManager.SetupSecurityScannerKey(quaySetupInstance, quayConfiguration); err != nil {
+					return err
+				}
+				RecordSecurityScannerKey(quayConfiguration)
+				return nil
+			},
+			expectedKeys: []string{"kid-3"},
+			expectedID:   "kid-3",
+		},
+	}
+
+	for i, c := range cases {
+
+		if err := c.operation(); err != nil {
+			t.Fatalf("Test case %d returned an error: %v", i, err)
+		}
+
+		if keys := getKeys(); !reflect.DeepEqual(c.expectedKeys, keys) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expectedKeys, keys)
+		}
+
+		var id string
+
+		if keyStatus := quayConfiguration.QuayEcosystem.Status.SecurityScannerKey; keyStatus != nil {
+			id = keyStatus.ID
+
+			if len(keyStatus.PreviousIDs) > 0 {
+				t.Errorf("Test case %d kept previous keys: %#v", i, keyStatus.PreviousIDs)
+			}
+		}
+
+		if c.expectedID != id || c.expectedID != quayConfiguration.SecurityScannerKeyKid {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expectedID, id)
+		}
+	}
+}
+
+func TestRotateSecurityScannerKey(t *testing.T) {
+
+	server, getKeys := newServiceKeysServer()
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	quayConfiguration := &resources.QuayConfiguration{
+		QuayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "quay",
+				Namespace: "quay-enterprise",
+			},
+			Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+				Clair: redhatcopv1alpha1.Clair{
+					SecurityScannerKeyExpiration: "100h",
+				},
+			},
+		},
+		QuayConfigHostname: serverURL.Host,
+	}
+
+	quaySetupManager := &QuaySetupManager{}
+
+	quaySetupInstance, err := quaySetupManager.NewQuaySetupInstance(quayConfiguration)
+
+	if err != nil {
+		t.Fatalf("Failed to obtain QuaySetupInstance: %v", err)
+	}
+
+	cases := []struct {
+		operation           func() error
+		expectedKeys        []string
+		expectedID          string
+		expectedPreviousIDs []string
+	}{
+		{
+			// A created key is not recorded until it has been stored
+			operation: func() error {
+				return quaySetupManager.SetupSecurityScannerKey(quaySetupInstance, quayConfiguration)
+			},
+			expectedKeys: []string{"kid-1"},
+			expectedID:   "",
+		},
+		{
+			operation: func() error {
+				RecordSecurityScannerKey(quayConfiguration)
+				return nil
+			},
+			expectedKeys: []string{"kid-1"},
+			expectedID:   "kid-1",
+		},
+		{
+			// The rotation leaves the status referencing the key in use until the new key has been stored
+			operation: func() error {
+				return quaySetupManager.RotateSecurityScannerKey(quaySetupInstance, quayConfiguration)
+			},
+			expectedKeys: []string{"kid-1", "kid-2"},
+			expectedID:   "kid-1",
+		},
+		{
+			operation: func() error {
+				RecordSecurityScannerKey(quayConfiguration)
+				return nil
+			},
+			expectedKeys:        []string{"kid-1", "kid-2"},
+			expectedID:          "kid-2",
+			expectedPreviousIDs: []string{"kid-1"},
+		},
+		{
+			// The replaced key is deleted once Clair has been rolled out with the new key
+			operation: func() error {
+				return quaySetupManager.DeletePreviousSecurityScannerKeys(quaySetupInstance, quayConfiguration)
+			},
+			expectedKeys: []string{"kid-2"},
+			expectedID:   "kid-2",
+		},
+	}
+
+	for i, c := range cases {
+
+		if err := c.operation(); err != nil {
+			t.Fatalf("Test case %d returned an error: %v", i, err)
+		}
+
+		if keys := getKeys(); !reflect.DeepEqual(c.expectedKeys, keys) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expectedKeys, keys)
+		}
+
+		var id string
+		var previousIDs []string
+
+		if keyStatus := quayConfiguration.QuayEcosystem.Status.SecurityScannerKey; keyStatus != nil {
+			id = keyStatus.ID
+			previousIDs = keyStatus.PreviousIDs
+
+			if keyStatus.ExpiresAt == nil || keyStatus.ExpiresAt.Sub(keyStatus.CreatedAt.Time) != 100*time.Hour {
+				t.Errorf("Test case %d recorded an unexpected expiry: %#v", i, keyStatus)
+			}
+		}
+
+		if c.expectedID != id || !reflect.DeepEqual(c.expectedPreviousIDs, previousIDs) {
+			t.Errorf("Test case %d did not match\nExpected: %#v %#v\nActual: %#v %#v", i, c.expectedID, c.expectedPreviousIDs, id, previousIDs)
+		}
+	}
+}
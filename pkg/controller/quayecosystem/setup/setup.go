@@ -4,7 +4,9 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"time"
 
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 
 	"github.com/redhat-cop/operator-utils/pkg/util"
@@ -12,6 +14,7 @@ import (
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -77,6 +80,17 @@ func (*QuaySetupManager) PrepareForSetup(kclient kclient.Client, quayConfigurati
 
 	quayConfiguration.QuayDatabase.Server = postgresqlHost
 
+	// Clair uses the Quay database server unless a dedicated database is specified
+	clairPostgresqlHost := quayConfiguration.QuayEcosystem.Spec.Clair.Database.Server
+
+	if !resources.IsClairDatabaseDedicated(quayConfiguration.QuayEcosystem) {
+		clairPostgresqlHost = postgresqlHost
+	} else if utils.IsZeroOfUnderlyingType(clairPostgresqlHost) {
+		clairPostgresqlHost = resources.GetClairDatabaseName(quayConfiguration.QuayEcosystem)
+	}
+
+	quayConfiguration.ClairDatabase.Server = clairPostgresqlHost
+
 	redisHost := quayConfiguration.QuayEcosystem.Spec.Redis.Hostname
 
 	if utils.IsZeroOfUnderlyingType(redisHost) {
@@ -195,21 +209,18 @@ func (qm *QuaySetupManager) SetupQuay(quaySetupInstance *QuaySetupInstance) erro
 	// 	return fmt.Errorf("Failed to get security scanner key: %s", err.Error())
 	// }
 
-	//TODO Change to the correct clair endpoint
-	quayConfig.Config["SECURITY_SCANNER_ENDPOINT"] = fmt.Sprintf("http://%s", quaySetupInstance.quayConfiguration.ClairHostname)
-	quayConfig.Config["SECURITY_SCANNER_ISSUER_NAME"] = "security_scanner"
-	quayConfig.Config["FEATURE_SECURITY_SCANNER"] = true
+	resources.SetSecurityScannerConfig(quayConfig.Config, &quaySetupInstance.quayConfiguration)
 
 	// Setup Storage
 	distributedStorageConfig := map[string][]interface{}{}
 
 	for _, registryBackend := range quaySetupInstance.quayConfiguration.QuayEcosystem.Spec.Quay.RegistryBackends {
 
-		var quayRegistry []interface{}
+		quayRegistry, err := getRegistryBackendConfiguration(registryBackend, quaySetupInstance.quayConfiguration.RegistryBackendCredentials[registryBackend.Name])
 
-		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Local) {
-			quayRegistry = append(quayRegistry, constants.RegistryStorageTypeLocalStorageName)
-			quayRegistry = append(quayRegistry, registryBackend.RegistryBackendSource.Local)
+		if err != nil {
+			logging.Log.Error(err, "Failed to configure registry backend", "Name", registryBackend.Name)
+			return fmt.Errorf("Failed to configure registry backend %s: %s", registryBackend.Name, err.Error())
 		}
 
 		distributedStorageConfig[registryBackend.Name] = quayRegistry
@@ -217,6 +228,7 @@ func (qm *QuaySetupManager) SetupQuay(quaySetupInstance *QuaySetupInstance) erro
 	}
 
 	quayConfig.Config["DISTRIBUTED_STORAGE_CONFIG"] = distributedStorageConfig
+	resources.SetStorageReplicationConfig(quayConfig.Config, quaySetupInstance.quayConfiguration.QuayEcosystem)
 
 	// Add Certificates
 	_, _, err = quaySetupInstance.setupClient.UploadFileResource(constants.QuayAppConfigSSLPrivateKeySecretKey, quaySetupInstance.quayConfiguration.QuaySslPrivateKey)
@@ -232,6 +244,23 @@ func (qm *QuaySetupManager) SetupQuay(quaySetupInstance *QuaySetupInstance) erro
 		return err
 	}
 
+	// Add Registry Backend CA Certificates
+	for _, registryBackend := range quaySetupInstance.quayConfiguration.QuayEcosystem.Spec.Quay.RegistryBackends {
+
+		caCertificate, found := quaySetupInstance.quayConfiguration.RegistryBackendCredentials[registryBackend.Name][constants.RegistryBackendCACertificateKey]
+
+		if !found {
+			continue
+		}
+
+		_, _, err = quaySetupInstance.setupClient.UploadFileResource(getRegistryBackendCACertificateFileName(registryBackend.Name), []byte(caCertificate))
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to upload registry backend CA certificate", "Name", registryBackend.Name)
+			return fmt.Errorf("Failed to upload registry backend CA certificate: %s", err.Error())
+		}
+	}
+
 	// Validate multiple components
 	for _, validationComponent := range []client.QuayValidationType{client.RedisValidation, client.RegistryValidation, client.TimeMachineValidation, client.AccessValidation, client.SslValidation} {
 		err = qm.validateComponent(quaySetupInstance, quayConfig, validationComponent)
@@ -263,15 +292,34 @@ func (qm *QuaySetupManager) SetupQuay(quaySetupInstance *QuaySetupInstance) erro
 
 }
 
+// SetupSecurityScannerKey creates the service key used by Clair to authenticate against Quay. The key is recorded in the
+// QuayEcosystem status by RecordSecurityScannerKey once it has been stored
 func (*QuaySetupManager) SetupSecurityScannerKey(quaySetupInstance *QuaySetupInstance, quayConfiguration *resources.QuayConfiguration) error {
 
-	//TODO Convert to parameter
+	createdAt := metav1.Now()
+
+	// Keys do not expire unless an expiration is specified
+	var expiration interface{}
+	var expiresAt *metav1.Time
+
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.SecurityScannerKeyExpiration) {
+
+		keyLifetime, err := time.ParseDuration(quayConfiguration.QuayEcosystem.Spec.Clair.SecurityScannerKeyExpiration)
+
+		if err != nil {
+			return fmt.Errorf("Failed to parse security scanner key expiration: %s", err.Error())
+		}
+
+		expiresAt = &metav1.Time{Time: createdAt.Add(keyLifetime)}
+		expiration = expiresAt.Unix()
+	}
+
 	var securityScannerKey client.SecurityScannerKey
 	_, securityScannerKey, err := quaySetupInstance.setupClient.CreateSecurityScannerKey(client.QuayCreateSecurityScannerKeyRequest{
-		Name:       "security_scanner Service Key",
-		Service:    "security_scanner",
-		Expiration: nil,
-		Notes:      "Created during setup for service `security_scanner`",
+		Name:       constants.SecurityScannerKeyName,
+		Service:    constants.SecurityScannerKeyService,
+		Expiration: expiration,
+		Notes:      fmt.Sprintf("Created by the Quay Operator for service `%s`", constants.SecurityScannerKeyService),
 	})
 
 	if err != nil {
@@ -279,9 +327,26 @@ func (*QuaySetupManager) SetupSecurityScannerKey(quaySetupInstance *QuaySetupIns
 		return fmt.Errorf("Failed to create security scanner key: %s", err.Error())
 	}
 
+	if utils.IsZeroOfUnderlyingType(securityScannerKey.Kid) {
+		return fmt.Errorf("Failed to create security scanner key: No key ID returned")
+	}
+
 	quayConfiguration.SecurityScannerKeyKid = securityScannerKey.Kid
 	quayConfiguration.SecurityScannerKeyPrivateKey = securityScannerKey.PrivateKey
 
+	var previousIDs []string
+
+	if quayConfiguration.QuayEcosystem.Status.SecurityScannerKey != nil {
+		previousIDs = append(previousIDs, quayConfiguration.QuayEcosystem.Status.SecurityScannerKey.PreviousIDs...)
+	}
+
+	quayConfiguration.SecurityScannerKey = &redhatcopv1alpha1.SecurityScannerKeyStatus{
+		CreatedAt:   createdAt,
+		ExpiresAt:   expiresAt,
+		ID:          securityScannerKey.Kid,
+		PreviousIDs: previousIDs,
+	}
+
 	return nil
 }
 
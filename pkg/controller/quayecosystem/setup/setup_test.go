@@ -0,0 +1,68 @@
+package setup
+
+import (
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClairDatabaseServer(t *testing.T) {
+
+	cases := []struct {
+		database redhatcopv1alpha1.Database
+		expected string
+	}{
+		{
+			database: redhatcopv1alpha1.Database{},
+			expected: "quay-quay-postgresql",
+		},
+		{
+			// Settings tuning the database do not select a dedicated database
+			database: redhatcopv1alpha1.Database{
+				RetentionPolicy: redhatcopv1alpha1.DeletePersistentVolumeClaimRetentionPolicy,
+				VolumeSize:      "10Gi",
+			},
+			expected: "quay-quay-postgresql",
+		},
+		{
+			database: redhatcopv1alpha1.Database{
+				CredentialsSecretName: "clair-database-credentials",
+			},
+			expected: "quay-clair-postgresql",
+		},
+		{
+			database: redhatcopv1alpha1.Database{
+				CredentialsSecretName: "clair-database-credentials",
+				Server:                "postgresql.example.com",
+			},
+			expected: "postgresql.example.com",
+		},
+	}
+
+	for i, c := range cases {
+
+		quayConfiguration := &resources.QuayConfiguration{
+			QuayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "quay",
+					Namespace: "quay-enterprise",
+				},
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						Database: c.database,
+					},
+				},
+			},
+		}
+
+		if err := (&QuaySetupManager{}).PrepareForSetup(nil, quayConfiguration); err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		if quayConfiguration.ClairDatabase.Server != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, quayConfiguration.ClairDatabase.Server)
+		}
+	}
+}
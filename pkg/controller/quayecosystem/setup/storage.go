@@ -0,0 +1,228 @@
+package setup
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+)
+
+// getRegistryBackendConfiguration returns the Quay storage driver name and parameters for a registry backend
+func getRegistryBackendConfiguration(registryBackend redhatcopv1alpha1.RegistryBackend, credentials map[string]string) ([]interface{}, error) {
+
+	var quayRegistry []interface{}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Local) {
+		quayRegistry = append(quayRegistry, constants.RegistryStorageTypeLocalStorageName)
+		quayRegistry = append(quayRegistry, registryBackend.RegistryBackendSource.Local)
+	}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.S3) {
+		storageType, storageConfig, err := getS3StorageConfiguration(registryBackend.RegistryBackendSource.S3, credentials)
+
+		if err != nil {
+			return nil, err
+		}
+
+		quayRegistry = append(quayRegistry, storageType)
+		quayRegistry = append(quayRegistry, storageConfig)
+	}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Azure) {
+		quayRegistry = append(quayRegistry, constants.RegistryStorageTypeAzureStorageName)
+		quayRegistry = append(quayRegistry, getAzureStorageConfiguration(registryBackend.RegistryBackendSource.Azure, credentials))
+	}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.GoogleCloud) {
+		quayRegistry = append(quayRegistry, constants.RegistryStorageTypeGoogleCloudStorageName)
+		quayRegistry = append(quayRegistry, map[string]interface{}{
+			"bucket_name":  registryBackend.RegistryBackendSource.GoogleCloud.Bucket,
+			"storage_path": utils.CheckValue(registryBackend.RegistryBackendSource.GoogleCloud.StoragePath, constants.QuayRegistryStoragePath).(string),
+			"access_key":   credentials[constants.RegistryBackendAccessKeyKey],
+			"secret_key":   credentials[constants.RegistryBackendSecretKeyKey],
+		})
+	}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Swift) {
+		quayRegistry = append(quayRegistry, constants.RegistryStorageTypeSwiftStorageName)
+		quayRegistry = append(quayRegistry, getSwiftStorageConfiguration(registryBackend, credentials))
+	}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.ObjectBucketClaim) {
+		storageType, storageConfig, err := getObjectBucketClaimStorageConfiguration(registryBackend.RegistryBackendSource.ObjectBucketClaim, credentials)
+
+		if err != nil {
+			return nil, err
+		}
+
+		quayRegistry = append(quayRegistry, storageType)
+		quayRegistry = append(quayRegistry, storageConfig)
+	}
+
+	return quayRegistry, nil
+}
+
+// getS3StorageConfiguration renders an S3 backend as S3Storage, or RadosGWStorage when a custom endpoint is provided
+func getS3StorageConfiguration(s3 *redhatcopv1alpha1.S3RegistryBackendSource, credentials map[string]string) (string, map[string]interface{}, error) {
+
+	storagePath := utils.CheckValue(s3.StoragePath, constants.QuayRegistryStoragePath).(string)
+
+	if utils.IsZeroOfUnderlyingType(s3.Endpoint) {
+
+		storageConfig := map[string]interface{}{
+			"s3_bucket":    s3.Bucket,
+			"storage_path": storagePath,
+		}
+
+		setS3Credentials(storageConfig, credentials, "s3_access_key", "s3_secret_key")
+
+		if !utils.IsZeroOfUnderlyingType(s3.Region) {
+			storageConfig["s3_region"] = s3.Region
+		}
+
+		return constants.RegistryStorageTypeS3StorageName, storageConfig, nil
+	}
+
+	endpoint, err := url.Parse(s3.Endpoint)
+
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to parse S3 endpoint %s: %s", s3.Endpoint, err.Error())
+	}
+
+	storageConfig := map[string]interface{}{
+		"hostname":     endpoint.Hostname(),
+		"is_secure":    endpoint.Scheme == "https",
+		"bucket_name":  s3.Bucket,
+		"storage_path": storagePath,
+	}
+
+	setS3Credentials(storageConfig, credentials, "access_key", "secret_key")
+
+	if !utils.IsZeroOfUnderlyingType(endpoint.Port()) {
+		port, err := strconv.Atoi(endpoint.Port())
+
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to parse S3 endpoint port %s: %s", endpoint.Port(), err.Error())
+		}
+
+		storageConfig["port"] = port
+	}
+
+	return constants.RegistryStorageTypeRadosGWStorageName, storageConfig, nil
+}
+
+// setS3Credentials adds the access keys provided in the credentials secret of an S3 backend. Without a secret, Quay falls
+// back to the credentials of the instance or its service account
+func setS3Credentials(storageConfig map[string]interface{}, credentials map[string]string, accessKeyName string, secretKeyName string) {
+
+	if accessKey, found := credentials[constants.RegistryBackendAccessKeyKey]; found {
+		storageConfig[accessKeyName] = accessKey
+	}
+
+	if secretKey, found := credentials[constants.RegistryBackendSecretKeyKey]; found {
+		storageConfig[secretKeyName] = secretKey
+	}
+}
+
+// getObjectBucketClaimStorageConfiguration renders a bucket provisioned through an ObjectBucketClaim as RadosGWStorage
+func getObjectBucketClaimStorageConfiguration(objectBucketClaim *redhatcopv1alpha1.ObjectBucketClaimRegistryBackendSource, credentials map[string]string) (string, map[string]interface{}, error) {
+
+	if _, found := credentials[constants.ObjectBucketClaimBucketHostKey]; !found {
+		return "", nil, fmt.Errorf("ObjectBucketClaim has not been bound")
+	}
+
+	bucketHost := credentials[constants.ObjectBucketClaimBucketHostKey]
+
+	scheme := "http"
+
+	if objectBucketClaim.Secure {
+		scheme = "https"
+	}
+
+	// Some bucket provisioners publish the host as a URL carrying the scheme of the endpoint
+	if bucketURL, err := url.Parse(bucketHost); err == nil && !utils.IsZeroOfUnderlyingType(bucketURL.Scheme) && !utils.IsZeroOfUnderlyingType(bucketURL.Hostname()) {
+		scheme = bucketURL.Scheme
+		bucketHost = bucketURL.Hostname()
+	}
+
+	s3 := &redhatcopv1alpha1.S3RegistryBackendSource{
+		Bucket:      credentials[constants.ObjectBucketClaimBucketNameKey],
+		Endpoint:    fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(bucketHost, credentials[constants.ObjectBucketClaimBucketPortKey])),
+		StoragePath: objectBucketClaim.StoragePath,
+	}
+
+	return getS3StorageConfiguration(s3, map[string]string{
+		constants.RegistryBackendAccessKeyKey: credentials[constants.ObjectBucketClaimAccessKeyIDKey],
+		constants.RegistryBackendSecretKeyKey: credentials[constants.ObjectBucketClaimSecretAccessKeyKey],
+	})
+}
+
+// getAzureStorageConfiguration renders an Azure Blob Storage backend authenticating with either an account key or a SAS token
+func getAzureStorageConfiguration(azure *redhatcopv1alpha1.AzureRegistryBackendSource, credentials map[string]string) map[string]interface{} {
+
+	storageConfig := map[string]interface{}{
+		"azure_container":    azure.Container,
+		"azure_account_name": azure.AccountName,
+		"storage_path":       utils.CheckValue(azure.StoragePath, constants.QuayRegistryStoragePath).(string),
+	}
+
+	if accountKey, found := credentials[constants.RegistryBackendAzureAccountKeyKey]; found {
+		storageConfig["azure_account_key"] = accountKey
+	}
+
+	if sasToken, found := credentials[constants.RegistryBackendAzureSasTokenKey]; found {
+		storageConfig["sas_token"] = sasToken
+	}
+
+	return storageConfig
+}
+
+// getSwiftStorageConfiguration renders an OpenStack Swift backend authenticating with either a user/password or an application credential
+func getSwiftStorageConfiguration(registryBackend redhatcopv1alpha1.RegistryBackend, credentials map[string]string) map[string]interface{} {
+
+	swift := registryBackend.RegistryBackendSource.Swift
+
+	storageConfig := map[string]interface{}{
+		"swift_container": swift.Container,
+		"storage_path":    utils.CheckValue(swift.StoragePath, constants.QuayRegistryStoragePath).(string),
+		"auth_url":        swift.AuthURL,
+	}
+
+	if !utils.IsZeroOfUnderlyingType(swift.AuthVersion) {
+		storageConfig["auth_version"] = swift.AuthVersion
+	}
+
+	osOptions := map[string]interface{}{}
+
+	for key, value := range swift.OSOptions {
+		osOptions[key] = value
+	}
+
+	if applicationCredentialID, found := credentials[constants.RegistryBackendSwiftApplicationCredentialIDKey]; found {
+		osOptions["auth_type"] = "v3applicationcredential"
+		osOptions["application_credential_id"] = applicationCredentialID
+		osOptions["application_credential_secret"] = credentials[constants.RegistryBackendSwiftApplicationCredentialSecretKey]
+	} else {
+		storageConfig["swift_user"] = credentials[constants.RegistryBackendSwiftUserKey]
+		storageConfig["swift_password"] = credentials[constants.RegistryBackendSwiftPasswordKey]
+	}
+
+	if len(osOptions) > 0 {
+		storageConfig["os_options"] = osOptions
+	}
+
+	if _, found := credentials[constants.RegistryBackendCACertificateKey]; found {
+		storageConfig["ca_cert_path"] = fmt.Sprintf("%s/%s", constants.QuayConfigDirectory, getRegistryBackendCACertificateFileName(registryBackend.Name))
+	}
+
+	return storageConfig
+}
+
+// getRegistryBackendCACertificateFileName returns the name of the CA certificate for a registry backend within the Quay configuration
+func getRegistryBackendCACertificateFileName(registryBackendName string) string {
+	return fmt.Sprintf("%s/%s.crt", constants.QuayExtraCACertsDirectory, registryBackendName)
+}
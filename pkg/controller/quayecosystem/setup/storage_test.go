@@ -0,0 +1,373 @@
+package setup
+
+import (
+	"reflect"
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
+)
+
+func TestS3RegistryBackendConfiguration(t *testing.T) {
+
+	credentials := map[string]string{
+		constants.RegistryBackendAccessKeyKey: "minio",
+		constants.RegistryBackendSecretKeyKey: "minio123",
+	}
+
+	cases := []struct {
+		registryBackend redhatcopv1alpha1.RegistryBackend
+		credentials     map[string]string
+		expected        []interface{}
+	}{
+		{
+			registryBackend: redhatcopv1alpha1.RegistryBackend{
+				Name: "aws",
+				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+					S3: &redhatcopv1alpha1.S3RegistryBackendSource{
+						Bucket: "quay",
+						Region: "us-east-1",
+					},
+				},
+			},
+			credentials: credentials,
+			expected: []interface{}{constants.RegistryStorageTypeS3StorageName, map[string]interface{}{
+				"s3_bucket":     "quay",
+				"s3_region":     "us-east-1",
+				"storage_path":  constants.QuayRegistryStoragePath,
+				"s3_access_key": "minio",
+				"s3_secret_key": "minio123",
+			}},
+		},
+		{
+			registryBackend: redhatcopv1alpha1.RegistryBackend{
+				Name: "minio",
+				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+					S3: &redhatcopv1alpha1.S3RegistryBackendSource{
+						Bucket:      "quay",
+						Endpoint:    "http://minio.minio.svc:9000",
+						StoragePath: "/registry",
+					},
+				},
+			},
+			credentials: credentials,
+			expected: []interface{}{constants.RegistryStorageTypeRadosGWStorageName, map[string]interface{}{
+				"hostname":     "minio.minio.svc",
+				"port":         9000,
+				"is_secure":    false,
+				"bucket_name":  "quay",
+				"storage_path": "/registry",
+				"access_key":   "minio",
+				"secret_key":   "minio123",
+			}},
+		},
+		{
+			registryBackend: redhatcopv1alpha1.RegistryBackend{
+				Name: "ceph",
+				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+					S3: &redhatcopv1alpha1.S3RegistryBackendSource{
+						Bucket:   "quay",
+						Endpoint: "https://rgw.example.com",
+					},
+				},
+			},
+			credentials: credentials,
+			expected: []interface{}{constants.RegistryStorageTypeRadosGWStorageName, map[string]interface{}{
+				"hostname":     "rgw.example.com",
+				"is_secure":    true,
+				"bucket_name":  "quay",
+				"storage_path": constants.QuayRegistryStoragePath,
+				"access_key":   "minio",
+				"secret_key":   "minio123",
+			}},
+		},
+		{
+			// Without a credentials secret Quay authenticates using the instance or service account credentials
+			registryBackend: redhatcopv1alpha1.RegistryBackend{
+				Name: "aws",
+				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+					S3: &redhatcopv1alpha1.S3RegistryBackendSource{
+						Bucket: "quay",
+						Region: "us-east-1",
+					},
+				},
+			},
+			credentials: map[string]string{},
+			expected: []interface{}{constants.RegistryStorageTypeS3StorageName, map[string]interface{}{
+				"s3_bucket":    "quay",
+				"s3_region":    "us-east-1",
+				"storage_path": constants.QuayRegistryStoragePath,
+			}},
+		},
+		{
+			registryBackend: redhatcopv1alpha1.RegistryBackend{
+				Name: "ceph",
+				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+					S3: &redhatcopv1alpha1.S3RegistryBackendSource{
+						Bucket:   "quay",
+						Endpoint: "https://rgw.example.com",
+					},
+				},
+			},
+			credentials: nil,
+			expected: []interface{}{constants.RegistryStorageTypeRadosGWStorageName, map[string]interface{}{
+				"hostname":     "rgw.example.com",
+				"is_secure":    true,
+				"bucket_name":  "quay",
+				"storage_path": constants.QuayRegistryStoragePath,
+			}},
+		},
+	}
+
+	for i, c := range cases {
+		result, err := getRegistryBackendConfiguration(c.registryBackend, c.credentials)
+
+		if err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.expected, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestAzureRegistryBackendConfiguration(t *testing.T) {
+
+	registryBackend := redhatcopv1alpha1.RegistryBackend{
+		Name: "azure",
+		RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+			Azure: &redhatcopv1alpha1.AzureRegistryBackendSource{
+				AccountName: "quayaccount",
+				Container:   "quay",
+			},
+		},
+	}
+
+	cases := []struct {
+		credentials map[string]string
+		expected    []interface{}
+	}{
+		{
+			credentials: map[string]string{
+				constants.RegistryBackendAzureAccountKeyKey: "accountkey",
+			},
+			expected: []interface{}{constants.RegistryStorageTypeAzureStorageName, map[string]interface{}{
+				"azure_container":    "quay",
+				"azure_account_name": "quayaccount",
+				"azure_account_key":  "accountkey",
+				"storage_path":       constants.QuayRegistryStoragePath,
+			}},
+		},
+		{
+			credentials: map[string]string{
+				constants.RegistryBackendAzureSasTokenKey: "sastoken",
+			},
+			expected: []interface{}{constants.RegistryStorageTypeAzureStorageName, map[string]interface{}{
+				"azure_container":    "quay",
+				"azure_account_name": "quayaccount",
+				"sas_token":          "sastoken",
+				"storage_path":       constants.QuayRegistryStoragePath,
+			}},
+		},
+	}
+
+	for i, c := range cases {
+		result, err := getRegistryBackendConfiguration(registryBackend, c.credentials)
+
+		if err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.expected, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestGoogleCloudRegistryBackendConfiguration(t *testing.T) {
+
+	credentials := map[string]string{
+		constants.RegistryBackendAccessKeyKey: "GOOGACCESSKEY",
+		constants.RegistryBackendSecretKeyKey: "googsecret",
+	}
+
+	cases := []struct {
+		registryBackend redhatcopv1alpha1.RegistryBackend
+		expected        []interface{}
+	}{
+		{
+			registryBackend: redhatcopv1alpha1.RegistryBackend{
+				Name: "gcs",
+				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+					GoogleCloud: &redhatcopv1alpha1.GoogleCloudRegistryBackendSource{
+						Bucket: "quay",
+					},
+				},
+			},
+			expected: []interface{}{constants.RegistryStorageTypeGoogleCloudStorageName, map[string]interface{}{
+				"bucket_name":  "quay",
+				"storage_path": constants.QuayRegistryStoragePath,
+				"access_key":   "GOOGACCESSKEY",
+				"secret_key":   "googsecret",
+			}},
+		},
+		{
+			registryBackend: redhatcopv1alpha1.RegistryBackend{
+				Name: "gcs",
+				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+					GoogleCloud: &redhatcopv1alpha1.GoogleCloudRegistryBackendSource{
+						Bucket:      "quay",
+						StoragePath: "/registry",
+					},
+				},
+			},
+			expected: []interface{}{constants.RegistryStorageTypeGoogleCloudStorageName, map[string]interface{}{
+				"bucket_name":  "quay",
+				"storage_path": "/registry",
+				"access_key":   "GOOGACCESSKEY",
+				"secret_key":   "googsecret",
+			}},
+		},
+	}
+
+	for i, c := range cases {
+		result, err := getRegistryBackendConfiguration(c.registryBackend, credentials)
+
+		if err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.expected, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestSwiftRegistryBackendConfiguration(t *testing.T) {
+
+	swift := &redhatcopv1alpha1.SwiftRegistryBackendSource{
+		AuthURL:     "https://keystone.example.com:5000/v3",
+		AuthVersion: 3,
+		Container:   "quay",
+		OSOptions: map[string]string{
+			"project_name": "quay",
+		},
+	}
+
+	registryBackend := redhatcopv1alpha1.RegistryBackend{
+		Name: "swift",
+		RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+			Swift: swift,
+		},
+	}
+
+	cases := []struct {
+		credentials map[string]string
+		expected    []interface{}
+	}{
+		{
+			credentials: map[string]string{
+				constants.RegistryBackendSwiftUserKey:     "quay",
+				constants.RegistryBackendSwiftPasswordKey: "password",
+			},
+			expected: []interface{}{constants.RegistryStorageTypeSwiftStorageName, map[string]interface{}{
+				"swift_container": "quay",
+				"storage_path":    constants.QuayRegistryStoragePath,
+				"auth_url":        "https://keystone.example.com:5000/v3",
+				"auth_version":    3,
+				"swift_user":      "quay",
+				"swift_password":  "password",
+				"os_options": map[string]interface{}{
+					"project_name": "quay",
+				},
+			}},
+		},
+		{
+			credentials: map[string]string{
+				constants.RegistryBackendSwiftApplicationCredentialIDKey:     "credentialid",
+				constants.RegistryBackendSwiftApplicationCredentialSecretKey: "credentialsecret",
+				constants.RegistryBackendCACertificateKey:                    "certificate",
+			},
+			expected: []interface{}{constants.RegistryStorageTypeSwiftStorageName, map[string]interface{}{
+				"swift_container": "quay",
+				"storage_path":    constants.QuayRegistryStoragePath,
+				"auth_url":        "https://keystone.example.com:5000/v3",
+				"auth_version":    3,
+				"os_options": map[string]interface{}{
+					"project_name":                  "quay",
+					"auth_type":                     "v3applicationcredential",
+					"application_credential_id":     "credentialid",
+					"application_credential_secret": "credentialsecret",
+				},
+				"ca_cert_path": constants.QuayConfigDirectory + "/" + constants.QuayExtraCACertsDirectory + "/swift.crt",
+			}},
+		},
+	}
+
+	for i, c := range cases {
+		result, err := getRegistryBackendConfiguration(registryBackend, c.credentials)
+
+		if err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(c.expected, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestObjectBucketClaimRegistryBackendConfiguration(t *testing.T) {
+
+	cases := []struct {
+		objectBucketClaim *redhatcopv1alpha1.ObjectBucketClaimRegistryBackendSource
+		bucketHost        string
+		bucketPort        string
+		expectedHostname  string
+		expectedIsSecure  bool
+	}{
+		{
+			objectBucketClaim: &redhatcopv1alpha1.ObjectBucketClaimRegistryBackendSource{},
+			bucketHost:        "rook-ceph-rgw-store.rook-ceph",
+			bucketPort:        "443",
+			expectedHostname:  "rook-ceph-rgw-store.rook-ceph",
+			expectedIsSecure:  false,
+		},
+		{
+			objectBucketClaim: &redhatcopv1alpha1.ObjectBucketClaimRegistryBackendSource{Secure: true},
+			bucketHost:        "s3.openshift-storage.svc",
+			bucketPort:        "443",
+			expectedHostname:  "s3.openshift-storage.svc",
+			expectedIsSecure:  true,
+		},
+		{
+			objectBucketClaim: &redhatcopv1alpha1.ObjectBucketClaimRegistryBackendSource{},
+			bucketHost:        "https://s3.openshift-storage.svc",
+			bucketPort:        "8443",
+			expectedHostname:  "s3.openshift-storage.svc",
+			expectedIsSecure:  true,
+		},
+	}
+
+	for i, c := range cases {
+		credentials := map[string]string{
+			constants.ObjectBucketClaimBucketHostKey:      c.bucketHost,
+			constants.ObjectBucketClaimBucketPortKey:      c.bucketPort,
+			constants.ObjectBucketClaimBucketNameKey:      "quay-registry-0d8e6a",
+			constants.ObjectBucketClaimAccessKeyIDKey:     "access",
+			constants.ObjectBucketClaimSecretAccessKeyKey: "secret",
+		}
+
+		_, result, err := getObjectBucketClaimStorageConfiguration(c.objectBucketClaim, credentials)
+
+		if err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+			continue
+		}
+
+		if c.expectedHostname != result["hostname"] || c.expectedIsSecure != result["is_secure"] {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, []interface{}{c.expectedHostname, c.expectedIsSecure}, []interface{}{result["hostname"], result["is_secure"]})
+		}
+	}
+}
@@ -9,10 +9,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// SetDefaults initializes the runtime configuration and applies any spec defaults that were not set at admission time
 func SetDefaults(client client.Client, quayConfiguration *resources.QuayConfiguration) bool {
 
-	changed := false
-
 	// Initialize Base Variables
 	quayConfiguration.QuayConfigUsername = constants.QuayConfigUsername
 	quayConfiguration.QuayConfigPassword = constants.QuayConfigDefaultPasswordValue
@@ -21,58 +20,96 @@ func SetDefaults(client client.Client, quayConfiguration *resources.QuayConfigur
 	quayConfiguration.QuaySuperuserEmail = constants.QuaySuperuserDefaultEmail
 	quayConfiguration.QuayConfigPasswordSecret = resources.GetQuayConfigResourcesName(quayConfiguration.QuayEcosystem)
 
+	if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.KeepConfigDeployment) || !quayConfiguration.QuayEcosystem.Spec.Quay.KeepConfigDeployment {
+		quayConfiguration.DeployQuayConfiguration = true
+	}
+
+	if !quayConfiguration.QuayEcosystem.Status.SetupComplete {
+		quayConfiguration.DeployQuayConfiguration = true
+	}
+
+	return SetSpecDefaults(quayConfiguration.QuayEcosystem)
+}
+
+// SetSpecDefaults applies the image, storage and backend defaults to the QuayEcosystem spec
+func SetSpecDefaults(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) bool {
+
+	changed := false
+
 	// Core Quay Values
-	if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Image) {
+	if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.Image) {
 		changed = true
-		quayConfiguration.QuayEcosystem.Spec.Quay.Image = constants.QuayImage
+		quayEcosystem.Spec.Quay.Image = constants.QuayImage
 	}
-	if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Redis.Hostname) {
+	if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Redis.Hostname) {
 
-		if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Redis.Image) {
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Redis.Image) {
 			changed = true
-			quayConfiguration.QuayEcosystem.Spec.Redis.Image = constants.RedisImage
+			quayEcosystem.Spec.Redis.Image = constants.RedisImage
 		}
 
 	}
 
 	// User would like to have a database automatically provisioned if server not provided
-	if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database) || utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.Server) {
+	if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.Database) || utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.Database.Server) {
 
 		// If a user does not provide a server, one needs to be provisoned
-		if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.Server) {
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.Database.Server) {
 
-			if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.Image) {
+			if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.Database.Image) {
 				changed = true
-				quayConfiguration.QuayEcosystem.Spec.Quay.Database.Image = constants.PostgresqlImage
+				quayEcosystem.Spec.Quay.Database.Image = constants.PostgresqlImage
 			}
 		}
 
 	}
 
-	if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.KeepConfigDeployment) || !quayConfiguration.QuayEcosystem.Spec.Quay.KeepConfigDeployment {
-		quayConfiguration.DeployQuayConfiguration = true
+	// Clair
+	if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.Version) {
+		changed = true
+		quayEcosystem.Spec.Clair.Version = redhatcopv1alpha1.V2ClairVersion
 	}
 
-	if !quayConfiguration.QuayEcosystem.Status.SetupComplete {
-		quayConfiguration.DeployQuayConfiguration = true
+	if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.Image) {
+		changed = true
+
+		if quayEcosystem.Spec.Clair.Version == redhatcopv1alpha1.V4ClairVersion {
+			quayEcosystem.Spec.Clair.Image = constants.ClairV4Image
+		} else {
+			quayEcosystem.Spec.Clair.Image = constants.ClairImage
+		}
+	}
+
+	if quayEcosystem.Spec.Clair.Version == redhatcopv1alpha1.V4ClairVersion && utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.DeploymentMode) {
+		changed = true
+		quayEcosystem.Spec.Clair.DeploymentMode = redhatcopv1alpha1.CombinedClairDeploymentMode
 	}
 
-	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage) {
+	// A dedicated Clair database is only provisioned when requested. Otherwise Clair shares the Quay database server
+	if resources.IsClairDatabaseDedicated(quayEcosystem) && utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.Database.Server) {
 
-		if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeAccessModes) {
-			quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeAccessModes = constants.QuayRegistryStoragePersistentVolumeAccessModes
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Clair.Database.Image) {
 			changed = true
+			quayEcosystem.Spec.Clair.Database.Image = constants.PostgresqlImage
 		}
+	}
 
-		if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeSize) {
-			quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeSize = constants.QuayRegistryStoragePersistentVolumeStoreSize
+	if !utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.RegistryStorage) {
+
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeAccessModes) {
+			quayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeAccessModes = constants.QuayRegistryStoragePersistentVolumeAccessModes
+			changed = true
+		}
+
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeSize) {
+			quayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeSize = constants.QuayRegistryStoragePersistentVolumeStoreSize
 			changed = true
 		}
 	}
 
-	if utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.RegistryBackends) {
+	if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.RegistryBackends) {
 		// Generate Default Local Storage
-		quayConfiguration.QuayEcosystem.Spec.Quay.RegistryBackends = []redhatcopv1alpha1.RegistryBackend{
+		quayEcosystem.Spec.Quay.RegistryBackends = []redhatcopv1alpha1.RegistryBackend{
 			redhatcopv1alpha1.RegistryBackend{
 				Name: constants.RegistryStorageDefaultName,
 				RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
@@ -86,5 +123,24 @@ func SetDefaults(client client.Client, quayConfiguration *resources.QuayConfigur
 		changed = true
 	}
 
+	if quayEcosystem.Spec.HighAvailability.Enabled {
+
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.HighAvailability.Mode) {
+			quayEcosystem.Spec.HighAvailability.Mode = redhatcopv1alpha1.PreferredAntiAffinityHighAvailabilityMode
+			changed = true
+		}
+
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.HighAvailability.TopologyKey) {
+			quayEcosystem.Spec.HighAvailability.TopologyKey = constants.HighAvailabilityTopologyKey
+			changed = true
+		}
+
+		if quayEcosystem.Spec.HighAvailability.MinAvailable == nil {
+			minAvailable := constants.HighAvailabilityMinAvailable
+			quayEcosystem.Spec.HighAvailability.MinAvailable = &minAvailable
+			changed = true
+		}
+	}
+
 	return changed
 }
@@ -0,0 +1,434 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ValidateSpec performs the syntactic validation of a QuayEcosystem that does not require access to the cluster
+func ValidateSpec(quayEcosystem *redhatcopv1alpha1.QuayEcosystem) error {
+
+	// Validate Databases
+	if err := validateDatabaseSpec("Quay", quayEcosystem.Spec.Quay.Database); err != nil {
+		return err
+	}
+
+	if err := validateDatabaseSpec("Clair", quayEcosystem.Spec.Clair.Database); err != nil {
+		return err
+	}
+
+	// Quay PVC Generation
+	if !utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.RegistryStorage) {
+
+		if _, err := resource.ParseQuantity(quayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeSize); err != nil {
+			return fmt.Errorf("Failed to parse Registry Storage Persistent Volume Size %s: %s", quayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeSize, err.Error())
+		}
+
+		if err := validateRetentionPolicy("Registry Storage", quayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeRetentionPolicy); err != nil {
+			return err
+		}
+	}
+
+	// Validate Certificate Issuer
+	if err := validateCertificateIssuerSpec(quayEcosystem.Spec.Quay); err != nil {
+		return err
+	}
+
+	// Validate Service Exposure
+	if err := validateServiceExposureSpec("Quay", quayEcosystem.Spec.Quay.ServiceExposure, quayEcosystem.Spec.Quay.EnableNodePortService); err != nil {
+		return err
+	}
+
+	if err := validateServiceExposureSpec("Quay Config", quayEcosystem.Spec.Quay.ConfigServiceExposure, quayEcosystem.Spec.Quay.EnableNodePortService); err != nil {
+		return err
+	}
+
+	// Validate External Access. The type is discovered from the cluster unless specified
+	if quayEcosystem.Spec.Quay.ExternalAccess != nil {
+		if err := validateExternalAccessSpec(quayEcosystem, quayEcosystem.Spec.Quay.ExternalAccess.Type); err != nil {
+			return err
+		}
+	}
+
+	// Validate Registry Backends
+	registryBackendNames := map[string]bool{}
+
+	for _, registryBackend := range quayEcosystem.Spec.Quay.RegistryBackends {
+
+		if utils.IsZeroOfUnderlyingType(registryBackend.Name) {
+			return fmt.Errorf("Failed to locate a Name for Registry Backend")
+		}
+
+		if registryBackendNames[registryBackend.Name] {
+			return fmt.Errorf("Duplicate Registry Backend %s", registryBackend.Name)
+		}
+
+		registryBackendNames[registryBackend.Name] = true
+
+		if err := validateRegistryBackendSpec(registryBackend); err != nil {
+			return err
+		}
+	}
+
+	// Validate Autoscaling
+	if err := validateAutoscalingSpec("Quay", quayEcosystem.Spec.Quay.Autoscaling); err != nil {
+		return err
+	}
+
+	if err := validateAutoscalingSpec("Clair", quayEcosystem.Spec.Clair.Autoscaling); err != nil {
+		return err
+	}
+
+	// Validate Clair Configuration
+	if err := validateClairConfigSpec(quayEcosystem.Spec.Clair); err != nil {
+		return err
+	}
+
+	switch quayEcosystem.Spec.Clair.Version {
+	case "", redhatcopv1alpha1.V2ClairVersion, redhatcopv1alpha1.V4ClairVersion:
+	default:
+		return fmt.Errorf("Invalid Clair Version %s. Must be one of %s or %s", quayEcosystem.Spec.Clair.Version, redhatcopv1alpha1.V2ClairVersion, redhatcopv1alpha1.V4ClairVersion)
+	}
+
+	switch quayEcosystem.Spec.Clair.DeploymentMode {
+	case "":
+	case redhatcopv1alpha1.CombinedClairDeploymentMode, redhatcopv1alpha1.SplitClairDeploymentMode:
+		if quayEcosystem.Spec.Clair.Version != redhatcopv1alpha1.V4ClairVersion {
+			return fmt.Errorf("Clair Deployment Mode is only supported with Clair %s", redhatcopv1alpha1.V4ClairVersion)
+		}
+	default:
+		return fmt.Errorf("Invalid Clair Deployment Mode %s. Must be one of %s or %s", quayEcosystem.Spec.Clair.DeploymentMode, redhatcopv1alpha1.CombinedClairDeploymentMode, redhatcopv1alpha1.SplitClairDeploymentMode)
+	}
+
+	// Validate High Availability
+	switch quayEcosystem.Spec.HighAvailability.Mode {
+	case "", redhatcopv1alpha1.RequiredAntiAffinityHighAvailabilityMode, redhatcopv1alpha1.PreferredAntiAffinityHighAvailabilityMode:
+	default:
+		return fmt.Errorf("Invalid High Availability Mode %s. Must be one of %s or %s", quayEcosystem.Spec.HighAvailability.Mode, redhatcopv1alpha1.RequiredAntiAffinityHighAvailabilityMode, redhatcopv1alpha1.PreferredAntiAffinityHighAvailabilityMode)
+	}
+
+	if resources.IsStorageReplicationEnabled(quayEcosystem) {
+
+		if len(quayEcosystem.Spec.Quay.RegistryBackends) < 2 {
+			return fmt.Errorf("Storage replication requires at least two Registry Backends")
+		}
+
+		for _, registryBackend := range quayEcosystem.Spec.Quay.RegistryBackends {
+			if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Local) {
+				return fmt.Errorf("Storage replication is not supported with Local Registry Backend %s", registryBackend.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateUpdate verifies that fields which cannot change once Quay has been set up are left untouched
+func ValidateUpdate(oldQuayEcosystem *redhatcopv1alpha1.QuayEcosystem, newQuayEcosystem *redhatcopv1alpha1.QuayEcosystem) error {
+
+	if !oldQuayEcosystem.Status.SetupComplete {
+		return nil
+	}
+
+	if oldQuayEcosystem.Spec.Quay.Database.Server != newQuayEcosystem.Spec.Quay.Database.Server {
+		return fmt.Errorf("Quay Database Server cannot be changed after setup has completed")
+	}
+
+	// Quay is configured for a single Clair version during setup
+	if utils.CheckValue(oldQuayEcosystem.Spec.Clair.Version, redhatcopv1alpha1.V2ClairVersion) != utils.CheckValue(newQuayEcosystem.Spec.Clair.Version, redhatcopv1alpha1.V2ClairVersion) {
+		return fmt.Errorf("Clair Version cannot be changed after setup has completed")
+	}
+
+	oldRegistryBackendNames := map[string]bool{}
+
+	for _, registryBackend := range oldQuayEcosystem.Spec.Quay.RegistryBackends {
+		oldRegistryBackendNames[registryBackend.Name] = true
+	}
+
+	if len(oldQuayEcosystem.Spec.Quay.RegistryBackends) != len(newQuayEcosystem.Spec.Quay.RegistryBackends) {
+		return fmt.Errorf("Registry Backends cannot be added or removed after setup has completed")
+	}
+
+	for _, registryBackend := range newQuayEcosystem.Spec.Quay.RegistryBackends {
+		if !oldRegistryBackendNames[registryBackend.Name] {
+			return fmt.Errorf("Registry Backend %s cannot be renamed or added after setup has completed", registryBackend.Name)
+		}
+	}
+
+	return nil
+}
+
+func validateDatabaseSpec(component string, database redhatcopv1alpha1.Database) error {
+
+	quantities := []struct {
+		name  string
+		value string
+	}{
+		{name: "CPU", value: database.CPU},
+		{name: "Memory", value: database.Memory},
+		{name: "Volume Size", value: database.VolumeSize},
+	}
+
+	for _, quantity := range quantities {
+
+		if utils.IsZeroOfUnderlyingType(quantity.value) {
+			continue
+		}
+
+		if _, err := resource.ParseQuantity(quantity.value); err != nil {
+			return fmt.Errorf("Failed to parse %s Database %s %s: %s", component, quantity.name, quantity.value, err.Error())
+		}
+	}
+
+	return validateRetentionPolicy(fmt.Sprintf("%s Database", component), database.RetentionPolicy)
+}
+
+func validateRetentionPolicy(name string, retentionPolicy redhatcopv1alpha1.PersistentVolumeClaimRetentionPolicy) error {
+
+	switch retentionPolicy {
+	case "", redhatcopv1alpha1.RetainPersistentVolumeClaimRetentionPolicy, redhatcopv1alpha1.DeletePersistentVolumeClaimRetentionPolicy, redhatcopv1alpha1.SnapshotPersistentVolumeClaimRetentionPolicy:
+		return nil
+	}
+
+	return fmt.Errorf("Invalid %s Retention Policy %s. Must be one of %s, %s or %s", name, retentionPolicy, redhatcopv1alpha1.RetainPersistentVolumeClaimRetentionPolicy, redhatcopv1alpha1.DeletePersistentVolumeClaimRetentionPolicy, redhatcopv1alpha1.SnapshotPersistentVolumeClaimRetentionPolicy)
+}
+
+func validateAutoscalingSpec(component string, autoscaling *redhatcopv1alpha1.Autoscaling) error {
+
+	if autoscaling == nil {
+		return nil
+	}
+
+	if autoscaling.MaxReplicas < 1 {
+		return fmt.Errorf("%s Autoscaling Max Replicas must be at least 1", component)
+	}
+
+	if autoscaling.MinReplicas != nil && (*autoscaling.MinReplicas < 1 || *autoscaling.MinReplicas > autoscaling.MaxReplicas) {
+		return fmt.Errorf("%s Autoscaling Min Replicas must be between 1 and %d", component, autoscaling.MaxReplicas)
+	}
+
+	targets := []struct {
+		name  string
+		value *int32
+	}{
+		{name: "CPU", value: autoscaling.TargetCPUUtilizationPercentage},
+		{name: "Memory", value: autoscaling.TargetMemoryUtilizationPercentage},
+	}
+
+	for _, target := range targets {
+		if target.value != nil && *target.value < 1 {
+			return fmt.Errorf("%s Autoscaling Target %s Utilization Percentage must be greater than 0", component, target.name)
+		}
+	}
+
+	return nil
+}
+
+func validateCertificateIssuerSpec(quay redhatcopv1alpha1.Quay) error {
+
+	if quay.CertificateIssuer == nil {
+		return nil
+	}
+
+	if utils.IsZeroOfUnderlyingType(quay.CertificateIssuer.Name) {
+		return fmt.Errorf("Failed to locate a Name for Certificate Issuer")
+	}
+
+	switch quay.CertificateIssuer.Kind {
+	case "", redhatcopv1alpha1.IssuerCertificateIssuerKind, redhatcopv1alpha1.ClusterIssuerCertificateIssuerKind:
+	default:
+		return fmt.Errorf("Invalid Certificate Issuer Kind %s. Must be one of %s or %s", quay.CertificateIssuer.Kind, redhatcopv1alpha1.IssuerCertificateIssuerKind, redhatcopv1alpha1.ClusterIssuerCertificateIssuerKind)
+	}
+
+	if !utils.IsZeroOfUnderlyingType(quay.SslCertificatesSecretName) {
+		return fmt.Errorf("Certificate Issuer cannot be combined with SSL Certificates Secret %s", quay.SslCertificatesSecretName)
+	}
+
+	if quay.CertificateIssuer.IncludeConfigHostname && utils.IsZeroOfUnderlyingType(quay.ConfigRouteHost) {
+		return fmt.Errorf("Including the config hostname in the issued certificate requires a Config Route Host")
+	}
+
+	return nil
+}
+
+func validateExternalAccessSpec(quayEcosystem *redhatcopv1alpha1.QuayEcosystem, externalAccessType redhatcopv1alpha1.ExternalAccessType) error {
+
+	switch externalAccessType {
+	case "", redhatcopv1alpha1.RouteExternalAccessType:
+	case redhatcopv1alpha1.IngressExternalAccessType:
+
+		// Quay is exposed through its load balancer rather than an Ingress, which requires a host
+		if utils.IsZeroOfUnderlyingType(quayEcosystem.Spec.Quay.RouteHost) && !resources.IsLoadBalancerServiceExposure(quayEcosystem.Spec.Quay.ServiceExposure) {
+			return fmt.Errorf("Exposing Quay through an Ingress requires a Route Host")
+		}
+
+		// The Clair components of a split deployment are only reachable through the path based routing of the Clair route
+		if resources.IsClairEnabled(quayEcosystem) && len(resources.GetClairComponents(quayEcosystem)) > 1 {
+			return fmt.Errorf("Clair %s Deployment Mode is not supported when exposing Quay through an Ingress", redhatcopv1alpha1.SplitClairDeploymentMode)
+		}
+	default:
+		return fmt.Errorf("Invalid External Access Type %s. Must be one of %s or %s", externalAccessType, redhatcopv1alpha1.RouteExternalAccessType, redhatcopv1alpha1.IngressExternalAccessType)
+	}
+
+	return nil
+}
+
+func validateServiceExposureSpec(component string, serviceExposure *redhatcopv1alpha1.ServiceExposure, enableNodePortService bool) error {
+
+	if serviceExposure == nil {
+		return nil
+	}
+
+	serviceType := serviceExposure.Type
+
+	switch serviceType {
+	case "":
+		serviceType = corev1.ServiceTypeClusterIP
+
+		if enableNodePortService {
+			serviceType = corev1.ServiceTypeNodePort
+		}
+	case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		return fmt.Errorf("Invalid %s Service Type %s. Must be one of %s, %s or %s", component, serviceExposure.Type, corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+	}
+
+	if serviceExposure.NodePort != 0 {
+
+		if serviceType == corev1.ServiceTypeClusterIP {
+			return fmt.Errorf("%s Node Port requires Service Type %s or %s", component, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+		}
+
+		if serviceExposure.NodePort < 1 || serviceExposure.NodePort > 65535 {
+			return fmt.Errorf("Invalid %s Node Port %d", component, serviceExposure.NodePort)
+		}
+	}
+
+	switch serviceExposure.ExternalTrafficPolicy {
+	case "":
+	case corev1.ServiceExternalTrafficPolicyTypeCluster, corev1.ServiceExternalTrafficPolicyTypeLocal:
+		if serviceType == corev1.ServiceTypeClusterIP {
+			return fmt.Errorf("%s External Traffic Policy requires Service Type %s or %s", component, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+		}
+	default:
+		return fmt.Errorf("Invalid %s External Traffic Policy %s. Must be one of %s or %s", component, serviceExposure.ExternalTrafficPolicy, corev1.ServiceExternalTrafficPolicyTypeCluster, corev1.ServiceExternalTrafficPolicyTypeLocal)
+	}
+
+	if len(serviceExposure.LoadBalancerSourceRanges) > 0 && serviceType != corev1.ServiceTypeLoadBalancer {
+		return fmt.Errorf("%s Load Balancer Source Ranges require Service Type %s", component, corev1.ServiceTypeLoadBalancer)
+	}
+
+	for _, sourceRange := range serviceExposure.LoadBalancerSourceRanges {
+		if _, _, err := net.ParseCIDR(sourceRange); err != nil {
+			return fmt.Errorf("Invalid %s Load Balancer Source Range %s: %s", component, sourceRange, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func validateClairConfigSpec(clair redhatcopv1alpha1.Clair) error {
+
+	durations := []struct {
+		name  string
+		value string
+	}{
+		{name: "API Timeout", value: clair.APITimeout},
+		{name: "Notifier Renotify Interval", value: clair.NotifierRenotifyInterval},
+		{name: "Update Interval", value: clair.UpdateInterval},
+	}
+
+	for _, duration := range durations {
+		if utils.IsZeroOfUnderlyingType(duration.value) {
+			continue
+		}
+
+		if _, err := time.ParseDuration(duration.value); err != nil {
+			return fmt.Errorf("Failed to parse Clair %s %s: %s", duration.name, duration.value, err.Error())
+		}
+	}
+
+	if !utils.IsZeroOfUnderlyingType(clair.SecurityScannerKeyExpiration) {
+
+		keyExpiration, err := time.ParseDuration(clair.SecurityScannerKeyExpiration)
+
+		if err != nil {
+			return fmt.Errorf("Failed to parse Clair Security Scanner Key Expiration %s: %s", clair.SecurityScannerKeyExpiration, err.Error())
+		}
+
+		if keyExpiration <= 0 {
+			return fmt.Errorf("Clair Security Scanner Key Expiration must be greater than 0")
+		}
+
+		if clair.Version == redhatcopv1alpha1.V4ClairVersion {
+			return fmt.Errorf("Clair Security Scanner Key Expiration is not supported with Clair %s", redhatcopv1alpha1.V4ClairVersion)
+		}
+	}
+
+	if clair.NotifierAttempts != nil && *clair.NotifierAttempts < 1 {
+		return fmt.Errorf("Clair Notifier Attempts must be greater than 0")
+	}
+
+	if clair.DatabaseCacheSize != nil && *clair.DatabaseCacheSize < 1 {
+		return fmt.Errorf("Clair Database Cache Size must be greater than 0")
+	}
+
+	return nil
+}
+
+func validateRegistryBackendSpec(registryBackend redhatcopv1alpha1.RegistryBackend) error {
+
+	registryBackendSources := 0
+
+	for _, registryBackendSource := range []interface{}{
+		registryBackend.RegistryBackendSource.Local,
+		registryBackend.RegistryBackendSource.S3,
+		registryBackend.RegistryBackendSource.Azure,
+		registryBackend.RegistryBackendSource.GoogleCloud,
+		registryBackend.RegistryBackendSource.Swift,
+		registryBackend.RegistryBackendSource.ObjectBucketClaim,
+	} {
+		if !utils.IsZeroOfUnderlyingType(registryBackendSource) {
+			registryBackendSources++
+		}
+	}
+
+	if registryBackendSources != 1 {
+		return fmt.Errorf("Registry Backend %s must define exactly one storage source", registryBackend.Name)
+	}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.S3) {
+
+		if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.S3.Bucket) {
+			return fmt.Errorf("Failed to locate a Bucket for S3 Registry Backend %s", registryBackend.Name)
+		}
+
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.S3.Endpoint) {
+			endpoint, err := url.Parse(registryBackend.RegistryBackendSource.S3.Endpoint)
+
+			if err != nil {
+				return fmt.Errorf("Failed to parse Endpoint for S3 Registry Backend %s: %s", registryBackend.Name, err.Error())
+			}
+
+			if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || utils.IsZeroOfUnderlyingType(endpoint.Hostname()) {
+				return fmt.Errorf("Endpoint for S3 Registry Backend %s must be a http or https URL", registryBackend.Name)
+			}
+		}
+	}
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.ObjectBucketClaim) {
+		if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.ObjectBucketClaim.Name) && utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.ObjectBucketClaim.StorageClassName) {
+			return fmt.Errorf("Failed to locate an ObjectBucketClaim name or Storage Class for Registry Backend %s", registryBackend.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package validation
+
+import (
+	"testing"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateSpec(t *testing.T) {
+
+	tenReplicas := int32(10)
+
+	cases := []struct {
+		quayEcosystem *redhatcopv1alpha1.QuayEcosystem
+		expected      bool
+	}{
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{},
+			expected:      true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						Database: redhatcopv1alpha1.Database{
+							Memory: "lots",
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						Database: redhatcopv1alpha1.Database{
+							RetentionPolicy: redhatcopv1alpha1.SnapshotPersistentVolumeClaimRetentionPolicy,
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						Database: redhatcopv1alpha1.Database{
+							RetentionPolicy: "Archive",
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						RegistryBackends: []redhatcopv1alpha1.RegistryBackend{
+							{
+								Name: "local",
+								RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+									Local: &redhatcopv1alpha1.LocalRegistryBackendSource{},
+								},
+							},
+							{
+								Name: "local",
+								RegistryBackendSource: redhatcopv1alpha1.RegistryBackendSource{
+									Local: &redhatcopv1alpha1.LocalRegistryBackendSource{},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					HighAvailability: redhatcopv1alpha1.HighAvailability{
+						Enabled: true,
+						Mode:    redhatcopv1alpha1.RequiredAntiAffinityHighAvailabilityMode,
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					HighAvailability: redhatcopv1alpha1.HighAvailability{
+						Enabled: true,
+						Mode:    "TopologySpread",
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						Autoscaling: &redhatcopv1alpha1.Autoscaling{
+							MaxReplicas: 5,
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						Autoscaling: &redhatcopv1alpha1.Autoscaling{
+							MaxReplicas: 2,
+							MinReplicas: &tenReplicas,
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						APITimeout:       "2m",
+						NotifierAttempts: &tenReplicas,
+						UpdateInterval:   "12h",
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						UpdateInterval: "daily",
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						DeploymentMode: redhatcopv1alpha1.SplitClairDeploymentMode,
+						Version:        redhatcopv1alpha1.V4ClairVersion,
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						DeploymentMode: redhatcopv1alpha1.SplitClairDeploymentMode,
+						Version:        redhatcopv1alpha1.V2ClairVersion,
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						SecurityScannerKeyExpiration: "720h",
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Clair: redhatcopv1alpha1.Clair{
+						SecurityScannerKeyExpiration: "-1h",
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						CertificateIssuer: &redhatcopv1alpha1.CertificateIssuer{
+							Kind: redhatcopv1alpha1.ClusterIssuerCertificateIssuerKind,
+							Name: "letsencrypt",
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						CertificateIssuer: &redhatcopv1alpha1.CertificateIssuer{
+							Name: "letsencrypt",
+						},
+						SslCertificatesSecretName: "quay-ssl",
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						ExternalAccess: &redhatcopv1alpha1.ExternalAccess{
+							IngressClass: "nginx",
+							Type:         redhatcopv1alpha1.IngressExternalAccessType,
+						},
+						RouteHost: "quay.example.com",
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						ExternalAccess: &redhatcopv1alpha1.ExternalAccess{
+							Type: redhatcopv1alpha1.IngressExternalAccessType,
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						ExternalAccess: &redhatcopv1alpha1.ExternalAccess{
+							Type: redhatcopv1alpha1.IngressExternalAccessType,
+						},
+						ServiceExposure: &redhatcopv1alpha1.ServiceExposure{
+							ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyTypeLocal,
+							LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+							Type:                     corev1.ServiceTypeLoadBalancer,
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						ServiceExposure: &redhatcopv1alpha1.ServiceExposure{
+							NodePort: 30443,
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			quayEcosystem: &redhatcopv1alpha1.QuayEcosystem{
+				Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+					Quay: redhatcopv1alpha1.Quay{
+						ConfigServiceExposure: &redhatcopv1alpha1.ServiceExposure{
+							LoadBalancerSourceRanges: []string{"10.0.0.0"},
+							Type:                     corev1.ServiceTypeLoadBalancer,
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+	}
+
+	for i, c := range cases {
+		result := ValidateSpec(c.quayEcosystem) == nil
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+
+	quayEcosystem := func(server string, setupComplete bool) *redhatcopv1alpha1.QuayEcosystem {
+		return &redhatcopv1alpha1.QuayEcosystem{
+			Spec: redhatcopv1alpha1.QuayEcosystemSpec{
+				Quay: redhatcopv1alpha1.Quay{
+					Database: redhatcopv1alpha1.Database{
+						Server: server,
+					},
+				},
+			},
+			Status: redhatcopv1alpha1.QuayEcosystemStatus{
+				SetupComplete: setupComplete,
+			},
+		}
+	}
+
+	cases := []struct {
+		oldQuayEcosystem *redhatcopv1alpha1.QuayEcosystem
+		newQuayEcosystem *redhatcopv1alpha1.QuayEcosystem
+		expected         bool
+	}{
+		{
+			oldQuayEcosystem: quayEcosystem("postgresql-a", false),
+			newQuayEcosystem: quayEcosystem("postgresql-b", false),
+			expected:         true,
+		},
+		{
+			oldQuayEcosystem: quayEcosystem("postgresql-a", true),
+			newQuayEcosystem: quayEcosystem("postgresql-b", true),
+			expected:         false,
+		},
+	}
+
+	for i, c := range cases {
+		result := ValidateUpdate(c.oldQuayEcosystem, c.newQuayEcosystem) == nil
+
+		if c.expected != result {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
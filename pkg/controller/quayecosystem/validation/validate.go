@@ -2,16 +2,17 @@ package validation
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
 	"reflect"
 
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/constants"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/resources"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -19,6 +20,22 @@ import (
 // Validate performs validation across all resources
 func Validate(client client.Client, quayConfiguration *resources.QuayConfiguration) (bool, error) {
 
+	// Validate Specification
+	if err := ValidateSpec(quayConfiguration.QuayEcosystem); err != nil {
+		return false, err
+	}
+
+	// Validate External Access against the APIs served by the cluster
+	externalAccessType := resources.GetExternalAccessType(quayConfiguration)
+
+	if externalAccessType == redhatcopv1alpha1.RouteExternalAccessType && !quayConfiguration.IsOpenShift {
+		return false, fmt.Errorf("Routes are not available outside of OpenShift. Use External Access Type %s instead", redhatcopv1alpha1.IngressExternalAccessType)
+	}
+
+	if err := validateExternalAccessSpec(quayConfiguration.QuayEcosystem, externalAccessType); err != nil {
+		return false, err
+	}
+
 	// Validate Superuser Credentials Secret
 	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.SuperuserCredentialsSecretName) {
 
@@ -133,25 +150,60 @@ func Validate(client client.Client, quayConfiguration *resources.QuayConfigurati
 		}
 	}
 
-	// Validate Quay Database
-	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.Database.VolumeSize) {
+	// Validate Clair ImagePullSecret
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.ImagePullSecretName) {
 
-		_, err := resource.ParseQuantity(quayConfiguration.QuayEcosystem.Spec.Quay.Database.VolumeSize)
+		validImagePullSecret, _, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, quayConfiguration.QuayEcosystem.Spec.Clair.ImagePullSecretName, nil)
 
 		if err != nil {
 			return false, err
 		}
+
+		if !validImagePullSecret {
+			return false, fmt.Errorf("Failed to validate provided Clair Image Pull Secret")
+		}
+	}
+
+	// Validate Clair Database ImagePullSecret
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.Database.ImagePullSecretName) {
+
+		validImagePullSecret, _, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, quayConfiguration.QuayEcosystem.Spec.Clair.Database.ImagePullSecretName, nil)
+
+		if err != nil {
+			return false, err
+		}
+
+		if !validImagePullSecret {
+			return false, fmt.Errorf("Failed to validate provided Clair Database Image Pull Secret")
+		}
+	}
+
+	// Validate Clair Database Credential
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.Database.Server) && utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.Database.CredentialsSecretName) {
+		return false, fmt.Errorf("Failed to locate a Clair Database Credential for Externally Provisioned Instance")
 	}
 
-	// Quay PVC Generation
-	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage) {
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.Database.CredentialsSecretName) {
 
-		_, err := resource.ParseQuantity(quayConfiguration.QuayEcosystem.Spec.Quay.RegistryStorage.PersistentVolumeSize)
+		validClairDatabaseSecret, databaseSecret, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, quayConfiguration.QuayEcosystem.Spec.Clair.Database.CredentialsSecretName, constants.RequiredDatabaseCredentialKeys)
 
 		if err != nil {
 			return false, err
 		}
 
+		if !validClairDatabaseSecret {
+			return false, fmt.Errorf("Failed to validate provided Clair Database Secret")
+		}
+
+		quayConfiguration.ClairDatabase.Username = string(databaseSecret.Data[constants.DatabaseCredentialsUsernameKey])
+		quayConfiguration.ClairDatabase.Password = string(databaseSecret.Data[constants.DatabaseCredentialsPasswordKey])
+		quayConfiguration.ClairDatabase.Database = string(databaseSecret.Data[constants.DatabaseCredentialsDatabaseKey])
+
+		if _, found := databaseSecret.Data[constants.DatabaseCredentialsRootPasswordKey]; found {
+			quayConfiguration.ClairDatabase.RootPassword = string(databaseSecret.Data[constants.DatabaseCredentialsRootPasswordKey])
+		}
+
+		quayConfiguration.ValidProvidedClairDatabaseSecret = true
 	}
 
 	// Validate Quay SSL Certificates
@@ -171,9 +223,225 @@ func Validate(client client.Client, quayConfiguration *resources.QuayConfigurati
 
 	}
 
+	// Validate Extra CA Certificates
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Quay.ExtraCACertsSecretName) {
+		quayExtraCACerts, err := getExtraCACerts(client, quayConfiguration.QuayEcosystem.Namespace, quayConfiguration.QuayEcosystem.Spec.Quay.ExtraCACertsSecretName)
+
+		if err != nil {
+			return false, err
+		}
+
+		quayConfiguration.QuayExtraCACerts = quayExtraCACerts
+	}
+
+	if !utils.IsZeroOfUnderlyingType(quayConfiguration.QuayEcosystem.Spec.Clair.ExtraCACertsSecretName) {
+		clairExtraCACerts, err := getExtraCACerts(client, quayConfiguration.QuayEcosystem.Namespace, quayConfiguration.QuayEcosystem.Spec.Clair.ExtraCACertsSecretName)
+
+		if err != nil {
+			return false, err
+		}
+
+		quayConfiguration.ClairExtraCACerts = clairExtraCACerts
+	}
+
+	// Validate Registry Backends
+	err := validateRegistryBackends(client, quayConfiguration)
+
+	if err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
+func validateRegistryBackends(client client.Client, quayConfiguration *resources.QuayConfiguration) error {
+
+	quayConfiguration.RegistryBackendCredentials = map[string]map[string]string{}
+
+	for _, registryBackend := range quayConfiguration.QuayEcosystem.Spec.Quay.RegistryBackends {
+
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.S3) {
+			if err := validateS3RegistryBackend(client, quayConfiguration, registryBackend); err != nil {
+				return err
+			}
+		}
+
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Azure) {
+			if err := validateAzureRegistryBackend(client, quayConfiguration, registryBackend); err != nil {
+				return err
+			}
+		}
+
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.GoogleCloud) {
+			if err := validateGoogleCloudRegistryBackend(client, quayConfiguration, registryBackend); err != nil {
+				return err
+			}
+		}
+
+		if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Swift) {
+			if err := validateSwiftRegistryBackend(client, quayConfiguration, registryBackend); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func validateS3RegistryBackend(client client.Client, quayConfiguration *resources.QuayConfiguration, registryBackend redhatcopv1alpha1.RegistryBackend) error {
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.S3.CredentialsSecretName) {
+
+		validS3Secret, s3Secret, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, registryBackend.RegistryBackendSource.S3.CredentialsSecretName, constants.RequiredS3CredentialKeys)
+
+		if err != nil {
+			return err
+		}
+
+		if !validS3Secret {
+			return fmt.Errorf("Failed to validate provided S3 Registry Backend Secret for %s", registryBackend.Name)
+		}
+
+		quayConfiguration.RegistryBackendCredentials[registryBackend.Name] = getSecretStringData(s3Secret)
+	}
+
+	return nil
+}
+
+func validateAzureRegistryBackend(client client.Client, quayConfiguration *resources.QuayConfiguration, registryBackend redhatcopv1alpha1.RegistryBackend) error {
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Azure.Container) {
+		return fmt.Errorf("Failed to locate a Container for Azure Registry Backend %s", registryBackend.Name)
+	}
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Azure.AccountName) {
+		return fmt.Errorf("Failed to locate an Account Name for Azure Registry Backend %s", registryBackend.Name)
+	}
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Azure.CredentialsSecretName) {
+		return fmt.Errorf("Failed to locate a Credentials Secret for Azure Registry Backend %s", registryBackend.Name)
+	}
+
+	validAzureSecret, azureSecret, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, registryBackend.RegistryBackendSource.Azure.CredentialsSecretName, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if !validAzureSecret {
+		return fmt.Errorf("Failed to validate provided Azure Registry Backend Secret for %s", registryBackend.Name)
+	}
+
+	// Either an account key or a SAS token is required
+	if !validateProvidedSecretSlice(azureSecret, []string{constants.RegistryBackendAzureAccountKeyKey}) && !validateProvidedSecretSlice(azureSecret, []string{constants.RegistryBackendAzureSasTokenKey}) {
+		return fmt.Errorf("Failed to locate %s or %s in Azure Registry Backend Secret for %s", constants.RegistryBackendAzureAccountKeyKey, constants.RegistryBackendAzureSasTokenKey, registryBackend.Name)
+	}
+
+	quayConfiguration.RegistryBackendCredentials[registryBackend.Name] = getSecretStringData(azureSecret)
+
+	return nil
+}
+
+func validateGoogleCloudRegistryBackend(client client.Client, quayConfiguration *resources.QuayConfiguration, registryBackend redhatcopv1alpha1.RegistryBackend) error {
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.GoogleCloud.Bucket) {
+		return fmt.Errorf("Failed to locate a Bucket for Google Cloud Registry Backend %s", registryBackend.Name)
+	}
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.GoogleCloud.CredentialsSecretName) {
+		return fmt.Errorf("Failed to locate a Credentials Secret for Google Cloud Registry Backend %s", registryBackend.Name)
+	}
+
+	validGoogleCloudSecret, googleCloudSecret, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, registryBackend.RegistryBackendSource.GoogleCloud.CredentialsSecretName, constants.RequiredGoogleCloudCredentialKeys)
+
+	if err != nil {
+		return err
+	}
+
+	if !validGoogleCloudSecret {
+		return fmt.Errorf("Failed to validate provided Google Cloud Registry Backend Secret for %s", registryBackend.Name)
+	}
+
+	quayConfiguration.RegistryBackendCredentials[registryBackend.Name] = getSecretStringData(googleCloudSecret)
+
+	return nil
+}
+
+func validateSwiftRegistryBackend(client client.Client, quayConfiguration *resources.QuayConfiguration, registryBackend redhatcopv1alpha1.RegistryBackend) error {
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Swift.Container) {
+		return fmt.Errorf("Failed to locate a Container for Swift Registry Backend %s", registryBackend.Name)
+	}
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Swift.AuthURL) {
+		return fmt.Errorf("Failed to locate an Auth URL for Swift Registry Backend %s", registryBackend.Name)
+	}
+
+	if utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Swift.CredentialsSecretName) {
+		return fmt.Errorf("Failed to locate a Credentials Secret for Swift Registry Backend %s", registryBackend.Name)
+	}
+
+	validSwiftSecret, swiftSecret, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, registryBackend.RegistryBackendSource.Swift.CredentialsSecretName, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if !validSwiftSecret {
+		return fmt.Errorf("Failed to validate provided Swift Registry Backend Secret for %s", registryBackend.Name)
+	}
+
+	// Either a user/password or an application credential is required
+	if !validateProvidedSecretSlice(swiftSecret, constants.RequiredSwiftPasswordCredentialKeys) && !validateProvidedSecretSlice(swiftSecret, constants.RequiredSwiftApplicationCredentialKeys) {
+		return fmt.Errorf("Failed to locate a user/password or application credential in Swift Registry Backend Secret for %s", registryBackend.Name)
+	}
+
+	credentials := getSecretStringData(swiftSecret)
+
+	if !utils.IsZeroOfUnderlyingType(registryBackend.RegistryBackendSource.Swift.CACertSecretName) {
+
+		validCACertSecret, caCertSecret, err := validateSecret(client, quayConfiguration.QuayEcosystem.Namespace, registryBackend.RegistryBackendSource.Swift.CACertSecretName, constants.RequiredCACertificateKeys)
+
+		if err != nil {
+			return err
+		}
+
+		if !validCACertSecret {
+			return fmt.Errorf("Failed to validate provided Swift CA Certificate Secret for %s", registryBackend.Name)
+		}
+
+		credentials[constants.RegistryBackendCACertificateKey] = string(caCertSecret.Data[constants.RegistryBackendCACertificateKey])
+	}
+
+	quayConfiguration.RegistryBackendCredentials[registryBackend.Name] = credentials
+
+	return nil
+}
+
+// getExtraCACerts returns the PEM encoded certificates contained in a secret keyed by file name
+func getExtraCACerts(client client.Client, namespace string, name string) (map[string][]byte, error) {
+
+	_, extraCACertsSecret, err := validateSecret(client, namespace, name, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	extraCACerts := map[string][]byte{}
+
+	for fileName, certificate := range extraCACertsSecret.Data {
+
+		if block, _ := pem.Decode(certificate); block == nil {
+			return nil, fmt.Errorf("Failed to locate a PEM encoded certificate in key %s of Extra CA Certificates Secret %s", fileName, name)
+		}
+
+		extraCACerts[fileName] = certificate
+	}
+
+	return extraCACerts, nil
+}
+
 func validateSecret(client client.Client, namespace string, name string, requiredParameters interface{}) (bool, *corev1.Secret, error) {
 
 	secret := &corev1.Secret{}
@@ -208,6 +476,18 @@ func validateSecret(client client.Client, namespace string, name string, require
 
 }
 
+func getSecretStringData(secret *corev1.Secret) map[string]string {
+
+	secretData := map[string]string{}
+
+	for key, value := range secret.Data {
+		secretData[key] = string(value)
+	}
+
+	return secretData
+
+}
+
 func validateProvidedSecretMap(secret *corev1.Secret, requiredParameters map[string]string) bool {
 
 	for key := range requiredParameters {
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/watch"
@@ -127,3 +129,18 @@ func RunExec(k8sclient kubernetes.Interface, command []string, podName, namespac
 
 	return stdout.String(), stderr.String(), nil
 }
+
+// IsOpenShift returns whether the cluster serves the OpenShift Route API
+func IsOpenShift(k8sclient kubernetes.Interface) (bool, error) {
+
+	_, err := k8sclient.Discovery().ServerResourcesForGroupVersion(routev1.SchemeGroupVersion.String())
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to discover the %s API: %v", routev1.SchemeGroupVersion.String(), err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"time"
+
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
+	"github.com/theodor2311/quay-operator/pkg/webhook/quayecosystem"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	// CustomResourceDefinitionName is the name of the QuayEcosystem CustomResourceDefinition
+	CustomResourceDefinitionName = "quayecosystems.redhatcop.redhat.io"
+	// caCertName is the name of the CA certificate written to ServerCertDir by the admission server
+	caCertName = "ca-cert.pem"
+)
+
+// addConversionWebhook serves the QuayEcosystem conversion webhook and points the CustomResourceDefinition at it
+func addConversionWebhook(m manager.Manager, server *webhook.Server, operatorNamespace string) error {
+
+	c, err := newCustomResourceDefinitionClient(m.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	server.Handle(quayecosystem.ConversionPath, &quayecosystem.ConversionHandler{})
+
+	return m.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+
+		var caBundle []byte
+
+		// The admission server writes its certificates once it starts
+		err := wait.PollImmediateUntil(time.Second*5, func() (bool, error) {
+			var readErr error
+			caBundle, readErr = ioutil.ReadFile(path.Join(ServerCertDir, caCertName))
+			return readErr == nil, nil
+		}, stop)
+		if err != nil {
+			// Stopped before the certificates were written
+			return nil
+		}
+
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: CustomResourceDefinitionName}, crd); err != nil {
+			logging.Log.Error(err, "Failed to get CustomResourceDefinition", "Name", CustomResourceDefinitionName)
+			return err
+		}
+
+		conversionPath := quayecosystem.ConversionPath
+
+		crd.Spec.Conversion = &apiextensionsv1beta1.CustomResourceConversion{
+			Strategy: apiextensionsv1beta1.WebhookConverter,
+			WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+				Service: &apiextensionsv1beta1.ServiceReference{
+					Name:      ServiceName,
+					Namespace: operatorNamespace,
+					Path:      &conversionPath,
+				},
+				CABundle: caBundle,
+			},
+		}
+
+		if err := c.Update(context.TODO(), crd); err != nil {
+			logging.Log.Error(err, "Failed to configure conversion webhook", "Name", CustomResourceDefinitionName)
+			return err
+		}
+
+		logging.Log.Info("Configured conversion webhook", "Name", CustomResourceDefinitionName)
+
+		return nil
+	}))
+}
+
+// ConversionWebhookRequired returns whether the QuayEcosystem CustomResourceDefinition converts between API versions
+// through the operator, which then has to serve its webhooks
+func ConversionWebhookRequired(cfg *rest.Config) (bool, error) {
+
+	c, err := newCustomResourceDefinitionClient(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: CustomResourceDefinitionName}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy == apiextensionsv1beta1.WebhookConverter, nil
+}
+
+func newCustomResourceDefinitionClient(cfg *rest.Config) (client.Client, error) {
+
+	scheme := runtime.NewScheme()
+
+	if err := apiextensionsv1beta1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
@@ -0,0 +1,41 @@
+package quayecosystem
+
+import (
+	"encoding/json"
+	"fmt"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	redhatcopv1beta1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+)
+
+// quayEcosystemRules returns the admission rules matching QuayEcosystem resources in every served API version
+func quayEcosystemRules() []admissionregistrationv1beta1.RuleWithOperations {
+	return []admissionregistrationv1beta1.RuleWithOperations{
+		{
+			Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update},
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   []string{redhatcopv1alpha1.SchemeGroupVersion.Group},
+				APIVersions: []string{redhatcopv1alpha1.SchemeGroupVersion.Version, redhatcopv1beta1.SchemeGroupVersion.Version},
+				Resources:   []string{"quayecosystems"},
+			},
+		},
+	}
+}
+
+// decodeQuayEcosystem decodes an admitted QuayEcosystem of any served API version into the hub version
+func decodeQuayEcosystem(raw []byte) (*redhatcopv1alpha1.QuayEcosystem, error) {
+
+	converted, err := convertObject(raw, redhatcopv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		return nil, err
+	}
+
+	quayEcosystem := &redhatcopv1alpha1.QuayEcosystem{}
+
+	if err := json.Unmarshal(converted, quayEcosystem); err != nil {
+		return nil, fmt.Errorf("Failed to decode object: %s", err.Error())
+	}
+
+	return quayEcosystem, nil
+}
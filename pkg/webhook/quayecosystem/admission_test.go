@@ -0,0 +1,66 @@
+package quayecosystem
+
+import (
+	"context"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+func TestAdmissionServedVersions(t *testing.T) {
+
+	cases := []struct {
+		version              string
+		raw                  string
+		registryBackendsPath string
+	}{
+		{
+			version:              "v1alpha1",
+			raw:                  `{"apiVersion":"redhatcop.redhat.io/v1alpha1","kind":"QuayEcosystem","metadata":{"name":"quay","namespace":"quay-enterprise"},"spec":{}}`,
+			registryBackendsPath: "/spec/quay/registryBackends",
+		},
+		{
+			version:              "v1beta1",
+			raw:                  `{"apiVersion":"redhatcop.redhat.io/v1beta1","kind":"QuayEcosystem","metadata":{"name":"quay","namespace":"quay-enterprise"},"spec":{}}`,
+			registryBackendsPath: "/spec/quay/storage/backends",
+		},
+	}
+
+	for i, c := range cases {
+		req := types.Request{
+			AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: "redhatcop.redhat.io", Version: c.version, Kind: "QuayEcosystem"},
+				Operation: admissionv1beta1.Create,
+				Object:    runtime.RawExtension{Raw: []byte(c.raw)},
+			},
+		}
+
+		defaulted := (&quayEcosystemDefaulter{}).Handle(context.TODO(), req)
+
+		if !defaulted.Response.Allowed || len(defaulted.Patches) == 0 {
+			t.Errorf("Test case %d was not defaulted: %#v", i, defaulted)
+		}
+
+		// Defaults must be patched using the layout of the requested version
+		found := false
+
+		for _, patch := range defaulted.Patches {
+			if patch.Path == c.registryBackendsPath {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("Test case %d did not default %s: %#v", i, c.registryBackendsPath, defaulted.Patches)
+		}
+
+		validated := (&quayEcosystemValidator{}).Handle(context.TODO(), req)
+
+		if !validated.Response.Allowed {
+			t.Errorf("Test case %d was not admitted: %#v", i, validated.Response.Result)
+		}
+	}
+}
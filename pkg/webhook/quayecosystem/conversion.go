@@ -0,0 +1,125 @@
+package quayecosystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	redhatcopv1alpha1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1alpha1"
+	redhatcopv1beta1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1beta1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/logging"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ConversionPath is the path the QuayEcosystem conversion webhook is served on
+const ConversionPath = "/convert"
+
+// ConversionHandler converts QuayEcosystem resources between the served API versions
+type ConversionHandler struct{}
+
+// ServeHTTP handles a ConversionReview sent by the API server
+func (h *ConversionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	conversionReview := &apiextensionsv1beta1.ConversionReview{}
+
+	if err := json.NewDecoder(r.Body).Decode(conversionReview); err != nil {
+		logging.Log.Error(err, "Failed to decode ConversionReview")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if conversionReview.Request == nil {
+		http.Error(w, "ConversionReview does not contain a request", http.StatusBadRequest)
+		return
+	}
+
+	conversionReview.Response = Convert(conversionReview.Request)
+	conversionReview.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(conversionReview); err != nil {
+		logging.Log.Error(err, "Failed to encode ConversionReview")
+	}
+}
+
+// Convert converts each object in the request to the desired API version
+func Convert(request *apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+
+	response := &apiextensionsv1beta1.ConversionResponse{
+		UID: request.UID,
+	}
+
+	for _, object := range request.Objects {
+
+		converted, err := convertObject(object.Raw, request.DesiredAPIVersion)
+
+		if err != nil {
+			logging.Log.Error(err, "Failed to convert QuayEcosystem", "DesiredAPIVersion", request.DesiredAPIVersion)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			return response
+		}
+
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	response.Result = metav1.Status{
+		Status: metav1.StatusSuccess,
+	}
+
+	return response
+}
+
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+
+	typeMeta := metav1.TypeMeta{}
+
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("Failed to decode object: %s", err.Error())
+	}
+
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	// Convert to the hub version
+	hub := &redhatcopv1alpha1.QuayEcosystem{}
+
+	switch typeMeta.APIVersion {
+	case redhatcopv1alpha1.SchemeGroupVersion.String():
+		if err := json.Unmarshal(raw, hub); err != nil {
+			return nil, fmt.Errorf("Failed to decode object: %s", err.Error())
+		}
+	case redhatcopv1beta1.SchemeGroupVersion.String():
+		spoke := &redhatcopv1beta1.QuayEcosystem{}
+		if err := json.Unmarshal(raw, spoke); err != nil {
+			return nil, fmt.Errorf("Failed to decode object: %s", err.Error())
+		}
+		if err := spoke.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported API version %s", typeMeta.APIVersion)
+	}
+
+	// Convert from the hub version
+	switch desiredAPIVersion {
+	case redhatcopv1alpha1.SchemeGroupVersion.String():
+		hub.APIVersion = desiredAPIVersion
+		return json.Marshal(hub)
+	case redhatcopv1beta1.SchemeGroupVersion.String():
+		spoke := &redhatcopv1beta1.QuayEcosystem{}
+		if err := spoke.ConvertFrom(hub); err != nil {
+			return nil, err
+		}
+		return json.Marshal(spoke)
+	default:
+		return nil, fmt.Errorf("Unsupported API version %s", desiredAPIVersion)
+	}
+}
@@ -0,0 +1,61 @@
+package quayecosystem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	redhatcopv1beta1 "github.com/theodor2311/quay-operator/pkg/apis/redhatcop/v1beta1"
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/validation"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// NewMutatingWebhook builds the webhook applying defaults to QuayEcosystem resources on admission
+func NewMutatingWebhook(mgr manager.Manager) (*admission.Webhook, error) {
+	return builder.NewWebhookBuilder().
+		Name("mutating.quayecosystems.redhatcop.redhat.io").
+		Path("/mutate-quayecosystems").
+		Mutating().
+		Rules(quayEcosystemRules()...).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		WithManager(mgr).
+		Handlers(&quayEcosystemDefaulter{}).
+		Build()
+}
+
+// quayEcosystemDefaulter applies the same defaults as the reconcile loop so they are persisted at admission time
+type quayEcosystemDefaulter struct{}
+
+// Handle applies the spec defaults and returns the resulting patch in the API version of the request
+func (d *quayEcosystemDefaulter) Handle(ctx context.Context, req types.Request) types.Response {
+
+	quayEcosystem, err := decodeQuayEcosystem(req.AdmissionRequest.Object.Raw)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	defaultedQuayEcosystem := quayEcosystem.DeepCopy()
+	validation.SetSpecDefaults(defaultedQuayEcosystem)
+
+	if req.AdmissionRequest.Kind.Version != redhatcopv1beta1.SchemeGroupVersion.Version {
+		return admission.PatchResponse(quayEcosystem, defaultedQuayEcosystem)
+	}
+
+	original := &redhatcopv1beta1.QuayEcosystem{}
+
+	if err := json.Unmarshal(req.AdmissionRequest.Object.Raw, original); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	defaulted := &redhatcopv1beta1.QuayEcosystem{}
+
+	if err := defaulted.ConvertFrom(defaultedQuayEcosystem); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponse(original, defaulted)
+}
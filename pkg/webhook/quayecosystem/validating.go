@@ -0,0 +1,57 @@
+package quayecosystem
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/theodor2311/quay-operator/pkg/controller/quayecosystem/validation"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// NewValidatingWebhook builds the webhook validating QuayEcosystem resources on admission
+func NewValidatingWebhook(mgr manager.Manager) (*admission.Webhook, error) {
+	return builder.NewWebhookBuilder().
+		Name("validating.quayecosystems.redhatcop.redhat.io").
+		Path("/validate-quayecosystems").
+		Validating().
+		Rules(quayEcosystemRules()...).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		WithManager(mgr).
+		Handlers(&quayEcosystemValidator{}).
+		Build()
+}
+
+// quayEcosystemValidator rejects QuayEcosystem resources that would fail validation during reconciliation
+type quayEcosystemValidator struct{}
+
+// Handle validates the QuayEcosystem and, for updates, any changes to immutable fields
+func (v *quayEcosystemValidator) Handle(ctx context.Context, req types.Request) types.Response {
+
+	quayEcosystem, err := decodeQuayEcosystem(req.AdmissionRequest.Object.Raw)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	if err := validation.ValidateSpec(quayEcosystem); err != nil {
+		return admission.ValidationResponse(false, err.Error())
+	}
+
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+
+		oldQuayEcosystem, err := decodeQuayEcosystem(req.AdmissionRequest.OldObject.Raw)
+		if err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+
+		if err := validation.ValidateUpdate(oldQuayEcosystem, quayEcosystem); err != nil {
+			return admission.ValidationResponse(false, err.Error())
+		}
+	}
+
+	return admission.ValidationResponse(true, "")
+}
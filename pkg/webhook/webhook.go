@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// ServerName is the name of the admission webhook server
+	ServerName = "quay-operator-admission-server"
+	// ServerPort is the port the admission webhook server listens on
+	ServerPort = 9443
+	// ServerCertDir is the directory the admission webhook server certificates are written to
+	ServerCertDir = "/tmp/quay-operator-webhook-certs"
+	// ServiceName is the name of the Service fronting the admission webhook server
+	ServiceName = "quay-operator-webhook"
+	// ValidatingWebhookConfigName is the name of the ValidatingWebhookConfiguration
+	ValidatingWebhookConfigName = "quay-operator-validating-webhook"
+	// MutatingWebhookConfigName is the name of the MutatingWebhookConfiguration
+	MutatingWebhookConfigName = "quay-operator-mutating-webhook"
+	// ServingCertDir is the directory the serving certificate issued by the OpenShift service CA is mounted to
+	ServingCertDir = "/etc/quay-operator/webhook-serving-cert"
+	// ServiceCAFile is the OpenShift service CA bundle mounted alongside the service account token
+	ServiceCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+)
+
+// AddToManagerFuncs is a list of functions to build all Webhooks served by the Manager
+var AddToManagerFuncs []func(manager.Manager) (*admission.Webhook, error)
+
+// AddToManager adds an admission server serving all Webhooks, including the QuayEcosystem conversion webhook, to the Manager
+func AddToManager(m manager.Manager) error {
+
+	operatorNamespace, err := k8sutil.GetOperatorNamespace()
+	if err != nil {
+		return err
+	}
+
+	operatorName, err := k8sutil.GetOperatorName()
+	if err != nil {
+		return err
+	}
+
+	if err := useServingCertificate(); err != nil {
+		return err
+	}
+
+	server, err := webhook.NewServer(ServerName, m, webhook.ServerOptions{
+		Port:    ServerPort,
+		CertDir: ServerCertDir,
+		BootstrapOptions: &webhook.BootstrapOptions{
+			ValidatingWebhookConfigName: ValidatingWebhookConfigName,
+			MutatingWebhookConfigName:   MutatingWebhookConfigName,
+			Service: &webhook.Service{
+				Name:      ServiceName,
+				Namespace: operatorNamespace,
+				Selectors: map[string]string{
+					"name": operatorName,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var webhooks []webhook.Webhook
+
+	for _, f := range AddToManagerFuncs {
+		w, err := f(m)
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	if err := server.Register(webhooks...); err != nil {
+		return err
+	}
+
+	return addConversionWebhook(m, server, operatorNamespace)
+}
+
+// useServingCertificate seeds the admission server certificates with the serving certificate issued by the OpenShift
+// service CA when it is mounted, so the server presents a certificate matching the CA bundle the service CA injects into
+// the CustomResourceDefinition. Otherwise the admission server generates its own self-signed certificates
+func useServingCertificate() error {
+
+	files := map[string]string{
+		path.Join(ServingCertDir, "tls.crt"): "cert.pem",
+		path.Join(ServingCertDir, "tls.key"): "key.pem",
+		ServiceCAFile:                        caCertName,
+	}
+
+	for source := range files {
+		if _, err := os.Stat(source); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(ServerCertDir, 0700); err != nil {
+		return err
+	}
+
+	for source, target := range files {
+		content, err := ioutil.ReadFile(source)
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(path.Join(ServerCertDir, target), content, 0600); err != nil {
+			return err
+		}
+	}
+
+	// The admission server only uses the CA key to sign certificates it generates itself
+	return ioutil.WriteFile(path.Join(ServerCertDir, "ca-key.pem"), []byte{}, 0600)
+}